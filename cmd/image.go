@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"image"
 	"time"
 
 	"github.com/Krzysztofz01/pixel-sorter/pkg/sorter"
@@ -35,15 +34,12 @@ var imageCmd = &cobra.Command{
 			return err
 		}
 
-		var mask image.Image = nil
-		if len(FlagMaskImageFilePath) > 0 {
-			mask, err = utils.GetImageFromFile(FlagMaskImageFilePath)
-			if err != nil {
-				return err
-			}
+		mask, auxiliary, err := loadSorterInputs(img, rootSorterFlags, options)
+		if err != nil {
+			return err
 		}
 
-		sorter, err := sorter.CreateSorter(img, mask, SorterLogger, options)
+		sorter, err := sorter.CreateSorterWithAuxiliaryImages(img, mask, auxiliary, SorterLogger, options)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Krzysztofz01/pixel-sorter/pkg/img"
+	"github.com/Krzysztofz01/pixel-sorter/pkg/mask"
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	FlagMaskOperations       []string
+	FlagCombineMaskFilePaths []string
+	FlagCombineOperator      string
+	FlagMaskEdgeDetection    bool
+	FlagPattern              string
+	FlagPatternAngle         int
+	FlagPatternSize          float64
+	FlagPatternCount         int
+	FlagPatternThreshold     float64
+	FlagPatternSeed          int64
+	FlagPatternOctaves       int
+	FlagPatternPersistence   float64
+)
+
+var maskCmd = &cobra.Command{
+	Use:   "mask",
+	Short: "Perform the mask operations on the specified mask image file.",
+	Long:  "Perform the mask operations on the specified mask image file. The input mask is combined with the other mask images and the morphology operations are applied in the specified order, so the result can be used as the mask image of the sorting commands. Of the root command flags only the input-media-path, output-media-path, verbose and mask-channel flags are used. The mask-channel flag is used to read the input mask and the combined masks.",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := parseMediaOptions(); err != nil {
+			return err
+		}
+
+		LocalLogger.Info("Starting the mask processing.")
+		commandExecTime := time.Now()
+
+		format, ok := determineFileExtension(FlagOutputMediaFilePath, []string{"jpeg", "jpg", "png"})
+		if !ok {
+			return fmt.Errorf("cmd: invalid output image file format specified (%s)", FlagOutputMediaFilePath)
+		}
+
+		operator, err := parseMaskCombineOperator(FlagCombineOperator)
+		if err != nil {
+			return err
+		}
+
+		operations := make([]func(*image.Gray) (*image.Gray, error), 0, len(FlagMaskOperations))
+		for _, value := range FlagMaskOperations {
+			operation, err := parseMaskOperation(value)
+			if err != nil {
+				return err
+			}
+
+			operations = append(operations, operation)
+		}
+
+		result, err := loadMaskFromFile(FlagInputMediaFilePath, FlagMaskEdgeDetection)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range FlagCombineMaskFilePaths {
+			combinedMask, err := loadMaskFromFile(path, false)
+			if err != nil {
+				return err
+			}
+
+			if result, err = mask.Combine(result, combinedMask, operator); err != nil {
+				return err
+			}
+		}
+
+		for _, operation := range operations {
+			if result, err = operation(result); err != nil {
+				return err
+			}
+		}
+
+		if err := utils.StoreImageToFile(FlagOutputMediaFilePath, format, result); err != nil {
+			return err
+		}
+
+		LocalLogger.Infof("Mask processing finished (%s).", time.Since(commandExecTime))
+		return nil
+	},
+}
+
+func init() {
+	maskCmd.SilenceUsage = true
+
+	maskCmd.Flags().StringSliceVar(&FlagMaskOperations, "operations", []string{}, "The comma-separated list of the mask operations applied in order. Options: [dilate(radius), erode(radius), open(radius), close(radius), blur(sigma), threshold(0 - 255), invert].")
+
+	maskCmd.Flags().StringSliceVar(&FlagCombineMaskFilePaths, "combine-mask-paths", []string{}, "The comma-separated list of paths of the mask image files combined with the input mask before the operations are applied.")
+
+	maskCmd.Flags().StringVar(&FlagCombineOperator, "combine-operator", "and", "The operator used to combine the masks. Options: [and, or, xor, subtract].")
+
+	maskCmd.Flags().BoolVar(&FlagMaskEdgeDetection, "edge-detection", false, "Create the input mask by performing the edge detection on the input media, the same way as the edge interval determinant.")
+
+	rootCmd.AddCommand(maskCmd)
+}
+
+var maskGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a pattern mask image of the size of the specified image file.",
+	Long:  "Generate a pattern mask image (gradients, stripes, checkerboards, rings, random rectangles or noise) of the size of the specified image file, so the result can be used as the mask image of the sorting commands. Only the dimensions of the input media are read. Of the root command flags only the input-media-path, output-media-path and verbose flags are used.",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := parseMediaOptions(); err != nil {
+			return err
+		}
+
+		LocalLogger.Info("Starting the mask generation.")
+		commandExecTime := time.Now()
+
+		format, ok := determineFileExtension(FlagOutputMediaFilePath, []string{"jpeg", "jpg", "png"})
+		if !ok {
+			return fmt.Errorf("cmd: invalid output image file format specified (%s)", FlagOutputMediaFilePath)
+		}
+
+		options := mask.GetDefaultGeneratorOptions()
+
+		pattern, err := parseMaskPattern(FlagPattern)
+		if err != nil {
+			return err
+		}
+
+		options.Pattern = pattern
+		options.Angle = FlagPatternAngle
+		options.Size = FlagPatternSize
+		options.Count = FlagPatternCount
+		options.Threshold = FlagPatternThreshold
+		options.Seed = FlagPatternSeed
+		options.NoiseOctaves = FlagPatternOctaves
+		options.NoisePersistence = FlagPatternPersistence
+
+		config, err := utils.GetImageConfigFromFile(FlagInputMediaFilePath)
+		if err != nil {
+			return err
+		}
+
+		result, err := mask.Generate(config.Width, config.Height, options)
+		if err != nil {
+			return err
+		}
+
+		if err := utils.StoreImageToFile(FlagOutputMediaFilePath, format, result); err != nil {
+			return err
+		}
+
+		LocalLogger.Infof("Mask generation finished (%s).", time.Since(commandExecTime))
+		return nil
+	},
+}
+
+func init() {
+	maskGenerateCmd.SilenceUsage = true
+
+	maskGenerateCmd.Flags().StringVar(&FlagPattern, "pattern", "linear-gradient", "The pattern of the generated mask. Options: [linear-gradient, radial-gradient, stripes, checkerboard, rings, rectangles, noise].")
+
+	maskGenerateCmd.Flags().IntVar(&FlagPatternAngle, "pattern-angle", 0, "The angle of the linear gradient, stripes and checkerboard patterns.")
+
+	maskGenerateCmd.Flags().Float64Var(&FlagPatternSize, "pattern-size", 64, "The width in pixels of the stripes, checkerboard fields and rings, the max size of the rectangles and the size of the noise features. Options: [> 0.0].")
+
+	maskGenerateCmd.Flags().IntVar(&FlagPatternCount, "pattern-count", 16, "The count of the rectangles of the rectangles pattern. Options: [>= 0].")
+
+	maskGenerateCmd.Flags().Float64Var(&FlagPatternThreshold, "pattern-threshold", 0.5, "The threshold of the noise pattern, below which the noise is masked out. Options: [0.0 - 1.0].")
+
+	maskGenerateCmd.Flags().Int64Var(&FlagPatternSeed, "pattern-seed", 0, "The seed of the rectangles and noise patterns.")
+
+	maskGenerateCmd.Flags().IntVar(&FlagPatternOctaves, "pattern-octaves", 4, "The count of the noise octaves summed to create the noise pattern details. Options: [1 - 16].")
+
+	maskGenerateCmd.Flags().Float64Var(&FlagPatternPersistence, "pattern-persistence", 0.5, "The amplitude multiplier of each next noise octave of the noise pattern. Options: (0.0 - 1.0].")
+
+	maskCmd.AddCommand(maskGenerateCmd)
+}
+
+// Helper function used to load the mask image file using the mask-channel flag of the root command. The edge detection is optionally
+// performed on the image before it is turned into the mask, in which case the mask-channel flag is not used.
+func loadMaskFromFile(path string, edgeDetection bool) (*image.Gray, error) {
+	file, err := utils.GetImageFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if edgeDetection {
+		edges, err := img.PerformEdgeDetection(utils.ImageToNrgbaImage(file), false, true)
+		if err != nil {
+			return nil, fmt.Errorf("cmd: failed to perform the edge detection on the mask image: %w", err)
+		}
+
+		return mask.FromChannel(edges, mask.ChannelLuminance)
+	}
+
+	channel, err := parseMaskChannel(rootSorterFlags.MaskChannel)
+	if err != nil {
+		return nil, err
+	}
+
+	return mask.FromChannel(file, channel)
+}
+
+// Helper function used to parse the mask combine operator flag value
+func parseMaskCombineOperator(value string) (mask.Operator, error) {
+	switch strings.ToLower(value) {
+	case "and":
+		return mask.OperatorAnd, nil
+	case "or":
+		return mask.OperatorOr, nil
+	case "xor":
+		return mask.OperatorXor, nil
+	case "subtract":
+		return mask.OperatorSubtract, nil
+	default:
+		return 0, fmt.Errorf("cmd: invalid mask combine operator specified (%s)", value)
+	}
+}
+
+// Helper function used to parse the mask pattern flag value
+func parseMaskPattern(value string) (mask.Pattern, error) {
+	switch strings.ToLower(value) {
+	case "linear-gradient":
+		return mask.PatternLinearGradient, nil
+	case "radial-gradient":
+		return mask.PatternRadialGradient, nil
+	case "stripes":
+		return mask.PatternStripes, nil
+	case "checkerboard":
+		return mask.PatternCheckerboard, nil
+	case "rings":
+		return mask.PatternRings, nil
+	case "rectangles":
+		return mask.PatternRectangles, nil
+	case "noise":
+		return mask.PatternNoise, nil
+	default:
+		return 0, fmt.Errorf("cmd: invalid mask pattern specified (%s)", value)
+	}
+}
+
+// Helper function used to parse the mask operation flag value in the name(parameter) format
+func parseMaskOperation(value string) (func(*image.Gray) (*image.Gray, error), error) {
+	name, parameter := strings.ToLower(strings.TrimSpace(value)), ""
+	if index := strings.Index(name, "("); index != -1 {
+		if !strings.HasSuffix(name, ")") {
+			return nil, fmt.Errorf("cmd: invalid mask operation specified (%s)", value)
+		}
+
+		name, parameter = strings.TrimSpace(name[:index]), strings.TrimSpace(name[index+1:len(name)-1])
+	}
+
+	switch name {
+	case "dilate", "erode", "open", "close":
+		{
+			radius, err := strconv.Atoi(parameter)
+			if err != nil {
+				return nil, fmt.Errorf("cmd: invalid mask operation radius specified (%s): %w", value, err)
+			}
+
+			operation := map[string]func(*image.Gray, int) (*image.Gray, error){
+				"dilate": mask.Dilate,
+				"erode":  mask.Erode,
+				"open":   mask.Open,
+				"close":  mask.Close,
+			}[name]
+
+			return func(m *image.Gray) (*image.Gray, error) { return operation(m, radius) }, nil
+		}
+	case "blur":
+		{
+			sigma, err := strconv.ParseFloat(parameter, 64)
+			if err != nil {
+				return nil, fmt.Errorf("cmd: invalid mask operation sigma specified (%s): %w", value, err)
+			}
+
+			return func(m *image.Gray) (*image.Gray, error) { return mask.Blur(m, sigma) }, nil
+		}
+	case "threshold":
+		{
+			threshold, err := strconv.Atoi(parameter)
+			if err != nil || threshold < 0 || threshold > 255 {
+				return nil, fmt.Errorf("cmd: invalid mask operation threshold specified (%s)", value)
+			}
+
+			return func(m *image.Gray) (*image.Gray, error) { return mask.Threshold(m, uint8(threshold)), nil }, nil
+		}
+	case "invert":
+		{
+			if len(parameter) > 0 {
+				return nil, fmt.Errorf("cmd: the invert mask operation does not take a parameter (%s)", value)
+			}
+
+			return func(m *image.Gray) (*image.Gray, error) { return mask.Invert(m), nil }, nil
+		}
+	default:
+		return nil, fmt.Errorf("cmd: invalid mask operation specified (%s)", value)
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMaskOperationShouldParseValidOperations(t *testing.T) {
+	cases := map[string][]uint8{
+		"dilate(1)":      {255, 255, 255, 120},
+		"erode(1)":       {0, 0, 0, 0},
+		" Open( 1 ) ":    {0, 0, 0, 0},
+		"close(1)":       {255, 255, 120, 120},
+		"blur(0)":        {0, 255, 120, 0},
+		"threshold(121)": {0, 255, 0, 0},
+		"invert":         {255, 0, 135, 255},
+	}
+
+	for value, expected := range cases {
+		m := image.NewGray(image.Rect(0, 0, 4, 1))
+		m.Pix = []uint8{0, 255, 120, 0}
+
+		operation, err := parseMaskOperation(value)
+		assert.Nil(t, err)
+
+		result, err := operation(m)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, result.Pix, value)
+	}
+}
+
+func TestParseMaskOperationShouldReturnErrorForInvalidOperations(t *testing.T) {
+	cases := []string{"", "dilate", "dilate(a)", "blur(x)", "threshold(256)", "threshold(-1)", "invert(1)", "grow(2)", "erode(2"}
+
+	for _, value := range cases {
+		operation, err := parseMaskOperation(value)
+
+		assert.NotNil(t, err, value)
+		assert.Nil(t, operation)
+	}
+}
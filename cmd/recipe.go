@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Krzysztofz01/pixel-sorter/pkg/sorter"
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	FlagRecipeFilePath string
+)
+
+// Structure representing the recipe file describing the ordered list of sorting passes. Each pass is sorting the result of the previous pass.
+type Recipe struct {
+	Passes []RecipePass `yaml:"passes"`
+}
+
+// Structure representing a single pass of the recipe. The options are the values of the flags (e.g. "angle" or "interval-determinant")
+// overriding the flags specified in the command line for this pass. The sorted image is composited onto the previous result using the blend
+// mode and the opacity.
+type RecipePass struct {
+	Options map[string]any `yaml:"options"`
+	Blend   string         `yaml:"blend"`
+	Opacity *float64       `yaml:"opacity"`
+}
+
+var recipeCmd = &cobra.Command{
+	Use:   "recipe",
+	Short: "Perform the pixel sorting passes described by the recipe file on the specified image file.",
+	Long:  "Perform the pixel sorting passes described by the recipe file (YAML or JSON) on the specified image file. Each pass is using the flags specified in the command line overridden by the pass options and is composited onto the result of the previous pass. The scale can not be used, because the passes are composited at the input media size.",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		parseLoggingOptions()
+
+		LocalLogger.Info("Starting the recipe pixel sorting.")
+		commandExecTime := time.Now()
+
+		recipe, err := GetRecipeFromFile(FlagRecipeFilePath)
+		if err != nil {
+			LocalLogger.Errorf("Failed to load the recipe from the provided file: %s", err)
+			return err
+		}
+
+		format, ok := determineFileExtension(FlagOutputMediaFilePath, []string{"jpeg", "jpg", "png"})
+		if !ok {
+			return fmt.Errorf("cmd: invalid output image file format specified (%s)", FlagOutputMediaFilePath)
+		}
+
+		img, err := utils.GetImageFromFile(FlagInputMediaFilePath)
+		if err != nil {
+			return err
+		}
+
+		result, err := performRecipePasses(recipe, utils.ImageToNrgbaImage(img), rootCmd.PersistentFlags(), rootSorterFlags)
+		if err != nil {
+			return err
+		}
+
+		if err := utils.StoreImageToFile(FlagOutputMediaFilePath, format, result); err != nil {
+			return err
+		}
+
+		LocalLogger.Infof("Recipe pixel sorting finished (%s).", time.Since(commandExecTime))
+		return nil
+	},
+}
+
+func init() {
+	recipeCmd.SilenceUsage = true
+
+	recipeCmd.Flags().StringVar(&FlagRecipeFilePath, "recipe-path", "", "The path of the recipe file (yaml, yml or json) describing the ordered list of sorting passes.")
+	if err := recipeCmd.MarkFlagRequired("recipe-path"); err != nil {
+		panic(fmt.Errorf("cmd: failed to mark the recipe-path as required: %w", err))
+	}
+
+	rootCmd.AddCommand(recipeCmd)
+}
+
+// Read and parse the recipe file. The YAML and JSON files are supported.
+func GetRecipeFromFile(path string) (*Recipe, error) {
+	if _, ok := determineFileExtension(path, []string{"yaml", "yml", "json"}); !ok {
+		return nil, fmt.Errorf("cmd: invalid recipe file format specified (%s)", path)
+	}
+
+	path, err := utils.EscapePathQuotes(path)
+	if err != nil {
+		return nil, fmt.Errorf("cmd: failed to escape the recipe file path: %w", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cmd: failed to read the recipe file: %w", err)
+	}
+
+	return ParseRecipe(content)
+}
+
+// Parse the recipe from the given YAML or JSON content and validate the pass blend modes and opacities
+func ParseRecipe(content []byte) (*Recipe, error) {
+	recipe := new(Recipe)
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(recipe); err != nil {
+		return nil, fmt.Errorf("cmd: failed to parse the recipe: %w", err)
+	}
+
+	if len(recipe.Passes) == 0 {
+		return nil, fmt.Errorf("cmd: the recipe must contain at least one pass")
+	}
+
+	for index, pass := range recipe.Passes {
+		if _, err := parseRecipeBlend(pass.Blend); err != nil {
+			return nil, fmt.Errorf("cmd: invalid recipe pass %d: %w", index+1, err)
+		}
+
+		if pass.Opacity != nil && (*pass.Opacity < 0.0 || *pass.Opacity > 1.0) {
+			return nil, fmt.Errorf("cmd: invalid recipe pass %d: the opacity must be between values 0 and 1", index+1)
+		}
+	}
+
+	return recipe, nil
+}
+
+// The names of the flags specifying the sorter inputs loaded from the input media and the files. The recipe passes with the same values of
+// these flags are sharing the inputs, which are loaded only once.
+var recipePassInputFlagNames = []string{
+	"palette-size",
+	"mask-source",
+	"mask-image-path",
+	"mask-channel",
+	"mask-key-color",
+	"mask-key-tolerance",
+	"mask-invert",
+	"key-image-path",
+	"interval-map-image-path",
+	"length-map-image-path",
+	"direction-map-image-path",
+	"blending-opacity-image-path",
+	"cluster-map-output-path",
+	"cluster-count",
+	"cluster-seed",
+}
+
+// Structure representing the sorter inputs of the recipe pass
+type recipePassInputs struct {
+	mask      image.Image
+	auxiliary *sorter.AuxiliaryImages
+	palette   color.Palette
+}
+
+// Function used to perform all recipe passes on the given image. The options of each pass are parsed from a separate flag set holding the
+// given flag values overridden by the pass options, so the options of each pass are parsed and validated in the same way as the options of
+// the image command. The inputs of the passes (e.g. the alpha and color-key masks, the median cut palette and the cluster map) are derived
+// from the given original image instead of the result of the previous pass.
+func performRecipePasses(recipe *Recipe, img *image.NRGBA, flags *pflag.FlagSet, values *sorterFlags) (*image.NRGBA, error) {
+	var (
+		result *image.NRGBA                 = img
+		inputs map[string]*recipePassInputs = make(map[string]*recipePassInputs)
+	)
+
+	for index, pass := range recipe.Passes {
+		passExecTime := time.Now()
+
+		passFlags, passValues, err := createRecipePassFlags(flags, values, pass.Options)
+		if err != nil {
+			return nil, fmt.Errorf("cmd: invalid recipe pass %d options: %w", index+1, err)
+		}
+
+		options, err := parseSorterOptions(passFlags, passValues)
+		if err != nil {
+			return nil, fmt.Errorf("cmd: invalid recipe pass %d options: %w", index+1, err)
+		}
+
+		// NOTE: The scaled sort is producing a smaller image, which can not be composited onto the result of the previous pass
+		if options.Scale != 1.0 {
+			return nil, fmt.Errorf("cmd: invalid recipe pass %d options: the scale can not be used by the recipe passes, because the results of the passes are composited at the input media size", index+1)
+		}
+
+		key := createRecipePassInputsKey(passFlags)
+		if _, ok := inputs[key]; !ok {
+			mask, auxiliary, err := loadSorterInputs(img, passValues, options)
+			if err != nil {
+				return nil, err
+			}
+
+			inputs[key] = &recipePassInputs{mask: mask, auxiliary: auxiliary, palette: options.QuantizePalette}
+		}
+
+		passInputs := inputs[key]
+		options.QuantizePalette = passInputs.palette
+
+		s, err := sorter.CreateSorterWithAuxiliaryImages(result, passInputs.mask, passInputs.auxiliary, SorterLogger, options)
+		if err != nil {
+			return nil, err
+		}
+
+		sortedImage, err := s.Sort()
+		if err != nil {
+			return nil, err
+		}
+
+		if result, err = compositeRecipePass(result, utils.ImageToNrgbaImage(sortedImage), pass); err != nil {
+			return nil, err
+		}
+
+		LocalLogger.Infof("Recipe pass %d finished (%s).", index+1, time.Since(passExecTime))
+	}
+
+	return result, nil
+}
+
+// Create the flag set of the recipe pass bound to a copy of the given flag values overridden by the pass options. The flags specified in the
+// given flag set are also marked as specified in the pass flag set. The given flag set and values are not modified.
+func createRecipePassFlags(flags *pflag.FlagSet, values *sorterFlags, options map[string]any) (*pflag.FlagSet, *sorterFlags, error) {
+	passValues := new(sorterFlags)
+
+	passFlags := pflag.NewFlagSet("recipe-pass", pflag.ContinueOnError)
+	registerSorterFlags(passFlags, passValues)
+
+	// NOTE: The values are copied after the flags registration, because the registration is assigning the default values
+	*passValues = *values
+
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if passFlag := passFlags.Lookup(flag.Name); passFlag != nil {
+			passFlag.Changed = flag.Changed
+		}
+	})
+
+	if err := applyRecipePassOptions(passFlags, options); err != nil {
+		return nil, nil, err
+	}
+
+	return passFlags, passValues, nil
+}
+
+// Create the key identifying the sorter inputs specified by the given recipe pass flag set
+func createRecipePassInputsKey(flags *pflag.FlagSet) string {
+	values := make([]string, 0, len(recipePassInputFlagNames))
+	for _, name := range recipePassInputFlagNames {
+		values = append(values, flags.Lookup(name).Value.String())
+	}
+
+	return strings.Join(values, "\x00")
+}
+
+// Function used to composite the sorted image of the pass onto the previous result using the blend mode and opacity of the pass
+func compositeRecipePass(previous, sorted *image.NRGBA, pass RecipePass) (*image.NRGBA, error) {
+	blend, err := parseRecipeBlend(pass.Blend)
+	if err != nil {
+		return nil, err
+	}
+
+	if blend != nil {
+		if sorted, err = utils.BlendImagesNrgba(previous, sorted, *blend); err != nil {
+			return nil, fmt.Errorf("cmd: failed to blend the recipe pass result: %w", err)
+		}
+	}
+
+	if pass.Opacity == nil || *pass.Opacity == 1.0 {
+		return sorted, nil
+	}
+
+	if sorted, err = utils.MixImagesNrgba(previous, sorted, *pass.Opacity); err != nil {
+		return nil, fmt.Errorf("cmd: failed to mix the recipe pass result: %w", err)
+	}
+
+	return sorted, nil
+}
+
+// Helper function used to parse the recipe pass blend mode. The nil blending mode is representing the normal blend mode.
+func parseRecipeBlend(value string) (*utils.BlendingMode, error) {
+	var mode utils.BlendingMode
+
+	switch strings.ToLower(value) {
+	case "", "normal":
+		return nil, nil
+	case "lighten":
+		mode = utils.LightenOnly
+	case "darken":
+		mode = utils.DarkenOnly
+	case "multiply":
+		mode = utils.Multiply
+	case "screen":
+		mode = utils.Screen
+	case "overlay":
+		mode = utils.Overlay
+	case "soft-light":
+		mode = utils.SoftLight
+	case "hard-light":
+		mode = utils.HardLight
+	case "difference":
+		mode = utils.Difference
+	case "exclusion":
+		mode = utils.Exclusion
+	case "color-dodge":
+		mode = utils.ColorDodge
+	case "color-burn":
+		mode = utils.ColorBurn
+	case "hue":
+		mode = utils.Hue
+	case "saturation":
+		mode = utils.Saturation
+	case "color":
+		mode = utils.Color
+	case "luminosity":
+		mode = utils.Luminosity
+	default:
+		return nil, fmt.Errorf("cmd: invalid recipe pass blend mode specified (%s)", value)
+	}
+
+	return &mode, nil
+}
+
+// Set the flags according to the given recipe pass options. The option values can be scalars or lists for the flags accepting lists.
+func applyRecipePassOptions(flags *pflag.FlagSet, options map[string]any) error {
+	for name, value := range options {
+		switch name {
+		case "input-media-path", "output-media-path", "verbose":
+			return fmt.Errorf("cmd: the %s flag can not be specified by the recipe pass", name)
+		}
+
+		flag := flags.Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("cmd: unknown recipe pass option specified (%s)", name)
+		}
+
+		if values, ok := value.([]any); ok {
+			sliceValue, ok := flag.Value.(pflag.SliceValue)
+			if !ok {
+				return fmt.Errorf("cmd: the %s option does not accept a list of values", name)
+			}
+
+			items := make([]string, 0, len(values))
+			for _, item := range values {
+				items = append(items, fmt.Sprint(item))
+			}
+
+			if err := sliceValue.Replace(items); err != nil {
+				return fmt.Errorf("cmd: invalid %s option value specified: %w", name, err)
+			}
+
+			flag.Changed = true
+			continue
+		}
+
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			if err := sliceValue.Replace(strings.Split(fmt.Sprint(value), ",")); err != nil {
+				return fmt.Errorf("cmd: invalid %s option value specified: %w", name, err)
+			}
+
+			flag.Changed = true
+			continue
+		}
+
+		if err := flags.Set(name, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("cmd: invalid %s option value specified: %w", name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRecipeShouldParseYamlRecipe(t *testing.T) {
+	content := `
+passes:
+  - options:
+      angle: 45
+      interval-determinant: hue
+      mask: true
+  - options:
+      cluster-indices: [0, 2]
+    blend: lighten
+    opacity: 0.5
+`
+
+	recipe, err := ParseRecipe([]byte(content))
+
+	assert.Nil(t, err)
+	assert.Len(t, recipe.Passes, 2)
+	assert.Equal(t, map[string]any{"angle": 45, "interval-determinant": "hue", "mask": true}, recipe.Passes[0].Options)
+	assert.Nil(t, recipe.Passes[0].Opacity)
+	assert.Equal(t, "lighten", recipe.Passes[1].Blend)
+	assert.Equal(t, 0.5, *recipe.Passes[1].Opacity)
+}
+
+func TestParseRecipeShouldParseJsonRecipe(t *testing.T) {
+	content := `{"passes": [{"options": {"angle": 90, "direction": "descending"}, "blend": "darken", "opacity": 1}]}`
+
+	recipe, err := ParseRecipe([]byte(content))
+
+	assert.Nil(t, err)
+	assert.Len(t, recipe.Passes, 1)
+	assert.Equal(t, map[string]any{"angle": 90, "direction": "descending"}, recipe.Passes[0].Options)
+	assert.Equal(t, "darken", recipe.Passes[0].Blend)
+}
+
+func TestParseRecipeShouldNotParseInvalidRecipe(t *testing.T) {
+	cases := []string{
+		``,
+		`passes: []`,
+		`passes: [{options: {angle: 45}, blend: multiply-twice}]`,
+		`passes: [{options: {angle: 45}, opacity: 1.5}]`,
+		`passes: [{options: {angle: 45}, unknown: 1}]`,
+		`passes: {}`,
+	}
+
+	for _, content := range cases {
+		recipe, err := ParseRecipe([]byte(content))
+
+		assert.Nil(t, recipe)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestApplyRecipePassOptionsShouldSetFlags(t *testing.T) {
+	var (
+		angle   int
+		mode    string
+		indices []int
+	)
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.IntVar(&angle, "angle", 0, "")
+	flags.StringVar(&mode, "mode", "none", "")
+	flags.IntSliceVar(&indices, "indices", []int{1}, "")
+
+	err := applyRecipePassOptions(flags, map[string]any{"angle": 30, "mode": "rgb", "indices": []any{2, 3}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 30, angle)
+	assert.Equal(t, "rgb", mode)
+	assert.Equal(t, []int{2, 3}, indices)
+	assert.True(t, flags.Changed("angle"))
+	assert.True(t, flags.Changed("indices"))
+
+	err = applyRecipePassOptions(flags, map[string]any{"indices": "4,5"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{4, 5}, indices)
+}
+
+func TestCreateRecipePassFlagsShouldNotModifyTheGivenFlags(t *testing.T) {
+	values := new(sorterFlags)
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	registerSorterFlags(flags, values)
+
+	assert.Nil(t, flags.Set("angle", "10"))
+	assert.Nil(t, flags.Set("seed", "5"))
+	assert.Nil(t, flags.Set("cluster-indices", "1"))
+
+	passFlags, passValues, err := createRecipePassFlags(flags, values, map[string]any{"angle": 30, "cluster-indices": []any{2, 3}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 30, passValues.Angle)
+	assert.Equal(t, []int{2, 3}, passValues.ClusterIndices)
+	assert.Equal(t, int64(5), passValues.Seed)
+	assert.True(t, passFlags.Changed("seed"))
+	assert.False(t, passFlags.Changed("noise-seed"))
+
+	assert.Equal(t, 10, values.Angle)
+	assert.Equal(t, []int{1}, values.ClusterIndices)
+}
+
+func TestCreateRecipePassInputsKeyShouldOnlyDependOnInputFlags(t *testing.T) {
+	values := new(sorterFlags)
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	registerSorterFlags(flags, values)
+
+	a, _, err := createRecipePassFlags(flags, values, map[string]any{"angle": 30})
+	assert.Nil(t, err)
+
+	b, _, err := createRecipePassFlags(flags, values, map[string]any{"angle": 60})
+	assert.Nil(t, err)
+
+	c, _, err := createRecipePassFlags(flags, values, map[string]any{"mask-source": "alpha"})
+	assert.Nil(t, err)
+
+	assert.Equal(t, createRecipePassInputsKey(a), createRecipePassInputsKey(b))
+	assert.NotEqual(t, createRecipePassInputsKey(a), createRecipePassInputsKey(c))
+}
+
+func TestApplyRecipePassOptionsShouldNotApplyInvalidOptions(t *testing.T) {
+	var (
+		angle int
+		path  string
+	)
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.IntVar(&angle, "angle", 0, "")
+	flags.StringVar(&path, "input-media-path", "", "")
+
+	cases := []map[string]any{
+		{"unknown": 1},
+		{"angle": "x"},
+		{"angle": []any{1, 2}},
+		{"input-media-path": "image.png"},
+	}
+
+	for _, options := range cases {
+		assert.NotNil(t, applyRecipePassOptions(flags, options))
+	}
+}
+
+func TestCompositeRecipePassShouldBlendAndMixResult(t *testing.T) {
+	previous := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	previous.SetNRGBA(0, 0, color.NRGBA{100, 200, 0, 255})
+
+	sorted := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	sorted.SetNRGBA(0, 0, color.NRGBA{200, 100, 100, 255})
+
+	opacity := 0.5
+
+	cases := []struct {
+		pass     RecipePass
+		expected color.NRGBA
+	}{
+		{RecipePass{Blend: "", Opacity: nil}, color.NRGBA{200, 100, 100, 255}},
+		{RecipePass{Blend: "normal", Opacity: &opacity}, color.NRGBA{150, 150, 50, 255}},
+		{RecipePass{Blend: "lighten", Opacity: nil}, color.NRGBA{200, 200, 100, 255}},
+		{RecipePass{Blend: "darken", Opacity: &opacity}, color.NRGBA{100, 150, 0, 255}},
+		{RecipePass{Blend: "difference", Opacity: nil}, color.NRGBA{100, 100, 100, 255}},
+	}
+
+	for _, c := range cases {
+		actual, err := compositeRecipePass(previous, sorted, c.pass)
+
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, actual.NRGBAAt(0, 0))
+	}
+}
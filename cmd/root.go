@@ -2,39 +2,117 @@ package cmd
 
 import (
 	"fmt"
+	"image"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/Krzysztofz01/pixel-sorter/pkg/mask"
 	"github.com/Krzysztofz01/pixel-sorter/pkg/sorter"
 	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
 	nestedFormatter "github.com/antonfisher/nested-logrus-formatter"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
-	FlagInputMediaFilePath         string
-	FlagOutputMediaFilePath        string
-	FlagMaskImageFilePath          string
-	FlagSortDeterminant            string
-	FlagSortDirection              string
-	FlagSortOrder                  string
-	FlagIntervalDeterminant        string
-	FlagIntervalPainting           string
-	FlagIntervalLowerThreshold     float64
-	FlagIntervalUpperThreshold     float64
-	FlagAngle                      int
-	FlagMask                       bool
-	FlagIntervalLength             int
-	FlagSortCycles                 int
-	FlagImageScale                 float64
-	FlagBlendingMode               string
-	FlagVerboseLogging             bool
-	FlagIntervalLengthRandomFactor int
+	FlagInputMediaFilePath  string
+	FlagOutputMediaFilePath string
+	FlagVerboseLogging      bool
 )
 
+// Structure representing the values of the flags describing the sorter options and inputs. The persistent flags of the root command are
+// bound to the root values and each recipe pass is using its own copy of the values.
+type sorterFlags struct {
+	MaskImageFilePath           string
+	KeyImageFilePath            string
+	IntervalMapImageFilePath    string
+	LengthMapImageFilePath      string
+	DirectionMapImageFilePath   string
+	OpacityImageFilePath        string
+	SortDeterminant             string
+	SortDirection               string
+	DirectionScheme             string
+	DirectionBrightness         float64
+	SortOrder                   string
+	SortStrength                float64
+	IntervalDeterminant         string
+	IntervalPainting            string
+	GradientStops               int
+	GradientColorSpace          string
+	QuantizeLevels              int
+	PaletteFilePath             string
+	PaletteSize                 int
+	Dithering                   string
+	IntervalLowerThreshold      float64
+	IntervalUpperThreshold      float64
+	IntervalHueWrapAround       bool
+	IntervalExpression          string
+	IntervalHysteresis          bool
+	IntervalStayLowerThreshold  float64
+	IntervalStayUpperThreshold  float64
+	IntervalGapTolerance        int
+	IntervalMinLength           int
+	IntervalRedLowerThreshold   float64
+	IntervalRedUpperThreshold   float64
+	IntervalGreenLowerThreshold float64
+	IntervalGreenUpperThreshold float64
+	IntervalBlueLowerThreshold  float64
+	IntervalBlueUpperThreshold  float64
+	IntervalDifferenceMetric    string
+	IntervalDifferenceThreshold float64
+	IntervalThresholdMode       string
+	IntervalLowerPercentile     float64
+	IntervalUpperPercentile     float64
+	Angle                       int
+	Mask                        bool
+	MaskThreshold               int
+	MaskFeatherRadius           float64
+	SoftMask                    bool
+	MaskSource                  string
+	MaskChannel                 string
+	MaskKeyColor                string
+	MaskKeyTolerance            float64
+	MaskInvert                  bool
+	IntervalLength              int
+	SortCycles                  int
+	ScheduleAngle               string
+	ScheduleLowerThreshold      string
+	ScheduleUpperThreshold      string
+	ScheduleIntervalLength      string
+	ScheduleOrders              []string
+	ScheduleDirections          []string
+	CompositeAngles             []int
+	AngleComposite              string
+	CompositeMemoryBudget       int
+	ImageScale                  float64
+	BlendingMode                string
+	BlendingOpacity             float64
+	IntervalLengthRandomFactor  int
+	IntervalLengthSource        string
+	IntervalLengthDistribution  string
+	IntervalLengthDeviation     float64
+	IntervalLengthExponent      float64
+	Seed                        int64
+	NoiseSeed                   int64
+	NoiseScale                  float64
+	NoiseOctaves                int
+	NoisePersistence            float64
+	ClusterCount                int
+	ClusterSeed                 int64
+	ClusterIndices              []int
+	ClusterMapOutputFilePath    string
+	ChannelSplit                string
+	ChannelSplitDeterminant     string
+	ChannelLowerThresholds      []float64
+	ChannelUpperThresholds      []float64
+	ChannelAngleOffsets         []int
+}
+
+var rootSorterFlags = new(sorterFlags)
+
 var (
 	Logger       *logrus.Logger
 	LocalLogger  *logrus.Entry
@@ -68,39 +146,180 @@ func init() {
 		panic(fmt.Errorf("cmd: failed to mark the output-media-path as required: %w", err))
 	}
 
-	rootCmd.PersistentFlags().StringVar(&FlagMaskImageFilePath, "mask-image-path", "", "The path of the mask image file used to process the input media.")
+	registerSorterFlags(rootCmd.PersistentFlags(), rootSorterFlags)
+}
+
+// Function used to define the flags describing the sorter options and inputs in the given flag set and to bind them to the given values
+func registerSorterFlags(flags *pflag.FlagSet, values *sorterFlags) {
+	flags.StringVar(&values.MaskImageFilePath, "mask-image-path", "", "The path of the mask image file used to process the input media.")
+
+	flags.StringVar(&values.KeyImageFilePath, "key-image-path", "", "The path of the key image file used as the source of the sort weights by the key sort determinant.")
+
+	flags.StringVar(&values.IntervalMapImageFilePath, "interval-map-image-path", "", "The path of the grayscale interval map image file compared against the thresholds by the map interval determinant.")
+
+	flags.StringVar(&values.LengthMapImageFilePath, "length-map-image-path", "", "The path of the grayscale length map image file used as the source of the interval max length by the map interval max length source.")
+
+	flags.StringVar(&values.DirectionMapImageFilePath, "direction-map-image-path", "", "The path of the grayscale direction map image file used as the source of the interval sort directions by the map direction scheme.")
+
+	flags.StringVarP(&values.SortDeterminant, "sort-determinant", "e", "brightness", "Parameter used as the argument for the sorting algorithm. Options: [brightness, hue, saturation, absolute, red, green, blue, key, noise].")
+
+	flags.StringVarP(&values.SortDirection, "direction", "d", "ascending", "Pixel sorting direction in intervals. Options: [ascending, descending, shuffle, random].")
+
+	flags.StringVar(&values.DirectionScheme, "direction-scheme", "uniform", "The scheme used to alternate the intervals between the sorting direction and the reversed sorting direction. Options: [uniform, strips, intervals, noise, map, brightness].")
+
+	flags.Float64Var(&values.DirectionBrightness, "direction-brightness-threshold", 0.5, "The average interval brightness above which the intervals are sorted in the reversed direction by the brightness direction scheme. Options: [0.0 - 1.0].")
+
+	flags.StringVarP(&values.SortOrder, "order", "o", "horizontal-vertical", "Order of the graphic sorting stages. Options: [horizontal, vertical, horizontal-vertical, vertical-horizontal].")
+
+	flags.Float64Var(&values.SortStrength, "sort-strength", 1, "The part of the way each pixel is moved from its original position toward its sorted position. Options: [0.0 - 1.0].")
+
+	flags.StringVarP(&values.IntervalDeterminant, "interval-determinant", "i", "brightness", "Parameter used to determine intervals. Options: [brightness, hue, saturation, mask, absolute, edge, map, rgb, difference, noise, expression, clusters].")
+
+	flags.StringVarP(&values.IntervalPainting, "interval-painting", "p", "fill", "Parameter used to specify the interval color painting behaviour. Options: [fill, gradient, repeat, average, reverse, mirror, median, smear, quantize].")
+
+	flags.IntVar(&values.GradientStops, "gradient-stops", 0, "The count of the gradient stops taken from evenly spaced quantiles of the sorted interval by the gradient interval painting. Zero means the three-point quadratic blend. Options: [0, 2 - 256].")
+
+	flags.StringVar(&values.GradientColorSpace, "gradient-color-space", "srgb", "The color space in which the gradient interval painting colors are interpolated. Options: [srgb, linear, oklab, hsl].")
+
+	flags.IntVar(&values.QuantizeLevels, "quantize-levels", 4, "The count of the colors to which each interval is quantized by the quantize interval painting if no palette is specified. Options: [2 - 256].")
+
+	flags.StringVar(&values.PaletteFilePath, "palette-path", "", "The path of the palette file (.gpl or .hex) used as the global palette by the quantize interval painting.")
+
+	flags.IntVar(&values.PaletteSize, "palette-size", 0, "The count of the colors of the global palette created from the input media using the median cut algorithm for the quantize interval painting. Zero means no median cut palette. Options: [0 - 256].")
+
+	flags.StringVar(&values.Dithering, "dithering", "none", "The ordered dithering used by the quantize interval painting. Options: [none, bayer4, bayer8].")
+
+	flags.Float64VarP(&values.IntervalLowerThreshold, "interval-lower-threshold", "l", 0.1, "The lower threshold of the interval determination process. Options: [0.0 - 1.0].")
+
+	flags.Float64VarP(&values.IntervalUpperThreshold, "interval-upper-threshold", "u", 0.9, "The upper threshold of the interval determination process. Options: [0.0 - 1.0].")
+
+	flags.BoolVar(&values.IntervalHueWrapAround, "hue-wrap-around", false, "Allow the hue interval range to cross the 0/360 degrees point when the lower threshold is greater than the upper threshold. Also applies to the hue clauses of the interval expression.")
+
+	flags.BoolVar(&values.IntervalHysteresis, "interval-hysteresis", false, "Use the separate stay thresholds to check if the pixels are extending an already started interval. The interval thresholds are only used to start the intervals. Not supported by the mask, edge, rgb, difference, expression and clusters interval determinants.")
+
+	flags.Float64Var(&values.IntervalStayLowerThreshold, "interval-stay-lower-threshold", 0, "The lower threshold used to extend an already started interval if the hysteresis is used. Options: [0.0 - 1.0].")
+
+	flags.Float64Var(&values.IntervalStayUpperThreshold, "interval-stay-upper-threshold", 1, "The upper threshold used to extend an already started interval if the hysteresis is used. Options: [0.0 - 1.0].")
+
+	flags.IntVar(&values.IntervalGapTolerance, "interval-gap-tolerance", 0, "The count of consecutive pixels not meeting the interval requirements that can stay inside an interval. Options: [>= 0].")
+
+	flags.IntVar(&values.IntervalMinLength, "interval-min-length", 0, "The min length of the interval. The pixels of shorter intervals are left unsorted. Options: [>= 0].")
+
+	flags.StringVar(&values.IntervalExpression, "interval-expression", "", "The composite interval determinant expression used by the expression interval determinant. Example: \"brightness(0.2, 0.8) and not hue(0.55, 0.7) and mask\".")
+
+	flags.Float64Var(&values.IntervalRedLowerThreshold, "interval-red-lower-threshold", 0, "The lower threshold of the red channel used by the rgb interval determinant. Options: [0.0 - 1.0].")
+
+	flags.Float64Var(&values.IntervalRedUpperThreshold, "interval-red-upper-threshold", 1, "The upper threshold of the red channel used by the rgb interval determinant. Options: [0.0 - 1.0].")
+
+	flags.Float64Var(&values.IntervalGreenLowerThreshold, "interval-green-lower-threshold", 0, "The lower threshold of the green channel used by the rgb interval determinant. Options: [0.0 - 1.0].")
+
+	flags.Float64Var(&values.IntervalGreenUpperThreshold, "interval-green-upper-threshold", 1, "The upper threshold of the green channel used by the rgb interval determinant. Options: [0.0 - 1.0].")
+
+	flags.Float64Var(&values.IntervalBlueLowerThreshold, "interval-blue-lower-threshold", 0, "The lower threshold of the blue channel used by the rgb interval determinant. Options: [0.0 - 1.0].")
+
+	flags.Float64Var(&values.IntervalBlueUpperThreshold, "interval-blue-upper-threshold", 1, "The upper threshold of the blue channel used by the rgb interval determinant. Options: [0.0 - 1.0].")
+
+	flags.StringVar(&values.IntervalDifferenceMetric, "interval-difference-metric", "brightness", "The metric used to measure the difference between adjacent pixels by the difference interval determinant. Options: [brightness, oklab].")
+
+	flags.Float64Var(&values.IntervalDifferenceThreshold, "interval-difference-threshold", 0.1, "The difference between adjacent pixels above which a new interval is started by the difference interval determinant. Options: [0.0 - 1.0].")
+
+	flags.StringVar(&values.IntervalThresholdMode, "interval-threshold-mode", "manual", "The method used to select the interval determinant thresholds. Options: [manual, otsu, percentile].")
+
+	flags.Float64Var(&values.IntervalLowerPercentile, "lower-percentile", 0, "The percentile of the image interval determinant values used as the lower threshold by the percentile threshold mode. Options: [0.0 - 100.0].")
+
+	flags.Float64Var(&values.IntervalUpperPercentile, "upper-percentile", 100, "The percentile of the image interval determinant values used as the upper threshold by the percentile threshold mode. Options: [0.0 - 100.0].")
+
+	flags.IntVarP(&values.Angle, "angle", "a", 0, "The angle at which to sort the pixels.")
+
+	flags.BoolVarP(&values.Mask, "mask", "m", false, "Exclude the sorting effect from masked out ares of the image.")
+
+	flags.IntVar(&values.MaskThreshold, "mask-threshold", 127, "The mask grayscale value below which the pixels are masked out and split the intervals. Options: [0 - 255].")
+
+	flags.Float64Var(&values.MaskFeatherRadius, "mask-feather-radius", 0, "The radius in pixels of the gaussian blur used to feather the mask edges. Options: [>= 0.0].")
+
+	flags.BoolVar(&values.SoftMask, "soft-mask", false, "Blend the sorted image with the original image according to the mask grayscale values instead of only excluding the masked out areas.")
+
+	flags.StringVar(&values.MaskSource, "mask-source", "image", "The source of the mask. The image source is using the mask image, the alpha source is using the input alpha channel and the color-key source is masking out the input pixels close to the key color. Options: [image, alpha, color-key].")
+
+	flags.StringVar(&values.MaskChannel, "mask-channel", "gray", "The channel of the mask image used as the mask. The gray channel requires a grayscale mask image. Options: [gray, luminance, red, green, blue, alpha].")
+
+	flags.StringVar(&values.MaskKeyColor, "mask-key-color", "#00ff00", "The hex color used as the key by the color-key mask source.")
+
+	flags.Float64Var(&values.MaskKeyTolerance, "mask-key-tolerance", 0.2, "The max normalized distance between the pixel color and the key color used by the color-key mask source. Options: [0.0 - 1.0].")
+
+	flags.BoolVar(&values.MaskInvert, "mask-invert", false, "Invert the mask created from the mask source.")
+
+	flags.IntVarP(&values.IntervalLength, "interval-max-length", "k", 0, "The max length of the interval. Zero means no length limits.")
+
+	flags.IntVarP(&values.IntervalLengthRandomFactor, "interval-max-length-random-factor", "r", 0, "The value representing the range of values that can be randomly subtracted or added to the max interval length. Options: [>= 0]")
+
+	flags.StringVar(&values.IntervalLengthSource, "interval-max-length-source", "fixed", "The source of the max length of the interval. The noise and map sources multiply the max length by the noise or length map value at the interval start. Options: [fixed, noise, map].")
+
+	flags.StringVar(&values.IntervalLengthDistribution, "interval-max-length-distribution", "uniform", "The statistical distribution of the interval max lengths. Options: [uniform, normal, exponential, lognormal, powerlaw].")
+
+	flags.Float64Var(&values.IntervalLengthDeviation, "interval-max-length-deviation", 0.5, "The relative standard deviation of the normal distribution and the sigma of the log-normal distribution of the interval max lengths. Options: [>= 0.0].")
+
+	flags.Float64Var(&values.IntervalLengthExponent, "interval-max-length-exponent", 2.0, "The exponent of the power-law distribution of the interval max lengths. Options: [> 1.0].")
+
+	flags.Int64Var(&values.Seed, "seed", 0, "The seed of the random decisions (interval max lengths, random direction and shuffle). The same seed and flags always produce the same image. The random decisions are not reproducible if the seed is not specified.")
+
+	flags.Int64Var(&values.NoiseSeed, "noise-seed", 0, "The seed of the noise field used by the noise determinants and the noise interval max length source.")
+
+	flags.Float64Var(&values.NoiseScale, "noise-scale", 100, "The size of the noise field features in pixels of the input image. Options: [> 0.0].")
+
+	flags.IntVar(&values.NoiseOctaves, "noise-octaves", 4, "The count of the noise octaves summed to create the noise field details. Options: [1 - 16].")
+
+	flags.Float64Var(&values.NoisePersistence, "noise-persistence", 0.5, "The amplitude multiplier of each next noise octave. Options: (0.0 - 1.0].")
+
+	flags.IntVar(&values.ClusterCount, "cluster-count", 8, "The count of the color clusters used by the clusters interval determinant. Options: [2 - 255].")
+
+	flags.Int64Var(&values.ClusterSeed, "cluster-seed", 0, "The seed of the k-means color clustering used by the clusters interval determinant.")
+
+	flags.IntSliceVar(&values.ClusterIndices, "cluster-indices", nil, "The indices of the color clusters which intervals should be sorted by the clusters interval determinant. Empty means all clusters. Example: \"0,2,5\".")
+
+	flags.StringVar(&values.ClusterMapOutputFilePath, "cluster-map-output-path", "", "The path of the palette-indexed PNG image file to which the color cluster labels should be saved for inspection.")
+
+	flags.StringVar(&values.ChannelSplit, "channel-split", "none", "The color channels which are treated as separate planes, sorted independently and recombined. Options: [none, rgb, rgba].")
+
+	flags.StringVar(&values.ChannelSplitDeterminant, "channel-split-determinant", "own", "The source of the sort and interval values of the separately sorted color channel planes. Options: [own, shared].")
+
+	flags.Float64SliceVar(&values.ChannelLowerThresholds, "channel-lower-thresholds", nil, "The interval lower thresholds of the red, green, blue and alpha channel planes. Empty means the interval lower threshold for all planes. Example: \"0.1,0.2,0.3\".")
 
-	rootCmd.PersistentFlags().StringVarP(&FlagSortDeterminant, "sort-determinant", "e", "brightness", "Parameter used as the argument for the sorting algorithm. Options: [brightness, hue, saturation, absolute, red, green, blue ].")
+	flags.Float64SliceVar(&values.ChannelUpperThresholds, "channel-upper-thresholds", nil, "The interval upper thresholds of the red, green, blue and alpha channel planes. Empty means the interval upper threshold for all planes. Example: \"0.9,0.8,0.7\".")
 
-	rootCmd.PersistentFlags().StringVarP(&FlagSortDirection, "direction", "d", "ascending", "Pixel sorting direction in intervals. Options: [ascending, descending, shuffle, random].")
+	flags.IntSliceVar(&values.ChannelAngleOffsets, "channel-angle-offsets", nil, "The angle offsets added to the sorting angle of the red, green, blue and alpha channel planes. Empty means no offsets. Example: \"0,10,20\".")
 
-	rootCmd.PersistentFlags().StringVarP(&FlagSortOrder, "order", "o", "horizontal-vertical", "Order of the graphic sorting stages. Options: [horizontal, vertical, horizontal-vertical, vertical-horizontal].")
+	flags.IntVarP(&values.SortCycles, "cycles", "c", 1, "The count of sorting cycles that should be performed on the image.")
 
-	rootCmd.PersistentFlags().StringVarP(&FlagIntervalDeterminant, "interval-determinant", "i", "brightness", "Parameter used to determine intervals. Options: [brightness, hue, saturation, mask, absolute, edge].")
+	flags.StringVar(&values.ScheduleAngle, "schedule-angle", "", "The schedule of the angle across the sorting cycles given as a step added per cycle or a list of values. Example: \"+15\" or \"0,45,90\".")
 
-	rootCmd.PersistentFlags().StringVarP(&FlagIntervalPainting, "interval-painting", "p", "fill", "Parameter used to specify the interval color painting behaviour. Options: [fill, gradient, repeat, average].")
+	flags.StringVar(&values.ScheduleLowerThreshold, "schedule-lower-threshold", "", "The schedule of the interval lower threshold across the sorting cycles given as a step added per cycle or a list of values. Example: \"+0.05\" or \"0.1,0.3\".")
 
-	rootCmd.PersistentFlags().Float64VarP(&FlagIntervalLowerThreshold, "interval-lower-threshold", "l", 0.1, "The lower threshold of the interval determination process. Options: [0.0 - 1.0].")
+	flags.StringVar(&values.ScheduleUpperThreshold, "schedule-upper-threshold", "", "The schedule of the interval upper threshold across the sorting cycles given as a step added per cycle or a list of values. Example: \"-0.05\" or \"0.9,0.7\".")
 
-	rootCmd.PersistentFlags().Float64VarP(&FlagIntervalUpperThreshold, "interval-upper-threshold", "u", 0.9, "The upper threshold of the interval determination process. Options: [0.0 - 1.0].")
+	flags.StringVar(&values.ScheduleIntervalLength, "schedule-interval-max-length", "", "The schedule of the interval max length across the sorting cycles given as a step added per cycle or a list of values. Example: \"+10\" or \"20,40\".")
 
-	rootCmd.PersistentFlags().IntVarP(&FlagAngle, "angle", "a", 0, "The angle at which to sort the pixels.")
+	flags.StringSliceVar(&values.ScheduleOrders, "schedule-order", nil, "The list of the sorting orders used by the subsequent sorting cycles. Example: \"horizontal,vertical\".")
 
-	rootCmd.PersistentFlags().BoolVarP(&FlagMask, "mask", "m", false, "Exclude the sorting effect from masked out ares of the image.")
+	flags.StringSliceVar(&values.ScheduleDirections, "schedule-direction", nil, "The list of the sorting directions used by the subsequent sorting cycles. Example: \"ascending,descending\".")
 
-	rootCmd.PersistentFlags().IntVarP(&FlagIntervalLength, "interval-max-length", "k", 0, "The max length of the interval. Zero means no length limits.")
+	flags.IntSliceVar(&values.CompositeAngles, "composite-angles", nil, "The angles at which the image is sorted separately before the results are composited. Empty means a single sort at the angle. Example: \"0,60,120\".")
 
-	rootCmd.PersistentFlags().IntVarP(&FlagIntervalLengthRandomFactor, "interval-max-length-random-factor", "r", 0, "The value representing the range of values that can be randomly subtracted or added to the max interval length. Options: [>= 0]")
+	flags.StringVar(&values.AngleComposite, "angle-composite", "lighten", "The method used to composite the images sorted at the composite angles. Options: [lighten, darken, average, max-weight].")
 
-	rootCmd.PersistentFlags().IntVarP(&FlagSortCycles, "cycles", "c", 1, "The count of sorting cycles that should be performed on the image.")
+	flags.IntVar(&values.CompositeMemoryBudget, "composite-memory-budget", 0, "The approximate memory in megabytes used to sort the composite angles in parallel. Zero means all angles are sorted in parallel. Options: [>= 0].")
 
-	rootCmd.PersistentFlags().Float64VarP(&FlagImageScale, "scale", "s", 1, "Image downscaling percentage factor. Options: [0.0 - 1.0].")
+	flags.Float64VarP(&values.ImageScale, "scale", "s", 1, "Image downscaling percentage factor. Options: [0.0 - 1.0].")
 
-	rootCmd.PersistentFlags().StringVarP(&FlagBlendingMode, "blending-mode", "b", "none", "The blending mode algorithm to blend the sorted image into the original. Options: [none, lighten, darken].")
+	flags.StringVarP(&values.BlendingMode, "blending-mode", "b", "none", "The blending mode algorithm to blend the sorted image into the original. Options: [none, lighten, darken, multiply, screen, overlay, soft-light, hard-light, difference, exclusion, color-dodge, color-burn, hue, saturation, color, luminosity].")
+
+	flags.Float64Var(&values.BlendingOpacity, "blending-opacity", 1, "The opacity of the sorted image blended into the original. Options: [0.0 - 1.0].")
+
+	flags.StringVar(&values.OpacityImageFilePath, "blending-opacity-image-path", "", "The path of the grayscale image file used as the per-pixel opacity of the sorted image blended into the original.")
 }
 
-// Helper function used to validate and apply flag values into the sorter options struct
-func parseCommonOptions() (*sorter.SorterOptions, error) {
+// Helper function used to apply the logging flag values to the loggers
+func parseLoggingOptions() {
 	if FlagVerboseLogging {
 		Logger.SetLevel(logrus.DebugLevel)
 		Logger.SetReportCaller(true)
@@ -108,18 +327,40 @@ func parseCommonOptions() (*sorter.SorterOptions, error) {
 		LocalLogger = CreateLocalLogger(Logger)
 		SorterLogger = CreateSorterLogger(Logger)
 	}
+}
+
+// Helper function used to apply the logging flag values and validate the input and output media path flag values
+func parseMediaOptions() error {
+	parseLoggingOptions()
 
 	if len(FlagInputMediaFilePath) == 0 {
-		return nil, fmt.Errorf("cmd: invalid input media path specified (%s)", FlagInputMediaFilePath)
+		return fmt.Errorf("cmd: invalid input media path specified (%s)", FlagInputMediaFilePath)
 	}
 
 	if len(FlagOutputMediaFilePath) == 0 {
-		return nil, fmt.Errorf("cmd: invalid output media path specified (%s)", FlagOutputMediaFilePath)
+		return fmt.Errorf("cmd: invalid output media path specified (%s)", FlagOutputMediaFilePath)
+	}
+
+	return nil
+}
+
+// Helper function used to validate and apply the root command flag values into the sorter options struct
+func parseCommonOptions() (*sorter.SorterOptions, error) {
+	if err := parseMediaOptions(); err != nil {
+		return nil, err
 	}
 
+	return parseSorterOptions(rootCmd.PersistentFlags(), rootSorterFlags)
+}
+
+// Helper function used to validate and apply the given flag values into the sorter options struct. The flag set is the set to which the
+// values are bound and is used to check which flags were explicitly specified.
+func parseSorterOptions(flags *pflag.FlagSet, values *sorterFlags) (*sorter.SorterOptions, error) {
+	var err error
+
 	options := sorter.GetDefaultSorterOptions()
 
-	switch strings.ToLower(FlagSortDeterminant) {
+	switch strings.ToLower(values.SortDeterminant) {
 	case "brightness":
 		options.SortDeterminant = sorter.SortByBrightness
 	case "hue":
@@ -134,37 +375,29 @@ func parseCommonOptions() (*sorter.SorterOptions, error) {
 		options.SortDeterminant = sorter.SortByGreenChannel
 	case "blue":
 		options.SortDeterminant = sorter.SortByBlueChannel
+	case "key":
+		{
+			if len(values.KeyImageFilePath) == 0 {
+				return nil, fmt.Errorf("cmd: the key sort determinant requires the key image path to be specified")
+			}
+
+			options.SortDeterminant = sorter.SortByKeyImage
+		}
+	case "noise":
+		options.SortDeterminant = sorter.SortByNoise
 	default:
-		return nil, fmt.Errorf("cmd: invalid sort determinant specified (%s)", FlagSortDeterminant)
+		return nil, fmt.Errorf("cmd: invalid sort determinant specified (%s)", values.SortDeterminant)
 	}
 
-	switch strings.ToLower(FlagSortDirection) {
-	case "ascending":
-		options.SortDirection = sorter.SortAscending
-	case "descending":
-		options.SortDirection = sorter.SortDescending
-	case "shuffle":
-		options.SortDirection = sorter.Shuffle
-	case "random":
-		options.SortDirection = sorter.SortRandom
-	default:
-		return nil, fmt.Errorf("cmd: invalid sort direction specified (%s)", FlagSortDirection)
+	if options.SortDirection, err = parseSortDirection(values.SortDirection); err != nil {
+		return nil, err
 	}
 
-	switch strings.ToLower(FlagSortOrder) {
-	case "horizontal":
-		options.SortOrder = sorter.SortHorizontal
-	case "vertical":
-		options.SortOrder = sorter.SortVertical
-	case "horizontal-vertical":
-		options.SortOrder = sorter.SortHorizontalAndVertical
-	case "vertical-horizontal":
-		options.SortOrder = sorter.SortVerticalAndHorizontal
-	default:
-		return nil, fmt.Errorf("cmd: invalid sort order specified (%s)", FlagSortOrder)
+	if options.SortOrder, err = parseSortOrder(values.SortOrder); err != nil {
+		return nil, err
 	}
 
-	switch strings.ToLower(FlagIntervalDeterminant) {
+	switch strings.ToLower(values.IntervalDeterminant) {
 	case "brightness":
 		options.IntervalDeterminant = sorter.SplitByBrightness
 	case "hue":
@@ -173,8 +406,8 @@ func parseCommonOptions() (*sorter.SorterOptions, error) {
 		options.IntervalDeterminant = sorter.SplitBySaturation
 	case "mask":
 		{
-			if len(FlagMaskImageFilePath) == 0 {
-				LocalLogger.Warnf("The interval determinant is using the mask, but not mask file has been specified.")
+			if !isMaskSpecified(values) {
+				LocalLogger.Warnf("The interval determinant is using the mask, but not mask has been specified.")
 			}
 
 			options.IntervalDeterminant = sorter.SplitByMask
@@ -183,11 +416,120 @@ func parseCommonOptions() (*sorter.SorterOptions, error) {
 		options.IntervalDeterminant = sorter.SplitByAbsoluteColor
 	case "edge":
 		options.IntervalDeterminant = sorter.SplitByEdgeDetection
+	case "map":
+		{
+			if len(values.IntervalMapImageFilePath) == 0 {
+				return nil, fmt.Errorf("cmd: the map interval determinant requires the interval map image path to be specified")
+			}
+
+			options.IntervalDeterminant = sorter.SplitByMap
+		}
+	case "rgb":
+		options.IntervalDeterminant = sorter.SplitByRgbChannels
+	case "difference":
+		options.IntervalDeterminant = sorter.SplitByLocalDifference
+	case "noise":
+		options.IntervalDeterminant = sorter.SplitByNoise
+	case "expression":
+		{
+			if len(values.IntervalExpression) == 0 {
+				return nil, fmt.Errorf("cmd: the expression interval determinant requires the interval expression to be specified")
+			}
+
+			if values.IntervalHysteresis {
+				return nil, fmt.Errorf("cmd: the interval hysteresis is not supported by the expression interval determinant, the stay thresholds can not be applied to the expression clauses")
+			}
+
+			expression, err := sorter.ParseIntervalExpression(values.IntervalExpression)
+			if err != nil {
+				return nil, fmt.Errorf("cmd: invalid interval expression specified (%s): %w", values.IntervalExpression, err)
+			}
+
+			options.IntervalDeterminant = sorter.SplitByExpression
+			options.IntervalExpression = expression
+		}
+	case "clusters":
+		options.IntervalDeterminant = sorter.SplitByClusters
+	default:
+		return nil, fmt.Errorf("cmd: invalid interval determinant specified (%s)", values.IntervalDeterminant)
+	}
+
+	switch strings.ToLower(values.IntervalDifferenceMetric) {
+	case "brightness":
+		options.IntervalDifferenceMetric = sorter.DifferenceBrightness
+	case "oklab":
+		options.IntervalDifferenceMetric = sorter.DifferenceOklab
+	default:
+		return nil, fmt.Errorf("cmd: invalid interval difference metric specified (%s)", values.IntervalDifferenceMetric)
+	}
+
+	switch strings.ToLower(values.IntervalThresholdMode) {
+	case "manual":
+		options.IntervalThresholdSelection = sorter.ThresholdManual
+	case "otsu":
+		options.IntervalThresholdSelection = sorter.ThresholdOtsu
+	case "percentile":
+		options.IntervalThresholdSelection = sorter.ThresholdPercentile
 	default:
-		return nil, fmt.Errorf("cmd: invalid interval determinant specified (%s)", FlagIntervalDeterminant)
+		return nil, fmt.Errorf("cmd: invalid interval threshold mode specified (%s)", values.IntervalThresholdMode)
 	}
 
-	switch strings.ToLower(FlagIntervalPainting) {
+	switch strings.ToLower(values.DirectionScheme) {
+	case "uniform":
+		options.DirectionScheme = sorter.DirectionUniform
+	case "strips":
+		options.DirectionScheme = sorter.DirectionAlternateStrips
+	case "intervals":
+		options.DirectionScheme = sorter.DirectionAlternateIntervals
+	case "noise":
+		options.DirectionScheme = sorter.DirectionByNoise
+	case "map":
+		{
+			if len(values.DirectionMapImageFilePath) == 0 {
+				return nil, fmt.Errorf("cmd: the map direction scheme requires the direction map image path to be specified")
+			}
+
+			options.DirectionScheme = sorter.DirectionByMap
+		}
+	case "brightness":
+		options.DirectionScheme = sorter.DirectionByBrightness
+	default:
+		return nil, fmt.Errorf("cmd: invalid direction scheme specified (%s)", values.DirectionScheme)
+	}
+
+	switch strings.ToLower(values.IntervalLengthSource) {
+	case "fixed":
+		options.IntervalLengthSource = sorter.IntervalLengthFixed
+	case "noise":
+		options.IntervalLengthSource = sorter.IntervalLengthNoise
+	case "map":
+		{
+			if len(values.LengthMapImageFilePath) == 0 {
+				return nil, fmt.Errorf("cmd: the map interval max length source requires the length map image path to be specified")
+			}
+
+			options.IntervalLengthSource = sorter.IntervalLengthMap
+		}
+	default:
+		return nil, fmt.Errorf("cmd: invalid interval max length source specified (%s)", values.IntervalLengthSource)
+	}
+
+	switch strings.ToLower(values.IntervalLengthDistribution) {
+	case "uniform":
+		options.IntervalLengthDistribution = sorter.DistributionUniform
+	case "normal":
+		options.IntervalLengthDistribution = sorter.DistributionNormal
+	case "exponential":
+		options.IntervalLengthDistribution = sorter.DistributionExponential
+	case "lognormal":
+		options.IntervalLengthDistribution = sorter.DistributionLogNormal
+	case "powerlaw":
+		options.IntervalLengthDistribution = sorter.DistributionPowerLaw
+	default:
+		return nil, fmt.Errorf("cmd: invalid interval max length distribution specified (%s)", values.IntervalLengthDistribution)
+	}
+
+	switch strings.ToLower(values.IntervalPainting) {
 	case "fill":
 		options.IntervalPainting = sorter.IntervalFill
 	case "gradient":
@@ -196,34 +538,264 @@ func parseCommonOptions() (*sorter.SorterOptions, error) {
 		options.IntervalPainting = sorter.IntervalRepeat
 	case "average":
 		options.IntervalPainting = sorter.IntervalAverage
+	case "reverse":
+		options.IntervalPainting = sorter.IntervalReverse
+	case "mirror":
+		options.IntervalPainting = sorter.IntervalMirror
+	case "median":
+		options.IntervalPainting = sorter.IntervalMedian
+	case "smear":
+		options.IntervalPainting = sorter.IntervalSmear
+	case "quantize":
+		options.IntervalPainting = sorter.IntervalQuantize
+	default:
+		return nil, fmt.Errorf("cmd: invalid interval painting specified (%s)", values.IntervalPainting)
+	}
+
+	switch strings.ToLower(values.GradientColorSpace) {
+	case "srgb":
+		options.GradientColorSpace = sorter.GradientSrgb
+	case "linear":
+		options.GradientColorSpace = sorter.GradientLinearRgb
+	case "oklab":
+		options.GradientColorSpace = sorter.GradientOklab
+	case "hsl":
+		options.GradientColorSpace = sorter.GradientHsl
+	default:
+		return nil, fmt.Errorf("cmd: invalid gradient color space specified (%s)", values.GradientColorSpace)
+	}
+
+	switch strings.ToLower(values.Dithering) {
+	case "none":
+		options.QuantizeDithering = sorter.DitheringNone
+	case "bayer4":
+		options.QuantizeDithering = sorter.DitheringBayer4x4
+	case "bayer8":
+		options.QuantizeDithering = sorter.DitheringBayer8x8
 	default:
-		return nil, fmt.Errorf("cmd: invalid interval painting specified (%s)", FlagIntervalPainting)
+		return nil, fmt.Errorf("cmd: invalid dithering specified (%s)", values.Dithering)
+	}
+
+	if len(values.PaletteFilePath) > 0 && values.PaletteSize != 0 {
+		return nil, fmt.Errorf("cmd: the palette path and the palette size can not be specified together")
+	}
+
+	if values.PaletteSize < 0 || values.PaletteSize > 256 {
+		return nil, fmt.Errorf("cmd: invalid palette size specified (%d)", values.PaletteSize)
+	}
+
+	if len(values.PaletteFilePath) > 0 {
+		palette, err := utils.GetPaletteFromFile(values.PaletteFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("cmd: failed to load the palette: %w", err)
+		}
+
+		options.QuantizePalette = palette
 	}
 
-	switch FlagBlendingMode {
+	switch values.BlendingMode {
 	case "none":
 		options.Blending = sorter.BlendingNone
 	case "lighten":
 		options.Blending = sorter.BlendingLighten
 	case "darken":
 		options.Blending = sorter.BlendingDarken
+	case "multiply":
+		options.Blending = sorter.BlendingMultiply
+	case "screen":
+		options.Blending = sorter.BlendingScreen
+	case "overlay":
+		options.Blending = sorter.BlendingOverlay
+	case "soft-light":
+		options.Blending = sorter.BlendingSoftLight
+	case "hard-light":
+		options.Blending = sorter.BlendingHardLight
+	case "difference":
+		options.Blending = sorter.BlendingDifference
+	case "exclusion":
+		options.Blending = sorter.BlendingExclusion
+	case "color-dodge":
+		options.Blending = sorter.BlendingColorDodge
+	case "color-burn":
+		options.Blending = sorter.BlendingColorBurn
+	case "hue":
+		options.Blending = sorter.BlendingHue
+	case "saturation":
+		options.Blending = sorter.BlendingSaturation
+	case "color":
+		options.Blending = sorter.BlendingColor
+	case "luminosity":
+		options.Blending = sorter.BlendingLuminosity
+	default:
+		return nil, fmt.Errorf("cmd: invalid blending mode specified (%s)", values.BlendingMode)
+	}
+
+	if values.BlendingOpacity < 0.0 || values.BlendingOpacity > 1.0 {
+		return nil, fmt.Errorf("cmd: the blending opacity must be between 0 and 1")
+	}
+
+	options.BlendingOpacity = values.BlendingOpacity
+
+	switch strings.ToLower(values.ChannelSplit) {
+	case "none":
+		options.ChannelSplit = sorter.ChannelSplitNone
+	case "rgb":
+		options.ChannelSplit = sorter.ChannelSplitRgb
+	case "rgba":
+		options.ChannelSplit = sorter.ChannelSplitRgba
 	default:
-		return nil, fmt.Errorf("cmd: invalid blending mode specified (%s)", FlagBlendingMode)
+		return nil, fmt.Errorf("cmd: invalid channel split specified (%s)", values.ChannelSplit)
 	}
 
-	options.IntervalDeterminantUpperThreshold = FlagIntervalUpperThreshold
-	options.IntervalDeterminantLowerThreshold = FlagIntervalLowerThreshold
-	options.IntervalLength = FlagIntervalLength
-	options.IntervalLengthRandomFactor = FlagIntervalLengthRandomFactor
-	options.Angle = FlagAngle
-	options.Cycles = FlagSortCycles
-	options.Scale = FlagImageScale
+	switch strings.ToLower(values.ChannelSplitDeterminant) {
+	case "own":
+		options.ChannelSplitDeterminant = sorter.ChannelSortByOwnValue
+	case "shared":
+		options.ChannelSplitDeterminant = sorter.ChannelSortBySharedDeterminant
+	default:
+		return nil, fmt.Errorf("cmd: invalid channel split determinant specified (%s)", values.ChannelSplitDeterminant)
+	}
+
+	if len(values.ChannelLowerThresholds) > len(options.ChannelOptions) {
+		return nil, fmt.Errorf("cmd: too many channel lower thresholds specified (%d)", len(values.ChannelLowerThresholds))
+	}
+
+	if len(values.ChannelUpperThresholds) > len(options.ChannelOptions) {
+		return nil, fmt.Errorf("cmd: too many channel upper thresholds specified (%d)", len(values.ChannelUpperThresholds))
+	}
+
+	if len(values.ChannelAngleOffsets) > len(options.ChannelOptions) {
+		return nil, fmt.Errorf("cmd: too many channel angle offsets specified (%d)", len(values.ChannelAngleOffsets))
+	}
+
+	for channel := range options.ChannelOptions {
+		options.ChannelOptions[channel].LowerThreshold = values.IntervalLowerThreshold
+		if channel < len(values.ChannelLowerThresholds) {
+			options.ChannelOptions[channel].LowerThreshold = values.ChannelLowerThresholds[channel]
+		}
+
+		options.ChannelOptions[channel].UpperThreshold = values.IntervalUpperThreshold
+		if channel < len(values.ChannelUpperThresholds) {
+			options.ChannelOptions[channel].UpperThreshold = values.ChannelUpperThresholds[channel]
+		}
 
-	if FlagMask && len(FlagMaskImageFilePath) == 0 {
-		LocalLogger.Warnf("The mask flag is set, but not mask file has been specified.")
+		if channel < len(values.ChannelAngleOffsets) {
+			options.ChannelOptions[channel].AngleOffset = values.ChannelAngleOffsets[channel]
+		}
 	}
 
-	options.UseMask = FlagMask
+	options.DirectionBrightnessThreshold = values.DirectionBrightness
+	if values.SortStrength < 0.0 || values.SortStrength > 1.0 {
+		return nil, fmt.Errorf("cmd: the sort strength must be between 0 and 1")
+	}
+
+	options.SortStrength = values.SortStrength
+	options.GradientStops = values.GradientStops
+	options.QuantizeLevels = values.QuantizeLevels
+	options.IntervalDeterminantUpperThreshold = values.IntervalUpperThreshold
+	options.IntervalDeterminantLowerThreshold = values.IntervalLowerThreshold
+	options.IntervalDeterminantWrapAround = values.IntervalHueWrapAround
+	options.IntervalChannelThresholds = sorter.ChannelThresholds{
+		RedLower:   values.IntervalRedLowerThreshold,
+		RedUpper:   values.IntervalRedUpperThreshold,
+		GreenLower: values.IntervalGreenLowerThreshold,
+		GreenUpper: values.IntervalGreenUpperThreshold,
+		BlueLower:  values.IntervalBlueLowerThreshold,
+		BlueUpper:  values.IntervalBlueUpperThreshold,
+	}
+	options.IntervalHysteresis = values.IntervalHysteresis
+	options.IntervalStayLowerThreshold = values.IntervalStayLowerThreshold
+	options.IntervalStayUpperThreshold = values.IntervalStayUpperThreshold
+	options.IntervalGapTolerance = values.IntervalGapTolerance
+	options.IntervalMinLength = values.IntervalMinLength
+	options.IntervalDifferenceThreshold = values.IntervalDifferenceThreshold
+	options.IntervalLowerPercentile = values.IntervalLowerPercentile
+	options.IntervalUpperPercentile = values.IntervalUpperPercentile
+	options.IntervalLength = values.IntervalLength
+	options.IntervalLengthRandomFactor = values.IntervalLengthRandomFactor
+	options.IntervalLengthDeviation = values.IntervalLengthDeviation
+	options.IntervalLengthExponent = values.IntervalLengthExponent
+	options.NoiseSeed = values.NoiseSeed
+	options.NoiseScale = values.NoiseScale
+	options.NoiseOctaves = values.NoiseOctaves
+	options.NoisePersistence = values.NoisePersistence
+	options.ClusterCount = values.ClusterCount
+	options.ClusterSeed = values.ClusterSeed
+	options.ClusterIndices = values.ClusterIndices
+	options.Angle = values.Angle
+	options.Cycles = values.SortCycles
+
+	// NOTE: The random decisions are only reproducible if the seed is explicitly specified
+	if flags.Changed("seed") {
+		options.Seed = &values.Seed
+	}
+
+	schedules := []struct {
+		text     string
+		name     string
+		schedule *sorter.ParameterSchedule
+	}{
+		{values.ScheduleAngle, "angle", &options.CycleSchedule.Angle},
+		{values.ScheduleLowerThreshold, "lower threshold", &options.CycleSchedule.LowerThreshold},
+		{values.ScheduleUpperThreshold, "upper threshold", &options.CycleSchedule.UpperThreshold},
+		{values.ScheduleIntervalLength, "interval max length", &options.CycleSchedule.IntervalLength},
+	}
+
+	for _, schedule := range schedules {
+		if *schedule.schedule, err = sorter.ParseParameterSchedule(schedule.text); err != nil {
+			return nil, fmt.Errorf("cmd: invalid %s schedule specified: %w", schedule.name, err)
+		}
+	}
+
+	for _, order := range values.ScheduleOrders {
+		sortOrder, err := parseSortOrder(order)
+		if err != nil {
+			return nil, err
+		}
+
+		options.CycleSchedule.SortOrder = append(options.CycleSchedule.SortOrder, sortOrder)
+	}
+
+	for _, direction := range values.ScheduleDirections {
+		sortDirection, err := parseSortDirection(direction)
+		if err != nil {
+			return nil, err
+		}
+
+		options.CycleSchedule.SortDirection = append(options.CycleSchedule.SortDirection, sortDirection)
+	}
+
+	options.CompositeAngles = values.CompositeAngles
+	options.CompositeMemoryBudget = values.CompositeMemoryBudget
+
+	switch strings.ToLower(values.AngleComposite) {
+	case "lighten":
+		options.AngleComposite = sorter.AngleCompositeLighten
+	case "darken":
+		options.AngleComposite = sorter.AngleCompositeDarken
+	case "average":
+		options.AngleComposite = sorter.AngleCompositeAverage
+	case "max-weight":
+		options.AngleComposite = sorter.AngleCompositeMaxWeight
+	default:
+		return nil, fmt.Errorf("cmd: invalid angle composite specified (%s)", values.AngleComposite)
+	}
+
+	options.Scale = values.ImageScale
+
+	if values.Mask && !isMaskSpecified(values) {
+		LocalLogger.Warnf("The mask flag is set, but not mask has been specified.")
+	}
+
+	options.UseMask = values.Mask
+
+	if values.MaskThreshold < 0 || values.MaskThreshold > 255 {
+		return nil, fmt.Errorf("cmd: the mask threshold must be between values 0 and 255")
+	}
+
+	options.MaskThreshold = uint8(values.MaskThreshold)
+	options.MaskFeatherRadius = values.MaskFeatherRadius
+	options.SoftMask = values.SoftMask
 
 	if valid, msg := options.AreValid(); !valid {
 		return nil, fmt.Errorf("cmd: %s", msg)
@@ -232,6 +804,183 @@ func parseCommonOptions() (*sorter.SorterOptions, error) {
 	return options, nil
 }
 
+// Helper function used to load the mask and auxiliary images specified by the flags and to apply the options which are depending on the
+// input image, such as the median cut palette and the cluster map output.
+func loadSorterInputs(img image.Image, values *sorterFlags, options *sorter.SorterOptions) (image.Image, *sorter.AuxiliaryImages, error) {
+	var err error
+
+	if values.PaletteSize > 0 {
+		options.QuantizePalette, err = utils.CreateMedianCutPalette(img, values.PaletteSize)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	maskImage, err := loadMaskImage(img, values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	auxiliary := new(sorter.AuxiliaryImages)
+	if len(values.KeyImageFilePath) > 0 {
+		auxiliary.KeyImage, err = utils.GetImageFromFile(values.KeyImageFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(values.IntervalMapImageFilePath) > 0 {
+		auxiliary.IntervalMapImage, err = utils.GetImageFromFile(values.IntervalMapImageFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(values.LengthMapImageFilePath) > 0 {
+		auxiliary.IntervalLengthMapImage, err = utils.GetImageFromFile(values.LengthMapImageFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(values.DirectionMapImageFilePath) > 0 {
+		auxiliary.DirectionMapImage, err = utils.GetImageFromFile(values.DirectionMapImageFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(values.OpacityImageFilePath) > 0 {
+		auxiliary.BlendingOpacityImage, err = utils.GetImageFromFile(values.OpacityImageFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(values.ClusterMapOutputFilePath) > 0 {
+		if _, ok := determineFileExtension(values.ClusterMapOutputFilePath, []string{"png"}); !ok {
+			return nil, nil, fmt.Errorf("cmd: invalid cluster map output image file format specified (%s)", values.ClusterMapOutputFilePath)
+		}
+
+		clusterMap, err := sorter.CreateClusterLabelImage(img, options)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := utils.StoreImageToFile(values.ClusterMapOutputFilePath, "png", clusterMap); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return maskImage, auxiliary, nil
+}
+
+// Helper function used to determine if the mask flags are specifying a mask source
+func isMaskSpecified(values *sorterFlags) bool {
+	return len(values.MaskImageFilePath) > 0 || !strings.EqualFold(values.MaskSource, "image")
+}
+
+// Helper function used to create the mask image from the mask source specified by the flags. A nil image is returned if no mask is specified.
+func loadMaskImage(img image.Image, values *sorterFlags) (image.Image, error) {
+	var maskImage *image.Gray
+
+	switch strings.ToLower(values.MaskSource) {
+	case "image":
+		{
+			if len(values.MaskImageFilePath) == 0 {
+				return nil, nil
+			}
+
+			channel, err := parseMaskChannel(values.MaskChannel)
+			if err != nil {
+				return nil, err
+			}
+
+			file, err := utils.GetImageFromFile(values.MaskImageFilePath)
+			if err != nil {
+				return nil, err
+			}
+
+			if maskImage, err = mask.FromChannel(file, channel); err != nil {
+				return nil, err
+			}
+		}
+	case "alpha":
+		maskImage = mask.FromAlpha(img)
+	case "color-key":
+		{
+			key, err := utils.ParseHexColor(values.MaskKeyColor)
+			if err != nil {
+				return nil, err
+			}
+
+			if maskImage, err = mask.FromColorKey(img, key, values.MaskKeyTolerance); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("cmd: invalid mask source specified (%s)", values.MaskSource)
+	}
+
+	if values.MaskInvert {
+		maskImage = mask.Invert(maskImage)
+	}
+
+	return maskImage, nil
+}
+
+// Helper function used to parse the mask channel flag value
+func parseMaskChannel(value string) (mask.Channel, error) {
+	switch strings.ToLower(value) {
+	case "gray":
+		return mask.ChannelGray, nil
+	case "luminance":
+		return mask.ChannelLuminance, nil
+	case "red":
+		return mask.ChannelRed, nil
+	case "green":
+		return mask.ChannelGreen, nil
+	case "blue":
+		return mask.ChannelBlue, nil
+	case "alpha":
+		return mask.ChannelAlpha, nil
+	default:
+		return 0, fmt.Errorf("cmd: invalid mask channel specified (%s)", value)
+	}
+}
+
+// Helper function used to parse the sort direction flag value
+func parseSortDirection(value string) (sorter.SortDirection, error) {
+	switch strings.ToLower(value) {
+	case "ascending":
+		return sorter.SortAscending, nil
+	case "descending":
+		return sorter.SortDescending, nil
+	case "shuffle":
+		return sorter.Shuffle, nil
+	case "random":
+		return sorter.SortRandom, nil
+	default:
+		return 0, fmt.Errorf("cmd: invalid sort direction specified (%s)", value)
+	}
+}
+
+// Helper function used to parse the sort order flag value
+func parseSortOrder(value string) (sorter.SortOrder, error) {
+	switch strings.ToLower(value) {
+	case "horizontal":
+		return sorter.SortHorizontal, nil
+	case "vertical":
+		return sorter.SortVertical, nil
+	case "horizontal-vertical":
+		return sorter.SortHorizontalAndVertical, nil
+	case "vertical-horizontal":
+		return sorter.SortVerticalAndHorizontal, nil
+	default:
+		return 0, fmt.Errorf("cmd: invalid sort order specified (%s)", value)
+	}
+}
+
 // Helper function used to determine if the current path file extension matches the possible extension collection.
 func determineFileExtension(path string, extensions []string) (string, bool) {
 	path, err := utils.EscapePathQuotes(path)
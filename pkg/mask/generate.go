@@ -0,0 +1,214 @@
+package mask
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
+)
+
+// Flag representing the pattern of the generated mask
+type Pattern int
+
+const (
+	PatternLinearGradient Pattern = iota
+	PatternRadialGradient
+	PatternStripes
+	PatternCheckerboard
+	PatternRings
+	PatternRectangles
+	PatternNoise
+)
+
+// Structure representing the parameters of the generated mask. The angle is rotating the linear gradient, stripes and checkerboard
+// patterns. The size is the width in pixels of the stripes, checkerboard fields and rings, the max size of the rectangles and the size of
+// the noise features. The threshold is used to turn the noise into a binary mask. The seed is used by the rectangles and noise patterns.
+type GeneratorOptions struct {
+	Pattern          Pattern
+	Angle            int
+	Size             float64
+	Count            int
+	Threshold        float64
+	Seed             int64
+	NoiseOctaves     int
+	NoisePersistence float64
+}
+
+// Get a GeneratorOptions structure instance with default values
+func GetDefaultGeneratorOptions() *GeneratorOptions {
+	options := new(GeneratorOptions)
+	options.Pattern = PatternLinearGradient
+	options.Angle = 0
+	options.Size = 64
+	options.Count = 16
+	options.Threshold = 0.5
+	options.Seed = 0
+	options.NoiseOctaves = 4
+	options.NoisePersistence = 0.5
+
+	return options
+}
+
+// Return a boolean value indicating if the given generator options combination is valid
+// and a string containing validation failure message if the options came out to be invalid
+func (options *GeneratorOptions) AreValid() (bool, string) {
+	if options.Pattern < PatternLinearGradient || options.Pattern > PatternNoise {
+		return false, "invalid mask pattern specified"
+	}
+
+	if options.Size <= 0.0 {
+		return false, "the pattern size must be greater than zero"
+	}
+
+	if options.Count < 0 {
+		return false, "the pattern count must not be negative"
+	}
+
+	if options.Threshold < 0.0 || options.Threshold > 1.0 {
+		return false, "the pattern threshold must be between values 0 and 1"
+	}
+
+	if options.NoiseOctaves < 1 || options.NoiseOctaves > 16 {
+		return false, "the noise octaves count must be between values 1 and 16"
+	}
+
+	if options.NoisePersistence <= 0.0 || options.NoisePersistence > 1.0 {
+		return false, "the noise persistence must be greater than 0 and not greater than 1"
+	}
+
+	return true, ""
+}
+
+// Generate a mask of the given size with the pattern described by the options. The default options are used if the options are nil.
+// The masks generated with the same options are identical.
+func Generate(width, height int, options *GeneratorOptions) (*image.Gray, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("mask: the generated mask size must be greater than zero")
+	}
+
+	if options == nil {
+		options = GetDefaultGeneratorOptions()
+	}
+
+	if valid, msg := options.AreValid(); !valid {
+		return nil, fmt.Errorf("mask: %s", msg)
+	}
+
+	mask := image.NewGray(image.Rect(0, 0, width, height))
+	if options.Pattern == PatternRectangles {
+		generateRectangles(mask, options)
+		return mask, nil
+	}
+
+	value := createPatternFunction(width, height, options)
+
+	wg := &sync.WaitGroup{}
+	for y := 0; y < height; y += 1 {
+		wg.Add(1)
+		go func(yIndex int) {
+			defer wg.Done()
+
+			for x := 0; x < width; x += 1 {
+				mask.Pix[yIndex*mask.Stride+x] = value(float64(x)+0.5, float64(yIndex)+0.5)
+			}
+		}(y)
+	}
+
+	wg.Wait()
+	return mask, nil
+}
+
+// Helper function used to create the function calculating the mask value at the given pixel center coordinates for the given pattern.
+// The coordinates are rotated around the mask center by the pattern angle.
+func createPatternFunction(width, height int, options *GeneratorOptions) func(x, y float64) uint8 {
+	var (
+		xCenter  float64 = float64(width) / 2.0
+		yCenter  float64 = float64(height) / 2.0
+		sin, cos float64 = math.Sincos(math.Pi * float64(options.Angle) / 180.0)
+		size     float64 = options.Size
+	)
+
+	rotate := func(x, y float64) (float64, float64) {
+		dx, dy := x-xCenter, y-yCenter
+		return dx*cos + dy*sin, -dx*sin + dy*cos
+	}
+
+	binary := func(white bool) uint8 {
+		if white {
+			return 0xff
+		}
+
+		return 0x00
+	}
+
+	switch options.Pattern {
+	case PatternLinearGradient:
+		{
+			// NOTE: The half of the image extent along the gradient direction, so the gradient spans exactly the whole image
+			extent := (math.Abs(cos)*float64(width) + math.Abs(sin)*float64(height)) / 2.0
+
+			return func(x, y float64) uint8 {
+				u, _ := rotate(x, y)
+				return uint8(utils.ClampInt(0, int(math.Round((u+extent)/(2.0*extent)*255.0)), 255))
+			}
+		}
+	case PatternRadialGradient:
+		{
+			radius := math.Hypot(xCenter, yCenter)
+
+			return func(x, y float64) uint8 {
+				distance := math.Hypot(x-xCenter, y-yCenter)
+				return uint8(utils.ClampInt(0, int(math.Round((1.0-distance/radius)*255.0)), 255))
+			}
+		}
+	case PatternStripes:
+		return func(x, y float64) uint8 {
+			u, _ := rotate(x, y)
+			return binary(int(math.Floor(u/size))%2 == 0)
+		}
+	case PatternCheckerboard:
+		return func(x, y float64) uint8 {
+			u, v := rotate(x, y)
+			return binary((int(math.Floor(u/size))+int(math.Floor(v/size)))%2 == 0)
+		}
+	case PatternRings:
+		return func(x, y float64) uint8 {
+			return binary(int(math.Floor(math.Hypot(x-xCenter, y-yCenter)/size))%2 == 0)
+		}
+	case PatternNoise:
+		{
+			noise := utils.CreatePerlinNoise(options.Seed)
+
+			return func(x, y float64) uint8 {
+				return binary(noise.FractalNoise(x/size, y/size, options.NoiseOctaves, options.NoisePersistence) >= options.Threshold)
+			}
+		}
+	default:
+		panic("mask: invalid mask pattern specified")
+	}
+}
+
+// Helper function used to draw the given count of white rectangles with random positions and sizes not greater than the pattern size
+func generateRectangles(mask *image.Gray, options *GeneratorOptions) {
+	var (
+		random  *rand.Rand = rand.New(rand.NewSource(options.Seed))
+		width   int        = mask.Bounds().Dx()
+		height  int        = mask.Bounds().Dy()
+		maxSize int        = max(1, int(options.Size))
+	)
+
+	for index := 0; index < options.Count; index += 1 {
+		rectangle := image.Rect(0, 0, 1+random.Intn(maxSize), 1+random.Intn(maxSize))
+		rectangle = rectangle.Add(image.Pt(random.Intn(width), random.Intn(height))).Intersect(mask.Bounds())
+
+		for y := rectangle.Min.Y; y < rectangle.Max.Y; y += 1 {
+			for x := rectangle.Min.X; x < rectangle.Max.X; x += 1 {
+				mask.Pix[y*mask.Stride+x] = 0xff
+			}
+		}
+	}
+}
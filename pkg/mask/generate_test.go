@@ -0,0 +1,141 @@
+package mask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+func createTestGeneratorOptions(pattern Pattern) *GeneratorOptions {
+	options := GetDefaultGeneratorOptions()
+	options.Pattern = pattern
+
+	return options
+}
+
+func TestGenerateShouldCreateMaskOfTheGivenSize(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	for pattern := PatternLinearGradient; pattern <= PatternNoise; pattern += 1 {
+		mask, err := Generate(30, 20, createTestGeneratorOptions(pattern))
+
+		assert.Nil(t, err)
+		assert.Equal(t, 30, mask.Bounds().Dx())
+		assert.Equal(t, 20, mask.Bounds().Dy())
+	}
+}
+
+func TestGenerateShouldReturnErrorForInvalidParameters(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	mask, err := Generate(0, 10, nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, mask)
+
+	invalidOptions := []func(o *GeneratorOptions){
+		func(o *GeneratorOptions) { o.Pattern = Pattern(-1) },
+		func(o *GeneratorOptions) { o.Size = 0 },
+		func(o *GeneratorOptions) { o.Count = -1 },
+		func(o *GeneratorOptions) { o.Threshold = 1.1 },
+		func(o *GeneratorOptions) { o.NoiseOctaves = 0 },
+		func(o *GeneratorOptions) { o.NoisePersistence = 0 },
+	}
+
+	for _, modify := range invalidOptions {
+		options := GetDefaultGeneratorOptions()
+		modify(options)
+
+		mask, err := Generate(10, 10, options)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, mask)
+	}
+}
+
+func TestGenerateShouldCreateLinearGradient(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	mask, err := Generate(100, 10, createTestGeneratorOptions(PatternLinearGradient))
+
+	assert.Nil(t, err)
+	assert.Less(t, mask.GrayAt(0, 5).Y, uint8(5))
+	assert.Greater(t, mask.GrayAt(99, 5).Y, uint8(250))
+	assert.Equal(t, mask.GrayAt(50, 0).Y, mask.GrayAt(50, 9).Y)
+
+	options := createTestGeneratorOptions(PatternLinearGradient)
+	options.Angle = 90
+
+	mask, err = Generate(10, 100, options)
+
+	assert.Nil(t, err)
+	assert.Less(t, mask.GrayAt(5, 0).Y, uint8(5))
+	assert.Greater(t, mask.GrayAt(5, 99).Y, uint8(250))
+}
+
+func TestGenerateShouldCreateRadialGradient(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	mask, err := Generate(100, 100, createTestGeneratorOptions(PatternRadialGradient))
+
+	assert.Nil(t, err)
+	assert.Greater(t, mask.GrayAt(50, 50).Y, uint8(250))
+	assert.Less(t, mask.GrayAt(0, 0).Y, uint8(5))
+	assert.Greater(t, mask.GrayAt(50, 50).Y, mask.GrayAt(75, 50).Y)
+}
+
+func TestGenerateShouldCreateStripesCheckerboardAndRings(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := createTestGeneratorOptions(PatternStripes)
+	options.Size = 10
+
+	stripes, err := Generate(40, 40, options)
+
+	assert.Nil(t, err)
+	assert.NotEqual(t, stripes.GrayAt(5, 0).Y, stripes.GrayAt(15, 0).Y)
+	assert.Equal(t, stripes.GrayAt(5, 0).Y, stripes.GrayAt(5, 39).Y)
+	assert.Equal(t, stripes.GrayAt(5, 0).Y, stripes.GrayAt(25, 0).Y)
+
+	options.Pattern = PatternCheckerboard
+	checkerboard, err := Generate(40, 40, options)
+
+	assert.Nil(t, err)
+	assert.NotEqual(t, checkerboard.GrayAt(5, 5).Y, checkerboard.GrayAt(15, 5).Y)
+	assert.NotEqual(t, checkerboard.GrayAt(5, 5).Y, checkerboard.GrayAt(5, 15).Y)
+	assert.Equal(t, checkerboard.GrayAt(5, 5).Y, checkerboard.GrayAt(15, 15).Y)
+
+	options.Pattern = PatternRings
+	rings, err := Generate(40, 40, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(255), rings.GrayAt(20, 20).Y)
+	assert.Equal(t, uint8(0), rings.GrayAt(35, 20).Y)
+	assert.Equal(t, rings.GrayAt(35, 20).Y, rings.GrayAt(20, 35).Y)
+}
+
+func TestGenerateShouldCreateSeededRandomPatterns(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	for _, pattern := range []Pattern{PatternRectangles, PatternNoise} {
+		options := createTestGeneratorOptions(pattern)
+		options.Size = 8
+
+		a, err := Generate(64, 64, options)
+		assert.Nil(t, err)
+
+		b, err := Generate(64, 64, options)
+		assert.Nil(t, err)
+
+		options.Seed = 1
+		c, err := Generate(64, 64, options)
+		assert.Nil(t, err)
+
+		assert.Equal(t, a.Pix, b.Pix)
+		assert.NotEqual(t, a.Pix, c.Pix)
+
+		for _, value := range a.Pix {
+			assert.True(t, value == 0 || value == 255)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package mask
+
+import (
+	"errors"
+	"image"
+
+	"github.com/Krzysztofz01/imaging"
+)
+
+// Flag representing the boolean operator used to combine masks. The operators are applied to the grayscale values, so for the binary masks
+// the white (not masked out) value is the logical true.
+type Operator int
+
+const (
+	OperatorAnd Operator = iota
+	OperatorOr
+	OperatorXor
+	OperatorSubtract
+)
+
+// Create a dilated copy of the mask, where the white (not masked out) areas are grown by the given radius in pixels. The square
+// structuring element is used.
+func Dilate(mask *image.Gray, radius int) (*image.Gray, error) {
+	if radius < 0 {
+		return nil, errors.New("mask: the dilation radius must not be negative")
+	}
+
+	return morphologyFilter(mask, radius, func(a, b uint8) uint8 { return max(a, b) }), nil
+}
+
+// Create a eroded copy of the mask, where the black (masked out) areas are grown by the given radius in pixels. The square
+// structuring element is used.
+func Erode(mask *image.Gray, radius int) (*image.Gray, error) {
+	if radius < 0 {
+		return nil, errors.New("mask: the erosion radius must not be negative")
+	}
+
+	return morphologyFilter(mask, radius, func(a, b uint8) uint8 { return min(a, b) }), nil
+}
+
+// Create a opened copy of the mask (erosion followed by dilation), which removes the white areas smaller than the given radius
+func Open(mask *image.Gray, radius int) (*image.Gray, error) {
+	eroded, err := Erode(mask, radius)
+	if err != nil {
+		return nil, err
+	}
+
+	return Dilate(eroded, radius)
+}
+
+// Create a closed copy of the mask (dilation followed by erosion), which removes the black areas smaller than the given radius
+func Close(mask *image.Gray, radius int) (*image.Gray, error) {
+	dilated, err := Dilate(mask, radius)
+	if err != nil {
+		return nil, err
+	}
+
+	return Erode(dilated, radius)
+}
+
+// Create a blurred copy of the mask using the gaussian blur with the given sigma
+func Blur(mask *image.Gray, sigma float64) (*image.Gray, error) {
+	if mask == nil {
+		panic("mask: the provided mask reference is nil")
+	}
+
+	if sigma < 0.0 {
+		return nil, errors.New("mask: the blur sigma must not be negative")
+	}
+
+	blurred := imaging.Blur(mask, sigma)
+
+	result := image.NewGray(image.Rect(0, 0, blurred.Bounds().Dx(), blurred.Bounds().Dy()))
+	for index := 0; index < len(result.Pix); index += 1 {
+		result.Pix[index] = blurred.Pix[index*4]
+	}
+
+	return result, nil
+}
+
+// Create a binary copy of the mask, where the values below the threshold are black (masked out) and the other values are white
+func Threshold(mask *image.Gray, threshold uint8) *image.Gray {
+	return mapMask(mask, func(v uint8) uint8 {
+		if v < threshold {
+			return 0x00
+		}
+
+		return 0xff
+	})
+}
+
+// Create a mask by combining the two masks of the same size using the given operator. The and and or operators are the minimum and
+// the maximum of the values, the xor operator is the absolute difference and the subtract operator is the saturated difference.
+func Combine(a, b *image.Gray, operator Operator) (*image.Gray, error) {
+	if a == nil || b == nil {
+		panic("mask: the provided mask reference is nil")
+	}
+
+	if a.Bounds().Dx() != b.Bounds().Dx() || a.Bounds().Dy() != b.Bounds().Dy() {
+		return nil, errors.New("mask: the combined masks must have the same size")
+	}
+
+	var combine func(a, b uint8) uint8
+	switch operator {
+	case OperatorAnd:
+		combine = func(a, b uint8) uint8 { return min(a, b) }
+	case OperatorOr:
+		combine = func(a, b uint8) uint8 { return max(a, b) }
+	case OperatorXor:
+		combine = func(a, b uint8) uint8 { return max(a, b) - min(a, b) }
+	case OperatorSubtract:
+		combine = func(a, b uint8) uint8 {
+			if b > a {
+				return 0x00
+			}
+
+			return a - b
+		}
+	default:
+		return nil, errors.New("mask: invalid mask combine operator specified")
+	}
+
+	var (
+		width  int         = a.Bounds().Dx()
+		height int         = a.Bounds().Dy()
+		result *image.Gray = image.NewGray(image.Rect(0, 0, width, height))
+	)
+
+	for y := 0; y < height; y += 1 {
+		for x := 0; x < width; x += 1 {
+			aValue := a.GrayAt(a.Bounds().Min.X+x, a.Bounds().Min.Y+y).Y
+			bValue := b.GrayAt(b.Bounds().Min.X+x, b.Bounds().Min.Y+y).Y
+
+			result.Pix[y*result.Stride+x] = combine(aValue, bValue)
+		}
+	}
+
+	return result, nil
+}
+
+// Helper function used to create a copy of the mask with the mapping function applied to each value
+func mapMask(mask *image.Gray, mapping func(v uint8) uint8) *image.Gray {
+	if mask == nil {
+		panic("mask: the provided mask reference is nil")
+	}
+
+	result := image.NewGray(image.Rect(0, 0, mask.Bounds().Dx(), mask.Bounds().Dy()))
+	for y := 0; y < result.Bounds().Dy(); y += 1 {
+		for x := 0; x < result.Bounds().Dx(); x += 1 {
+			result.Pix[y*result.Stride+x] = mapping(mask.GrayAt(mask.Bounds().Min.X+x, mask.Bounds().Min.Y+y).Y)
+		}
+	}
+
+	return result
+}
+
+// Helper function used to apply the separable (horizontal and vertical pass) square morphology filter of the given radius, where the
+// reduce function is picking the value from the values in the neighbourhood
+func morphologyFilter(mask *image.Gray, radius int, reduce func(a, b uint8) uint8) *image.Gray {
+	var (
+		source     *image.Gray = mapMask(mask, func(v uint8) uint8 { return v })
+		horizontal *image.Gray = image.NewGray(source.Rect)
+		vertical   *image.Gray = image.NewGray(source.Rect)
+		width      int         = source.Rect.Dx()
+		height     int         = source.Rect.Dy()
+	)
+
+	if radius == 0 {
+		return source
+	}
+
+	for y := 0; y < height; y += 1 {
+		for x := 0; x < width; x += 1 {
+			value := source.Pix[y*source.Stride+x]
+			for xOffset := max(0, x-radius); xOffset <= min(width-1, x+radius); xOffset += 1 {
+				value = reduce(value, source.Pix[y*source.Stride+xOffset])
+			}
+
+			horizontal.Pix[y*horizontal.Stride+x] = value
+		}
+	}
+
+	for y := 0; y < height; y += 1 {
+		for x := 0; x < width; x += 1 {
+			value := horizontal.Pix[y*horizontal.Stride+x]
+			for yOffset := max(0, y-radius); yOffset <= min(height-1, y+radius); yOffset += 1 {
+				value = reduce(value, horizontal.Pix[yOffset*horizontal.Stride+x])
+			}
+
+			vertical.Pix[y*vertical.Stride+x] = value
+		}
+	}
+
+	return vertical
+}
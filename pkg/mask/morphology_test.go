@@ -0,0 +1,122 @@
+package mask
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestMask(width, height int, pix []uint8) *image.Gray {
+	mask := image.NewGray(image.Rect(0, 0, width, height))
+	copy(mask.Pix, pix)
+
+	return mask
+}
+
+func TestDilateShouldGrowTheWhiteAreas(t *testing.T) {
+	mask := createTestMask(5, 1, []uint8{0, 0, 255, 0, 0})
+
+	dilated, err := Dilate(mask, 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []uint8{0, 255, 255, 255, 0}, dilated.Pix)
+	assert.Equal(t, []uint8{0, 0, 255, 0, 0}, mask.Pix)
+}
+
+func TestErodeShouldGrowTheBlackAreas(t *testing.T) {
+	mask := createTestMask(3, 3, []uint8{
+		255, 255, 255,
+		255, 0, 255,
+		255, 255, 255,
+	})
+
+	eroded, err := Erode(mask, 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, make([]uint8, 9), eroded.Pix)
+}
+
+func TestMorphologyShouldReturnErrorForNegativeRadius(t *testing.T) {
+	mask := createTestMask(1, 1, []uint8{0})
+
+	for _, operation := range []func(*image.Gray, int) (*image.Gray, error){Dilate, Erode, Open, Close} {
+		result, err := operation(mask, -1)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, result)
+	}
+}
+
+func TestOpenShouldRemoveSmallWhiteAreas(t *testing.T) {
+	mask := createTestMask(7, 1, []uint8{255, 0, 0, 255, 255, 255, 255})
+
+	opened, err := Open(mask, 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []uint8{0, 0, 0, 255, 255, 255, 255}, opened.Pix)
+}
+
+func TestCloseShouldRemoveSmallBlackAreas(t *testing.T) {
+	mask := createTestMask(7, 1, []uint8{0, 0, 0, 255, 0, 255, 255})
+
+	closed, err := Close(mask, 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []uint8{0, 0, 0, 255, 255, 255, 255}, closed.Pix)
+}
+
+func TestBlurShouldSmoothTheMaskEdges(t *testing.T) {
+	mask := createTestMask(14, 1, []uint8{0, 0, 0, 0, 0, 0, 0, 255, 255, 255, 255, 255, 255, 255})
+
+	blurred, err := Blur(mask, 1.0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(0), blurred.Pix[0])
+	assert.Equal(t, uint8(255), blurred.Pix[13])
+	assert.Less(t, blurred.Pix[6], blurred.Pix[7])
+	assert.Greater(t, blurred.Pix[6], uint8(0))
+	assert.Less(t, blurred.Pix[7], uint8(255))
+
+	blurred, err = Blur(mask, -1)
+
+	assert.NotNil(t, err)
+	assert.Nil(t, blurred)
+}
+
+func TestThresholdShouldCreateBinaryMask(t *testing.T) {
+	mask := createTestMask(4, 1, []uint8{0, 126, 127, 200})
+
+	assert.Equal(t, []uint8{0, 0, 255, 255}, Threshold(mask, 127).Pix)
+}
+
+func TestCombineShouldCombineTheMasks(t *testing.T) {
+	a := createTestMask(4, 1, []uint8{0, 0, 255, 255})
+	b := createTestMask(4, 1, []uint8{0, 255, 0, 255})
+
+	cases := map[Operator][]uint8{
+		OperatorAnd:      {0, 0, 0, 255},
+		OperatorOr:       {0, 255, 255, 255},
+		OperatorXor:      {0, 255, 255, 0},
+		OperatorSubtract: {0, 0, 255, 0},
+	}
+
+	for operator, expected := range cases {
+		combined, err := Combine(a, b, operator)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, combined.Pix)
+	}
+}
+
+func TestCombineShouldReturnErrorForDifferentSizes(t *testing.T) {
+	combined, err := Combine(createTestMask(2, 1, nil), createTestMask(1, 2, nil), OperatorAnd)
+
+	assert.NotNil(t, err)
+	assert.Nil(t, combined)
+
+	combined, err = Combine(createTestMask(1, 1, nil), createTestMask(1, 1, nil), Operator(-1))
+
+	assert.NotNil(t, err)
+	assert.Nil(t, combined)
+}
@@ -0,0 +1,144 @@
+package mask
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/Krzysztofz01/pimit"
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
+)
+
+// Flag representing the channel of the image used as the grayscale value of the mask
+type Channel int
+
+const (
+	ChannelGray Channel = iota
+	ChannelLuminance
+	ChannelRed
+	ChannelGreen
+	ChannelBlue
+	ChannelAlpha
+)
+
+// Create a mask from the given grayscale image. The function will return a error if the image contains a pixel that is not grayscale.
+func FromGrayscale(img image.Image) (*image.Gray, error) {
+	if img == nil {
+		panic("mask: the provided image reference is nil")
+	}
+
+	var (
+		imgNrgba *image.NRGBA = utils.ImageToNrgbaImage(img)
+		mask     *image.Gray  = image.NewGray(image.Rect(0, 0, imgNrgba.Bounds().Dx(), imgNrgba.Bounds().Dy()))
+		errt                  = utils.NewErrorTrap()
+	)
+
+	pimit.ParallelNrgbaRead(imgNrgba, func(x, y int, r, g, b, _ uint8) {
+		if r != g || r != b {
+			errt.Set(fmt.Errorf("mask: the mask image contains a invalid color at x=%d y=%d", x, y))
+			return
+		}
+
+		mask.Pix[y*mask.Stride+x] = r
+	})
+
+	if err := errt.Err(); err != nil {
+		return nil, err
+	}
+
+	return mask, nil
+}
+
+// Create a mask from the given channel of the image. The luminance channel is the grayscale value of the color image, while the gray
+// channel requires the image to be grayscale.
+func FromChannel(img image.Image, channel Channel) (*image.Gray, error) {
+	if img == nil {
+		panic("mask: the provided image reference is nil")
+	}
+
+	if channel == ChannelGray {
+		return FromGrayscale(img)
+	}
+
+	var (
+		imgNrgba *image.NRGBA = utils.ImageToNrgbaImage(img)
+		mask     *image.Gray  = image.NewGray(image.Rect(0, 0, imgNrgba.Bounds().Dx(), imgNrgba.Bounds().Dy()))
+		value    func(c color.NRGBA) uint8
+	)
+
+	switch channel {
+	case ChannelLuminance:
+		value = func(c color.NRGBA) uint8 { return uint8(utils.NrgbaToGrayscaleComponent(c)) }
+	case ChannelRed:
+		value = func(c color.NRGBA) uint8 { return c.R }
+	case ChannelGreen:
+		value = func(c color.NRGBA) uint8 { return c.G }
+	case ChannelBlue:
+		value = func(c color.NRGBA) uint8 { return c.B }
+	case ChannelAlpha:
+		value = func(c color.NRGBA) uint8 { return c.A }
+	default:
+		return nil, errors.New("mask: invalid mask channel specified")
+	}
+
+	pimit.ParallelNrgbaRead(imgNrgba, func(x, y int, r, g, b, a uint8) {
+		mask.Pix[y*mask.Stride+x] = value(color.NRGBA{r, g, b, a})
+	})
+
+	return mask, nil
+}
+
+// Create a mask from the alpha channel of the image, so the transparent areas of the image are masked out
+func FromAlpha(img image.Image) *image.Gray {
+	mask, err := FromChannel(img, ChannelAlpha)
+	if err != nil {
+		panic(fmt.Errorf("mask: failed to create the mask from the alpha channel: %w", err))
+	}
+
+	return mask
+}
+
+// Create a chroma-key mask from the image, where the pixels with a color close to the key color are masked out (black) and all other
+// pixels are not masked (white). The tolerance in range from 0.0 to 1.0 is the max normalized RGB distance between the pixel and the key.
+func FromColorKey(img image.Image, key color.Color, tolerance float64) (*image.Gray, error) {
+	if img == nil {
+		panic("mask: the provided image reference is nil")
+	}
+
+	if tolerance < 0.0 || tolerance > 1.0 {
+		return nil, errors.New("mask: the color key tolerance must be between values 0 and 1")
+	}
+
+	var (
+		imgNrgba *image.NRGBA = utils.ImageToNrgbaImage(img)
+		mask     *image.Gray  = image.NewGray(image.Rect(0, 0, imgNrgba.Bounds().Dx(), imgNrgba.Bounds().Dy()))
+		keyNrgba color.NRGBA  = color.NRGBAModel.Convert(key).(color.NRGBA)
+	)
+
+	pimit.ParallelNrgbaRead(imgNrgba, func(x, y int, r, g, b, _ uint8) {
+		dr := float64(r) - float64(keyNrgba.R)
+		dg := float64(g) - float64(keyNrgba.G)
+		db := float64(b) - float64(keyNrgba.B)
+
+		distance := math.Sqrt(dr*dr+dg*dg+db*db) / math.Sqrt(3*255*255)
+
+		if distance <= tolerance {
+			mask.Pix[y*mask.Stride+x] = 0x00
+		} else {
+			mask.Pix[y*mask.Stride+x] = 0xff
+		}
+	})
+
+	return mask, nil
+}
+
+// Create a inverted copy of the mask
+func Invert(mask *image.Gray) *image.Gray {
+	if mask == nil {
+		panic("mask: the provided mask reference is nil")
+	}
+
+	return mapMask(mask, func(v uint8) uint8 { return 0xff - v })
+}
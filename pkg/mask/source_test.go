@@ -0,0 +1,116 @@
+package mask
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+func TestFromGrayscaleShouldCreateMaskFromGrayscaleImage(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{10, 10, 10, 255})
+	img.Set(1, 0, color.NRGBA{200, 200, 200, 255})
+
+	mask, err := FromGrayscale(img)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []uint8{10, 200}, mask.Pix)
+}
+
+func TestFromGrayscaleShouldReturnErrorForColorImage(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{10, 10, 10, 255})
+	img.Set(1, 0, color.NRGBA{200, 0, 0, 255})
+
+	mask, err := FromGrayscale(img)
+
+	assert.NotNil(t, err)
+	assert.Nil(t, mask)
+}
+
+func TestFromChannelShouldCreateMaskFromChannel(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, color.NRGBA{200, 100, 50, 150})
+
+	cases := map[Channel]uint8{
+		ChannelLuminance: 124,
+		ChannelRed:       200,
+		ChannelGreen:     100,
+		ChannelBlue:      50,
+		ChannelAlpha:     150,
+	}
+
+	for channel, expected := range cases {
+		mask, err := FromChannel(img, channel)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, mask.Pix[0])
+	}
+}
+
+func TestFromChannelShouldReturnErrorForInvalidChannel(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	mask, err := FromChannel(image.NewNRGBA(image.Rect(0, 0, 1, 1)), Channel(-1))
+
+	assert.NotNil(t, err)
+	assert.Nil(t, mask)
+}
+
+func TestFromAlphaShouldCreateMaskFromAlphaChannel(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{255, 0, 0, 0})
+	img.Set(1, 0, color.NRGBA{255, 0, 0, 255})
+
+	mask := FromAlpha(img)
+
+	assert.Equal(t, []uint8{0, 255}, mask.Pix)
+}
+
+func TestFromColorKeyShouldMaskPixelsCloseToTheKey(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	img.Set(0, 0, color.NRGBA{0, 255, 0, 255})
+	img.Set(1, 0, color.NRGBA{20, 230, 20, 255})
+	img.Set(2, 0, color.NRGBA{200, 50, 80, 255})
+
+	mask, err := FromColorKey(img, color.NRGBA{0, 255, 0, 255}, 0.1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []uint8{0, 0, 255}, mask.Pix)
+}
+
+func TestFromColorKeyShouldReturnErrorForInvalidTolerance(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	cases := []float64{-0.1, 1.1}
+
+	for _, tolerance := range cases {
+		mask, err := FromColorKey(image.NewNRGBA(image.Rect(0, 0, 1, 1)), color.Black, tolerance)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, mask)
+	}
+}
+
+func TestInvertShouldInvertTheMask(t *testing.T) {
+	mask := image.NewGray(image.Rect(0, 0, 3, 1))
+	mask.Pix = []uint8{0, 100, 255}
+
+	inverted := Invert(mask)
+
+	assert.Equal(t, []uint8{255, 155, 0}, inverted.Pix)
+	assert.Equal(t, []uint8{0, 100, 255}, mask.Pix)
+}
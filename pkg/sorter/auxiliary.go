@@ -0,0 +1,352 @@
+package sorter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/Krzysztofz01/pimit"
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
+)
+
+// Structure representing the optional auxiliary images that can be provided to the sorter next to the sorted image and
+// the mask image. All of the provided images must have the same bounds as the sorted image.
+type AuxiliaryImages struct {
+	// Image used as the source of the pixels sort weights for the SortByKeyImage sort determinant. The perceived brightness
+	// of the key image pixel is used as the weight of the pixel located at the same coordinates of the sorted image.
+	KeyImage image.Image
+
+	// Image used as the source of the interval determinant values for the SplitByMap interval determinant. The grayscale value
+	// of the map pixel is compared against the interval determinant thresholds. Color images are converted to grayscale.
+	IntervalMapImage image.Image
+
+	// Image used as the source of the max interval lengths for the IntervalLengthMap interval length source. The grayscale value of
+	// the length map pixel multiplied by the interval max length is the max length of the interval starting at the same coordinates.
+	IntervalLengthMapImage image.Image
+
+	// Image used as the source of the interval sort directions for the DirectionByMap direction scheme. The intervals starting at
+	// the pixels with a grayscale value of the direction map above the half are sorted in the reversed direction.
+	DirectionMapImage image.Image
+
+	// Image used as the per-pixel opacity of the sorted image blended into the original image. The grayscale value of the opacity image
+	// pixel is multiplied by the blending opacity. The image is not rotated, because the blending is performed after reverting the rotation.
+	BlendingOpacityImage image.Image
+}
+
+// Internal representation of the auxiliary images converted to the NRGBA color space
+type auxiliaryImagesNrgba struct {
+	keyImage               *image.NRGBA
+	intervalMapImage       *image.NRGBA
+	intervalLengthMapImage *image.NRGBA
+	directionMapImage      *image.NRGBA
+	blendingOpacityImage   *image.NRGBA
+}
+
+// Create a NRGBA representation of the provided auxiliary images and validate if they are matching the given bounds. The function
+// will return a empty set of auxiliary images if the provided auxiliary images reference is nil.
+func createAuxiliaryImagesNrgba(images *AuxiliaryImages, bounds image.Rectangle) (*auxiliaryImagesNrgba, error) {
+	auxiliary := new(auxiliaryImagesNrgba)
+	if images == nil {
+		return auxiliary, nil
+	}
+
+	if images.KeyImage != nil {
+		if images.KeyImage.Bounds() != bounds {
+			return nil, fmt.Errorf("sorter: can not create a sorter for a image and key image with bounds that are not matching")
+		}
+
+		auxiliary.keyImage = utils.ImageToNrgbaImage(images.KeyImage)
+	}
+
+	if images.IntervalMapImage != nil {
+		if images.IntervalMapImage.Bounds() != bounds {
+			return nil, fmt.Errorf("sorter: can not create a sorter for a image and interval map image with bounds that are not matching")
+		}
+
+		auxiliary.intervalMapImage = utils.ImageToNrgbaImage(images.IntervalMapImage)
+	}
+
+	if images.IntervalLengthMapImage != nil {
+		if images.IntervalLengthMapImage.Bounds() != bounds {
+			return nil, fmt.Errorf("sorter: can not create a sorter for a image and interval length map image with bounds that are not matching")
+		}
+
+		auxiliary.intervalLengthMapImage = utils.ImageToNrgbaImage(images.IntervalLengthMapImage)
+	}
+
+	if images.DirectionMapImage != nil {
+		if images.DirectionMapImage.Bounds() != bounds {
+			return nil, fmt.Errorf("sorter: can not create a sorter for a image and direction map image with bounds that are not matching")
+		}
+
+		auxiliary.directionMapImage = utils.ImageToNrgbaImage(images.DirectionMapImage)
+	}
+
+	if images.BlendingOpacityImage != nil {
+		if images.BlendingOpacityImage.Bounds() != bounds {
+			return nil, fmt.Errorf("sorter: can not create a sorter for a image and blending opacity image with bounds that are not matching")
+		}
+
+		auxiliary.blendingOpacityImage = utils.ImageToNrgbaImage(images.BlendingOpacityImage)
+	}
+
+	return auxiliary, nil
+}
+
+// Create a copy of the auxiliary images scaled according to the given percentage parameter
+func (auxiliary *auxiliaryImagesNrgba) scale(percentage float64) (*auxiliaryImagesNrgba, error) {
+	var (
+		scaled *auxiliaryImagesNrgba = new(auxiliaryImagesNrgba)
+		err    error
+	)
+
+	if auxiliary.keyImage != nil {
+		if scaled.keyImage, err = utils.ScaleImageNrgba(auxiliary.keyImage, percentage); err != nil {
+			return nil, fmt.Errorf("sorter: failed to scale the key image: %w", err)
+		}
+	}
+
+	if auxiliary.intervalMapImage != nil {
+		if scaled.intervalMapImage, err = utils.ScaleImageNrgba(auxiliary.intervalMapImage, percentage); err != nil {
+			return nil, fmt.Errorf("sorter: failed to scale the interval map image: %w", err)
+		}
+	}
+
+	if auxiliary.intervalLengthMapImage != nil {
+		if scaled.intervalLengthMapImage, err = utils.ScaleImageNrgba(auxiliary.intervalLengthMapImage, percentage); err != nil {
+			return nil, fmt.Errorf("sorter: failed to scale the interval length map image: %w", err)
+		}
+	}
+
+	if auxiliary.directionMapImage != nil {
+		if scaled.directionMapImage, err = utils.ScaleImageNrgba(auxiliary.directionMapImage, percentage); err != nil {
+			return nil, fmt.Errorf("sorter: failed to scale the direction map image: %w", err)
+		}
+	}
+
+	if auxiliary.blendingOpacityImage != nil {
+		if scaled.blendingOpacityImage, err = utils.ScaleImageNrgba(auxiliary.blendingOpacityImage, percentage); err != nil {
+			return nil, fmt.Errorf("sorter: failed to scale the blending opacity image: %w", err)
+		}
+	}
+
+	return scaled, nil
+}
+
+// Create a copy of the auxiliary images rotated counter-clockwise by the given angle
+func (auxiliary *auxiliaryImagesNrgba) rotate(angle int) *auxiliaryImagesNrgba {
+	rotated := new(auxiliaryImagesNrgba)
+
+	if auxiliary.keyImage != nil {
+		rotated.keyImage = utils.RotateImageNrgba(auxiliary.keyImage, angle)
+	}
+
+	if auxiliary.intervalMapImage != nil {
+		rotated.intervalMapImage = utils.RotateImageNrgba(auxiliary.intervalMapImage, angle)
+	}
+
+	if auxiliary.intervalLengthMapImage != nil {
+		rotated.intervalLengthMapImage = utils.RotateImageNrgba(auxiliary.intervalLengthMapImage, angle)
+	}
+
+	if auxiliary.directionMapImage != nil {
+		rotated.directionMapImage = utils.RotateImageNrgba(auxiliary.directionMapImage, angle)
+	}
+
+	// NOTE: The blending opacity image is not rotated, because the blending is performed after reverting the rotation
+	rotated.blendingOpacityImage = auxiliary.blendingOpacityImage
+
+	return rotated
+}
+
+// Structure representing the per-pixel data sources shared by all strips during the sorting process
+type sortingSources struct {
+	mask         Mask
+	sortWeights  []float64
+	intervalMap  []float64
+	noise        []float64
+	lengthValues []float64
+	directions   []float64
+	clusters     *colorClusters
+	quantizer    *intervalQuantizer
+}
+
+// Create the sorting sources for the given mask, auxiliary images and color clusters matching the sorted image. The bounds are the bounds of the sorted
+// (scaled and rotated) image and the original bounds are the bounds of the scaled image before the rotation, which are used to keep the
+// noise field independent from the rotation. The function will return a error if the determinants specified by the options require a
+// source that was not provided.
+func createSortingSources(mask Mask, auxiliary *auxiliaryImagesNrgba, clusters *colorClusters, bounds, originalBounds image.Rectangle, options *SorterOptions) (*sortingSources, error) {
+	sources := &sortingSources{
+		mask:         mask,
+		sortWeights:  nil,
+		intervalMap:  nil,
+		noise:        nil,
+		lengthValues: nil,
+		directions:   nil,
+		clusters:     clusters,
+		quantizer:    nil,
+	}
+
+	if options.IntervalPainting == IntervalQuantize {
+		sources.quantizer = createIntervalQuantizer(options)
+	}
+
+	if options.usesNoise() {
+		sources.noise = createNoiseValues(bounds, originalBounds, options)
+	}
+
+	switch options.SortDeterminant {
+	case SortByKeyImage:
+		{
+			if auxiliary == nil || auxiliary.keyImage == nil {
+				return nil, fmt.Errorf("sorter: the key image sort determinant requires a key image to be provided")
+			}
+
+			sources.sortWeights = createKeyWeights(auxiliary.keyImage)
+		}
+	case SortByNoise:
+		{
+			sources.sortWeights = sources.noise
+		}
+	}
+
+	if options.usesIntervalDeterminant(SplitByMap) {
+		if auxiliary == nil || auxiliary.intervalMapImage == nil {
+			return nil, fmt.Errorf("sorter: the map interval determinant requires a interval map image to be provided")
+		}
+
+		sources.intervalMap = createIntervalMapValues(auxiliary.intervalMapImage)
+	}
+
+	if options.IntervalDeterminant == SplitByClusters && clusters == nil {
+		return nil, fmt.Errorf("sorter: the clusters interval determinant requires the color clusters to be calculated")
+	}
+
+	switch options.IntervalLengthSource {
+	case IntervalLengthNoise:
+		{
+			sources.lengthValues = sources.noise
+		}
+	case IntervalLengthMap:
+		{
+			if auxiliary == nil || auxiliary.intervalLengthMapImage == nil {
+				return nil, fmt.Errorf("sorter: the map interval length source requires a interval length map image to be provided")
+			}
+
+			sources.lengthValues = createIntervalMapValues(auxiliary.intervalLengthMapImage)
+		}
+	}
+
+	switch options.DirectionScheme {
+	case DirectionByNoise:
+		{
+			sources.directions = sources.noise
+		}
+	case DirectionByMap:
+		{
+			if auxiliary == nil || auxiliary.directionMapImage == nil {
+				return nil, fmt.Errorf("sorter: the map direction scheme requires a direction map image to be provided")
+			}
+
+			sources.directions = createIntervalMapValues(auxiliary.directionMapImage)
+		}
+	}
+
+	return sources, nil
+}
+
+// Return the interval determinant source value (interval map, noise or cluster label) of the pixel at the given index or zero if the given
+// interval determinant is not using any source
+func (sources *sortingSources) sourceValueAt(determinant IntervalDeterminant, pixelIndex int) float64 {
+	switch determinant {
+	case SplitByMap:
+		return sources.intervalMap[pixelIndex]
+	case SplitByNoise:
+		return sources.noise[pixelIndex]
+	case SplitByClusters:
+		return float64(sources.clusters.labels[pixelIndex])
+	default:
+		return 0
+	}
+}
+
+// Function used to calculate the max interval length for a interval starting at the given pixel index. For the noise and map length sources
+// the interval length specified by the options is multiplied by the source value at the given pixel. The final length is drawn from the
+// interval length distribution specified by the options using the given random source.
+func (sources *sortingSources) calculateMaxIntervalLength(options *SorterOptions, random *rand.Rand, pixelIndex int) int {
+	length := options.IntervalLength
+	if sources.lengthValues != nil && length != 0 {
+		length = int(math.Round(sources.lengthValues[pixelIndex] * float64(length)))
+		if length < 1 {
+			length = 1
+		}
+	}
+
+	return calculateDistributedIntervalLength(length, options, random)
+}
+
+// Calculate the fractal noise values of all pixels of the sorted image. The noise is sampled in the coordinates of the image before the
+// rotation, so the noise field stays in place regardless of the sorting angle. The noise scale is expressed in the original image pixels.
+func createNoiseValues(bounds, originalBounds image.Rectangle, options *SorterOptions) []float64 {
+	var (
+		width           int                = bounds.Dx()
+		height          int                = bounds.Dy()
+		values          []float64          = make([]float64, width*height)
+		noise           *utils.PerlinNoise = utils.CreatePerlinNoise(options.NoiseSeed)
+		frequency       float64            = 1.0 / (options.NoiseScale * options.Scale)
+		sin, cos        float64            = math.Sincos(math.Pi * float64(options.Angle) / 180.0)
+		xOffset         float64            = float64(width)/2.0 - 0.5
+		yOffset         float64            = float64(height)/2.0 - 0.5
+		originalXCenter float64            = float64(originalBounds.Dx())/2.0 - 0.5
+		originalYCenter float64            = float64(originalBounds.Dy())/2.0 - 0.5
+	)
+
+	wg := &sync.WaitGroup{}
+	for y := 0; y < height; y += 1 {
+		wg.Add(1)
+		go func(yIndex int) {
+			defer wg.Done()
+
+			dy := float64(yIndex) - yOffset
+			for x := 0; x < width; x += 1 {
+				dx := float64(x) - xOffset
+
+				// NOTE: Inverse of the rotation mapping performed by the imaging library rotation
+				xOriginal := dx*cos - dy*sin + originalXCenter
+				yOriginal := dx*sin + dy*cos + originalYCenter
+
+				values[yIndex*width+x] = noise.FractalNoise(xOriginal*frequency, yOriginal*frequency, options.NoiseOctaves, options.NoisePersistence)
+			}
+		}(y)
+	}
+
+	wg.Wait()
+	return values
+}
+
+// Calculate the sort weights of all pixels of the key image represented as the perceived brightness of the key pixel
+func createKeyWeights(keyImage *image.NRGBA) []float64 {
+	width := keyImage.Bounds().Dx()
+	weights := make([]float64, width*keyImage.Bounds().Dy())
+
+	pimit.ParallelNrgbaRead(keyImage, func(x, y int, r, g, b, _ uint8) {
+		weights[y*width+x] = utils.CalculatePerceivedBrightness(color.RGBA{r, g, b, 0xff})
+	})
+
+	return weights
+}
+
+// Calculate the normalized grayscale values of all pixels of the interval map image
+func createIntervalMapValues(intervalMapImage *image.NRGBA) []float64 {
+	width := intervalMapImage.Bounds().Dx()
+	values := make([]float64, width*intervalMapImage.Bounds().Dy())
+
+	pimit.ParallelNrgbaRead(intervalMapImage, func(x, y int, r, g, b, a uint8) {
+		values[y*width+x] = float64(utils.NrgbaToGrayscaleComponent(color.NRGBA{r, g, b, a})) / 255.0
+	})
+
+	return values
+}
@@ -14,6 +14,7 @@ import (
 type bufferedSorter struct {
 	image       *image.NRGBA
 	maskImage   *image.NRGBA
+	auxiliary   *auxiliaryImagesNrgba
 	logger      SorterLogger
 	cancel      func()
 	cancelMutex sync.Mutex
@@ -23,6 +24,12 @@ type bufferedSorter struct {
 // Create a new buffered image sorter instance by providing the image to be sorted and optional parameters such as mask image
 // and a logger instance. This function will return a new buffered sorter instance or a error.
 func CreateBufferedSorter(image image.Image, mask image.Image, logger SorterLogger) (BufferedSorter, error) {
+	return CreateBufferedSorterWithAuxiliaryImages(image, mask, nil, logger)
+}
+
+// Create a new buffered image sorter instance by providing the image to be sorted and optional parameters such as mask image,
+// auxiliary images and a logger instance. This function will return a new buffered sorter instance or a error.
+func CreateBufferedSorterWithAuxiliaryImages(image image.Image, mask image.Image, auxiliary *AuxiliaryImages, logger SorterLogger) (BufferedSorter, error) {
 	if image == nil {
 		return nil, fmt.Errorf("sorter: can not create a sorter with the provided nil image")
 	}
@@ -41,6 +48,13 @@ func CreateBufferedSorter(image image.Image, mask image.Image, logger SorterLogg
 		}
 	}
 
+	auxiliaryNrgba, err := createAuxiliaryImagesNrgba(auxiliary, image.Bounds())
+	if err != nil {
+		return nil, err
+	}
+
+	sorter.auxiliary = auxiliaryNrgba
+
 	if logger == nil {
 		sorter.logger = getDiscardLogger()
 	} else {
@@ -65,14 +79,18 @@ func (sorter *bufferedSorter) CancelSort() bool {
 
 func (sorter *bufferedSorter) Sort(options *SorterOptions) (image.Image, error) {
 	var (
-		srcImageNrgba       *image.NRGBA
-		srcImageScaledNrgba *image.NRGBA
-		srcMaskImageNrgba   *image.NRGBA
-		srcImageRgba        *image.RGBA
-		mask                Mask
-		revertRotation      func(*image.NRGBA) *image.NRGBA
-		sortingExecTime     time.Time = time.Now()
-		err                 error     = nil
+		srcImageNrgba           *image.NRGBA
+		srcImageScaledNrgba     *image.NRGBA
+		srcMaskImageNrgba       *image.NRGBA
+		srcMaskImageScaledNrgba *image.NRGBA
+		srcImageRgba            *image.RGBA
+		mask                    Mask
+		auxiliary               *auxiliaryImagesNrgba
+		sources                 *sortingSources
+		clusters                *colorClusters
+		revertRotation          func(*image.NRGBA) *image.NRGBA
+		sortingExecTime         time.Time = time.Now()
+		err                     error     = nil
 	)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -85,37 +103,21 @@ func (sorter *bufferedSorter) Sort(options *SorterOptions) (image.Image, error)
 	sorter.state.Apply(options)
 	defer sorter.state.Rollback()
 
-	if options.Scale != 1.0 {
-		scalingExecTime := time.Now()
-
-		if bufferedSrcImg, bufferedSrcMaskImg, ok := sorter.state.GetScaledImages(); ok {
-			srcImageNrgba = bufferedSrcImg
-			srcImageScaledNrgba = bufferedSrcImg
-			srcMaskImageNrgba = bufferedSrcMaskImg
-		} else {
-			if srcImageNrgba, err = utils.ScaleImageNrgba(sorter.image, options.Scale); err != nil {
-				return nil, fmt.Errorf("sorter: failed to scale the target image: %w", err)
-			}
-
-			if sorter.maskImage != nil {
-				if srcMaskImageNrgba, err = utils.ScaleImageNrgba(sorter.maskImage, options.Scale); err != nil {
-					return nil, fmt.Errorf("sorter: failed to scale the target image mask: %w", err)
-				}
-			} else {
-				srcMaskImageNrgba = nil
-			}
+	if len(options.CompositeAngles) > 0 {
+		return sorter.sortAngleComposite(options, sortingExecTime, ctx)
+	}
 
-			srcImageScaledNrgba = srcImageNrgba
-			sorter.state.SetScaledImages(srcImageNrgba, srcMaskImageNrgba)
-		}
+	if options.ChannelSplit != ChannelSplitNone || !options.CycleSchedule.IsEmpty() {
+		return sorter.sortScaledImageCycles(options, sortingExecTime, ctx)
+	}
 
-		sorter.logger.Debugf("Input images scaling took: %s", time.Since(scalingExecTime))
-	} else {
-		srcImageNrgba = sorter.image
-		srcImageScaledNrgba = sorter.image
-		srcMaskImageNrgba = sorter.maskImage
+	if srcImageNrgba, srcMaskImageNrgba, auxiliary, err = sorter.getScaledImages(options); err != nil {
+		return nil, err
 	}
 
+	srcImageScaledNrgba = srcImageNrgba
+	srcMaskImageScaledNrgba = srcMaskImageNrgba
+
 	if options.Angle != 0 {
 		if bufferedSrcImg, bufferedSrcMaskImg, ok := sorter.state.GetRotatedImages(); ok {
 			revertRotationRectangle := srcImageNrgba.Rect
@@ -135,9 +137,17 @@ func (sorter *bufferedSorter) Sort(options *SorterOptions) (image.Image, error)
 
 			sorter.state.SetRotatedImages(srcImageNrgba, srcMaskImageNrgba)
 		}
+
+		if bufferedAuxiliary, ok := sorter.state.GetRotatedAuxiliaryImages(); ok {
+			auxiliary = bufferedAuxiliary
+		} else {
+			auxiliary = auxiliary.rotate(options.Angle)
+
+			sorter.state.SetRotatedAuxiliaryImages(auxiliary)
+		}
 	}
 
-	if options.IntervalDeterminant == SplitByEdgeDetection {
+	if options.usesIntervalDeterminant(SplitByEdgeDetection) {
 		edgeDetectionExecTime := time.Now()
 
 		if bufferedImage, ok := sorter.state.GetEdgeDetectionImage(); ok {
@@ -165,44 +175,67 @@ func (sorter *bufferedSorter) Sort(options *SorterOptions) (image.Image, error)
 		mask = CreateEmptyMask()
 	}
 
+	if options.IntervalDeterminant == SplitByClusters {
+		clusteringExecTime := time.Now()
+
+		if bufferedClusters, ok := sorter.state.GetColorClusters(); ok {
+			clusters = bufferedClusters
+		} else {
+			clusters = labelColorClusters(srcImageNrgba, createClusterCentroids(sorter.image, options))
+
+			sorter.state.SetColorClusters(clusters)
+		}
+
+		sorter.logger.Debugf("Color clustering took: %s.", time.Since(clusteringExecTime))
+	}
+
+	if sources, err = createSortingSources(mask, auxiliary, clusters, srcImageNrgba.Bounds(), srcImageScaledNrgba.Bounds(), options); err != nil {
+		return nil, err
+	}
+
 	srcImageRgba = utils.NrgbaToRgbaImage(srcImageNrgba)
 	dstImageRgba := utils.GetImageCopyRgba(srcImageRgba)
 
+	sortOptions, err := resolveIntervalDeterminantThresholds(srcImageRgba, sources, options, sorter.logger)
+	if err != nil {
+		return nil, err
+	}
+
 	for c := 0; c < options.Cycles; c += 1 {
 		switch options.SortOrder {
 		case SortVertical:
 			{
-				if err = performParallelColumnSorting(srcImageRgba, dstImageRgba, mask, options, ctx); err != nil {
+				if err = performParallelColumnSorting(srcImageRgba, dstImageRgba, sources, sortOptions, c, ctx); err != nil {
 					return nil, fmt.Errorf("sorter: failed to perform the vertical column sort: %w", err)
 				}
 			}
 		case SortHorizontal:
 			{
-				if err = performParallelRowSorting(srcImageRgba, dstImageRgba, mask, options, ctx); err != nil {
+				if err = performParallelRowSorting(srcImageRgba, dstImageRgba, sources, sortOptions, c, ctx); err != nil {
 					return nil, fmt.Errorf("sorter: failed to perform the horizontal row sort: %w", err)
 				}
 			}
 		case SortVerticalAndHorizontal:
 			{
-				if err = performParallelColumnSorting(srcImageRgba, dstImageRgba, mask, options, ctx); err != nil {
+				if err = performParallelColumnSorting(srcImageRgba, dstImageRgba, sources, sortOptions, c, ctx); err != nil {
 					return nil, fmt.Errorf("sorter: failed to perform the vertical column sort: %w", err)
 				}
 
 				copy(srcImageRgba.Pix, dstImageRgba.Pix)
 
-				if err = performParallelRowSorting(srcImageRgba, dstImageRgba, mask, options, ctx); err != nil {
+				if err = performParallelRowSorting(srcImageRgba, dstImageRgba, sources, sortOptions, c, ctx); err != nil {
 					return nil, fmt.Errorf("sorter: failed to perform the horizontal row sort: %w", err)
 				}
 			}
 		case SortHorizontalAndVertical:
 			{
-				if err = performParallelRowSorting(srcImageRgba, dstImageRgba, mask, options, ctx); err != nil {
+				if err = performParallelRowSorting(srcImageRgba, dstImageRgba, sources, sortOptions, c, ctx); err != nil {
 					return nil, fmt.Errorf("sorter: failed to perform the horizontal row sort: %w", err)
 				}
 
 				copy(srcImageRgba.Pix, dstImageRgba.Pix)
 
-				if err = performParallelColumnSorting(srcImageRgba, dstImageRgba, mask, options, ctx); err != nil {
+				if err = performParallelColumnSorting(srcImageRgba, dstImageRgba, sources, sortOptions, c, ctx); err != nil {
 					return nil, fmt.Errorf("sorter: failed to perform the vertical column sort: %w", err)
 				}
 			}
@@ -218,25 +251,156 @@ func (sorter *bufferedSorter) Sort(options *SorterOptions) (image.Image, error)
 		dstImageNrgba = revertRotation(dstImageNrgba)
 	}
 
-	switch options.Blending {
-	case BlendingLighten:
-		{
-			if dstImageNrgba, err = utils.BlendImagesNrgba(srcImageScaledNrgba, dstImageNrgba, utils.LightenOnly); err != nil {
-				return nil, fmt.Errorf("sorter: failed to perform the image lighten blending: %w", err)
+	if dstImageNrgba, err = blendSortedImage(srcImageScaledNrgba, dstImageNrgba, srcMaskImageScaledNrgba, auxiliary.blendingOpacityImage, options); err != nil {
+		return nil, err
+	}
+
+	sorter.state.Commit()
+
+	sorter.logger.Debugf("Pixel sorting took: %s.", time.Since(sortingExecTime))
+	return dstImageNrgba, nil
+}
+
+// Get the images and auxiliary images scaled according to the given options with the mask image feathered according to the options. The
+// scaled images are buffered by the state, so the options must be applied to the state before.
+func (sorter *bufferedSorter) getScaledImages(options *SorterOptions) (*image.NRGBA, *image.NRGBA, *auxiliaryImagesNrgba, error) {
+	if options.Scale == 1.0 && (options.MaskFeatherRadius == 0.0 || sorter.maskImage == nil) {
+		return sorter.image, sorter.maskImage, sorter.auxiliary, nil
+	}
+
+	var (
+		srcImageNrgba     *image.NRGBA
+		srcMaskImageNrgba *image.NRGBA
+		auxiliary         *auxiliaryImagesNrgba
+		scalingExecTime   time.Time = time.Now()
+		err               error     = nil
+	)
+
+	if bufferedSrcImg, bufferedSrcMaskImg, ok := sorter.state.GetScaledImages(); ok {
+		srcImageNrgba = bufferedSrcImg
+		srcMaskImageNrgba = bufferedSrcMaskImg
+	} else {
+		srcImageNrgba = sorter.image
+		srcMaskImageNrgba = sorter.maskImage
+
+		if options.Scale != 1.0 {
+			if srcImageNrgba, err = utils.ScaleImageNrgba(sorter.image, options.Scale); err != nil {
+				return nil, nil, nil, fmt.Errorf("sorter: failed to scale the target image: %w", err)
 			}
-		}
-	case BlendingDarken:
-		{
-			if dstImageNrgba, err = utils.BlendImagesNrgba(srcImageScaledNrgba, dstImageNrgba, utils.DarkenOnly); err != nil {
-				return nil, fmt.Errorf("sorter: failed to perform the image darken blending: %w", err)
+
+			if sorter.maskImage != nil {
+				if srcMaskImageNrgba, err = utils.ScaleImageNrgba(sorter.maskImage, options.Scale); err != nil {
+					return nil, nil, nil, fmt.Errorf("sorter: failed to scale the target image mask: %w", err)
+				}
 			}
 		}
-	case BlendingNone:
-		break
-	default:
-		panic("sorter: invalid blending mode specified")
+
+		// NOTE: The mask feather radius is expressed in the original image pixels
+		srcMaskImageNrgba = featherMaskImage(srcMaskImageNrgba, options.MaskFeatherRadius*options.Scale)
+
+		sorter.state.SetScaledImages(srcImageNrgba, srcMaskImageNrgba)
+	}
+
+	if options.Scale == 1.0 {
+		auxiliary = sorter.auxiliary
+	} else if bufferedAuxiliary, ok := sorter.state.GetScaledAuxiliaryImages(); ok {
+		auxiliary = bufferedAuxiliary
+	} else {
+		if auxiliary, err = sorter.auxiliary.scale(options.Scale); err != nil {
+			return nil, nil, nil, fmt.Errorf("sorter: failed to scale the auxiliary images: %w", err)
+		}
+
+		sorter.state.SetScaledAuxiliaryImages(auxiliary)
+	}
+
+	sorter.logger.Debugf("Input images scaling took: %s", time.Since(scalingExecTime))
+	return srcImageNrgba, srcMaskImageNrgba, auxiliary, nil
+}
+
+// Perform the sorting of the color channel planes or the scheduled cycles specified by the options using the buffered scaled images. The
+// planes and cycles are sorted by a separate sorter sharing the scaled images, so only the scaling is buffered and the rotation is performed
+// on every sort, because each plane and cycle can be using a different image and angle. The options must be applied to the state before.
+func (sorter *bufferedSorter) sortScaledImageCycles(options *SorterOptions, sortingExecTime time.Time, ctx context.Context) (image.Image, error) {
+	srcImageNrgba, srcMaskImageNrgba, auxiliary, err := sorter.getScaledImages(options)
+	if err != nil {
+		return nil, err
+	}
+
+	cycleSorter := &defaultSorter{
+		image:            sorter.image,
+		maskImage:        srcMaskImageNrgba,
+		auxiliary:        auxiliary,
+		clusterCentroids: createClusterCentroids(sorter.image, options),
+		logger:           sorter.logger,
+	}
+
+	// NOTE: The images are already scaled, so the noise scale is adjusted to keep the noise field expressed in the original image pixels and
+	// the buffered scaled mask image is already feathered
+	scaledOptions := *options
+	scaledOptions.NoiseScale = options.NoiseScale * options.Scale
+	scaledOptions.MaskFeatherRadius = 0.0
+	scaledOptions.Scale = 1.0
+
+	var dstImageNrgba *image.NRGBA
+	if options.ChannelSplit != ChannelSplitNone {
+		dstImageNrgba, _, err = performChannelSplitSort(srcImageNrgba, &scaledOptions, func(plane *image.NRGBA, planeOptions *SorterOptions) (*image.NRGBA, error) {
+			return cycleSorter.sortImageCycles(plane, planeOptions, ctx)
+		})
+	} else {
+		dstImageNrgba, err = cycleSorter.sortImageCycles(srcImageNrgba, &scaledOptions, ctx)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if dstImageNrgba, err = blendSortedImage(srcImageNrgba, dstImageNrgba, srcMaskImageNrgba, auxiliary.blendingOpacityImage, options); err != nil {
+		return nil, err
+	}
+
+	// NOTE: The buffered images depending on the angle are not matching the committed options, because the cycles are sorted without the state
+	sorter.state.ResetRotatedImages()
+	sorter.state.Commit()
+
+	sorter.logger.Debugf("Pixel sorting took: %s.", time.Since(sortingExecTime))
+	return dstImageNrgba, nil
+}
+
+// Perform the sorting at all composite angles specified by the options using the buffered scaled images. Each angle is sorted by a separate
+// sorter sharing the scaled images, so the angles can be sorted in parallel. The options must be applied to the state before.
+func (sorter *bufferedSorter) sortAngleComposite(options *SorterOptions, sortingExecTime time.Time, ctx context.Context) (image.Image, error) {
+	srcImageNrgba, srcMaskImageNrgba, auxiliary, err := sorter.getScaledImages(options)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterCentroids := createClusterCentroids(sorter.image, options)
+
+	dstImageNrgba, err := performAngleCompositeSort(srcImageNrgba, options, func(angleOptions *SorterOptions) (*image.NRGBA, error) {
+		// NOTE: The buffered scaled mask image is already feathered
+		angleOptions.MaskFeatherRadius = 0.0
+
+		angleSorter := &defaultSorter{
+			image:            sorter.image,
+			maskImage:        srcMaskImageNrgba,
+			auxiliary:        auxiliary,
+			clusterCentroids: clusterCentroids,
+			logger:           sorter.logger,
+		}
+
+		return angleSorter.sortImageCycles(srcImageNrgba, angleOptions, ctx)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if dstImageNrgba, err = blendSortedImage(srcImageNrgba, dstImageNrgba, srcMaskImageNrgba, auxiliary.blendingOpacityImage, options); err != nil {
+		return nil, err
 	}
 
+	// NOTE: The buffered images depending on the angle are not matching the committed options, because the angles are sorted without the state
+	sorter.state.ResetRotatedImages()
 	sorter.state.Commit()
 
 	sorter.logger.Debugf("Pixel sorting took: %s.", time.Since(sortingExecTime))
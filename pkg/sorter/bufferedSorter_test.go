@@ -1,6 +1,7 @@
 package sorter
 
 import (
+	"image/color"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -175,6 +176,32 @@ func TestBufferedSorterDefaultOptionsAndSortDeterminantBlueChannel(t *testing.T)
 	assert.Nil(t, err)
 }
 
+func TestBufferedSorterDefaultOptionsAndSortDeterminantKeyImage(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.SortDeterminant = SortByKeyImage
+	options.Angle = 45
+	options.Scale = 0.5
+
+	auxiliary := &AuxiliaryImages{
+		KeyImage: mockTestBlackAndWhiteStripesImage(),
+	}
+
+	sorter, err := CreateBufferedSorterWithAuxiliaryImages(mockTestBlackAndWhiteStripesImage(), nil, auxiliary, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
 func TestBufferedSorterDefaultOptionsAndAngle45Degrees(t *testing.T) {
 	defer goleak.VerifyNone(t)
 
@@ -728,3 +755,1106 @@ func TestBufferedSorterDefaultOptionsAndIntervalPaintingAverage(t *testing.T) {
 	assert.NotNil(t, result)
 	assert.Nil(t, err)
 }
+
+func TestBufferedSorterDefaultOptionsAndIntervalDeterminantMap(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByMap
+	options.IntervalDeterminantLowerThreshold = 0.4
+	options.IntervalDeterminantUpperThreshold = 1.0
+
+	auxiliary := &AuxiliaryImages{
+		IntervalMapImage: mockTestBlackAndWhiteStripesImage(),
+	}
+
+	sorter, err := CreateBufferedSorterWithAuxiliaryImages(mockTestBlackAndWhiteStripesImage(), nil, auxiliary, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalDeterminantMapShouldFailWithoutMapImage(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByMap
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalThresholdSelectionOtsu(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalThresholdSelection = ThresholdOtsu
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalThresholdSelectionPercentile(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalThresholdSelection = ThresholdPercentile
+	options.IntervalLowerPercentile = 20
+	options.IntervalUpperPercentile = 85
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalDeterminantHueWrapAround(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByHue
+	options.IntervalDeterminantWrapAround = true
+	options.IntervalDeterminantLowerThreshold = 0.9
+	options.IntervalDeterminantUpperThreshold = 0.1
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalDeterminantRgbChannels(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByRgbChannels
+	options.IntervalChannelThresholds.RedLower = 0.5
+	options.IntervalChannelThresholds.BlueUpper = 0.4
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalDeterminantLocalDifferenceBrightness(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByLocalDifference
+	options.IntervalDifferenceMetric = DifferenceBrightness
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalDeterminantLocalDifferenceOklab(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByLocalDifference
+	options.IntervalDifferenceMetric = DifferenceOklab
+	options.IntervalDifferenceThreshold = 0.2
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndNoiseIntervalDeterminant(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByNoise
+	options.IntervalDeterminantLowerThreshold = 0.4
+	options.IntervalDeterminantUpperThreshold = 0.8
+	options.NoiseSeed = 42
+	options.NoiseScale = 10
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndNoiseSortDeterminant(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.SortDeterminant = SortByNoise
+	options.NoiseScale = 10
+	options.Angle = 45
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndNoiseIntervalLengthSource(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 20
+	options.IntervalLengthSource = IntervalLengthNoise
+	options.NoiseScale = 10
+	options.Scale = 0.5
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalExpression(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByExpression
+	options.IntervalExpression = CreateIntervalAndExpression(
+		CreateIntervalClauseExpression(SplitByBrightness, 0.2, 0.8),
+		CreateIntervalNotExpression(CreateIntervalClauseExpression(SplitByHue, 0.55, 0.7)),
+		CreateIntervalClauseExpression(SplitByNoise, 0.3, 1.0))
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalExpressionWithEdge(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByExpression
+	options.IntervalExpression = CreateIntervalOrExpression(
+		CreateIntervalClauseExpression(SplitByEdgeDetection, 0, 1),
+		CreateIntervalClauseExpression(SplitByBrightness, 0.9, 1.0))
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalHysteresisGapToleranceAndMinLength(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminantLowerThreshold = 0.4
+	options.IntervalDeterminantUpperThreshold = 0.8
+	options.IntervalHysteresis = true
+	options.IntervalStayLowerThreshold = 0.2
+	options.IntervalStayUpperThreshold = 0.9
+	options.IntervalGapTolerance = 3
+	options.IntervalMinLength = 4
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalLengthMapSource(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 30
+	options.IntervalLengthSource = IntervalLengthMap
+	options.Angle = 30
+
+	auxiliary := &AuxiliaryImages{
+		IntervalLengthMapImage: mockTestBlackAndWhiteStripesImage(),
+	}
+
+	sorter, err := CreateBufferedSorterWithAuxiliaryImages(mockTestBlackAndWhiteStripesImage(), nil, auxiliary, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalLengthMapSourceShouldFailWithoutLengthMap(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 30
+	options.IntervalLengthSource = IntervalLengthMap
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalLengthNormalDistribution(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 30
+	options.IntervalLengthDistribution = DistributionNormal
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalLengthExponentialDistribution(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 30
+	options.IntervalLengthDistribution = DistributionExponential
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalLengthLogNormalDistribution(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 30
+	options.IntervalLengthDistribution = DistributionLogNormal
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalLengthPowerLawDistribution(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 30
+	options.IntervalLengthDistribution = DistributionPowerLaw
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndClusterIntervalDeterminant(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByClusters
+	options.ClusterCount = 3
+	options.ClusterIndices = []int{0, 1}
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalPaintingReverse(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalReverse
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalPaintingMirror(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalMirror
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalPaintingMedian(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalMedian
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalPaintingSmear(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalSmear
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalPaintingGradientStopsInOklab(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalGradient
+	options.GradientStops = 5
+	options.GradientColorSpace = GradientOklab
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalPaintingGradientInHsl(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalGradient
+	options.GradientColorSpace = GradientHsl
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalPaintingQuantize(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalQuantize
+	options.QuantizeLevels = 3
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndIntervalPaintingQuantizeWithPaletteAndDithering(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalQuantize
+	options.QuantizePalette = color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{128, 128, 128, 255}, color.RGBA{255, 255, 255, 255}}
+	options.QuantizeDithering = DitheringBayer8x8
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndSortStrength(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.SortStrength = 0.5
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndSortStrengthWithMaskAndCycles(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.SortStrength = 0.25
+	options.UseMask = true
+	options.Cycles = 3
+	options.SortDirection = SortRandom
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), mockTestBlackAndWhiteStripesImage(), nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndChannelSplitRgb(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.ChannelSplit = ChannelSplitRgb
+	options.ChannelOptions[ChannelRed].AngleOffset = 15
+	options.ChannelOptions[ChannelBlue] = ChannelOptions{LowerThreshold: 0.2, UpperThreshold: 0.8, AngleOffset: -30}
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndChannelSplitRgbaWithSharedDeterminantAndScale(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.ChannelSplit = ChannelSplitRgba
+	options.ChannelSplitDeterminant = ChannelSortBySharedDeterminant
+	options.ChannelOptions[ChannelGreen].AngleOffset = 45
+	options.Scale = 0.5
+	options.UseMask = true
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), mockTestBlackAndWhiteStripesImage(), nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterCycleScheduleAndChannelSplitWithScaleShouldSortTheSameAsDefaultSorter(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	scheduleOptions := GetDefaultSorterOptions()
+	scheduleOptions.Cycles = 2
+	scheduleOptions.CycleSchedule.Angle = ParameterSchedule{Step: 15}
+	scheduleOptions.Scale = 0.5
+
+	channelSplitOptions := GetDefaultSorterOptions()
+	channelSplitOptions.ChannelSplit = ChannelSplitRgb
+	channelSplitOptions.ChannelOptions[ChannelRed].AngleOffset = 15
+	channelSplitOptions.Scale = 0.5
+
+	for _, options := range []*SorterOptions{scheduleOptions, channelSplitOptions} {
+		defaultSorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+		assert.NotNil(t, defaultSorter)
+		assert.Nil(t, err)
+
+		expected, err := defaultSorter.Sort()
+
+		assert.NotNil(t, expected)
+		assert.Nil(t, err)
+
+		bufferedSorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+		assert.NotNil(t, bufferedSorter)
+		assert.Nil(t, err)
+
+		for i := 0; i < 2; i += 1 {
+			actual, err := bufferedSorter.Sort(options)
+
+			assert.Nil(t, err)
+			assert.Equal(t, expected, actual)
+		}
+	}
+}
+
+func TestBufferedSorterDefaultOptionsAndDirectionAlternateStrips(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.DirectionScheme = DirectionAlternateStrips
+	options.SortDirection = SortDescending
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndDirectionAlternateIntervalsAndAngle(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.DirectionScheme = DirectionAlternateIntervals
+	options.IntervalLength = 20
+	options.Angle = 45
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndDirectionByNoise(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.DirectionScheme = DirectionByNoise
+	options.NoiseSeed = 7
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndDirectionByMap(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.DirectionScheme = DirectionByMap
+	options.Scale = 0.5
+
+	auxiliary := &AuxiliaryImages{
+		DirectionMapImage: mockTestBlackAndWhiteStripesImage(),
+	}
+
+	sorter, err := CreateBufferedSorterWithAuxiliaryImages(mockTestBlackAndWhiteStripesImage(), nil, auxiliary, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndDirectionByBrightness(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.DirectionScheme = DirectionByBrightness
+	options.DirectionBrightnessThreshold = 0.3
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndSeedShouldProduceReproducibleResults(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	seed := int64(42)
+
+	options := GetDefaultSorterOptions()
+	options.Seed = &seed
+	options.SortDirection = SortRandom
+	options.IntervalLength = 20
+	options.IntervalLengthDistribution = DistributionExponential
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	first, err := sorter.Sort(options)
+	assert.NotNil(t, first)
+	assert.Nil(t, err)
+
+	second, err := sorter.Sort(options)
+	assert.NotNil(t, second)
+	assert.Nil(t, err)
+
+	assert.Equal(t, first, second)
+}
+
+func TestBufferedSorterDefaultOptionsAndCycleScheduleAngleStepAndAlternatingOrder(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.Cycles = 3
+	options.CycleSchedule.Angle = ParameterSchedule{Step: 15}
+	options.CycleSchedule.SortOrder = []SortOrder{SortHorizontal, SortVertical}
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndCycleScheduleAndScaleAndMask(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.Cycles = 2
+	options.Scale = 0.5
+	options.UseMask = true
+	options.CycleSchedule.UpperThreshold = ParameterSchedule{Values: []float64{0.9, 0.6}}
+	options.CycleSchedule.IntervalLength = ParameterSchedule{Step: 10}
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), mockTestBlackAndWhiteStripesImage(), nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndAngleCompositeLighten(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.CompositeAngles = []int{0, 60, 120}
+	options.AngleComposite = AngleCompositeLighten
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndAngleCompositeMaxWeightWithScaleAndMemoryBudget(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.CompositeAngles = []int{0, 45, 90}
+	options.AngleComposite = AngleCompositeMaxWeight
+	options.Scale = 0.5
+	options.CompositeMemoryBudget = 1
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndAngleCompositeAverageWithCycleSchedule(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.CompositeAngles = []int{0, 90}
+	options.AngleComposite = AngleCompositeAverage
+	options.Cycles = 2
+	options.CycleSchedule.Angle = ParameterSchedule{Step: 15}
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndBlendingModeSoftLightWithOpacity(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.Blending = BlendingSoftLight
+	options.BlendingOpacity = 0.5
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), nil, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndBlendingModeLuminosityWithOpacityImageAndScale(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.Blending = BlendingLuminosity
+	options.Scale = 0.5
+	options.Angle = 45
+
+	auxiliary := &AuxiliaryImages{
+		BlendingOpacityImage: mockTestBlackAndWhiteStripesImage(),
+	}
+
+	sorter, err := CreateBufferedSorterWithAuxiliaryImages(mockTestBlackAndWhiteStripesImage(), nil, auxiliary, nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndSoftMaskWithFeatherRadiusAndScale(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.UseMask = true
+	options.SoftMask = true
+	options.MaskFeatherRadius = 2
+	options.MaskThreshold = 32
+	options.Scale = 0.5
+	options.Angle = 30
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), mockTestBlackAndWhiteStripesImage(), nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestBufferedSorterDefaultOptionsAndSoftMaskWithFeatherRadiusAndCompositeAngles(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.UseMask = true
+	options.SoftMask = true
+	options.MaskFeatherRadius = 1.5
+	options.CompositeAngles = []int{0, 90}
+
+	sorter, err := CreateBufferedSorter(mockTestBlackAndWhiteStripesImage(), mockTestBlackAndWhiteStripesImage(), nil)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort(options)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+	result, err = sorter.Sort(options)
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
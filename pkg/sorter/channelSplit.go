@@ -0,0 +1,141 @@
+package sorter
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
+)
+
+// Flag representing the color channels which are treated as separate planes and sorted independently
+type ChannelSplitMode int
+
+const (
+	ChannelSplitNone ChannelSplitMode = iota
+	ChannelSplitRgb
+	ChannelSplitRgba
+)
+
+// Flag representing the source of the sort weights and interval determinant values of the separately sorted color channel planes
+type ChannelSplitDeterminant int
+
+const (
+	ChannelSortByOwnValue ChannelSplitDeterminant = iota
+	ChannelSortBySharedDeterminant
+)
+
+const (
+	ChannelRed int = iota
+	ChannelGreen
+	ChannelBlue
+	ChannelAlpha
+)
+
+// Structure representing the parameters of a single color channel plane used by the channel split sorting
+type ChannelOptions struct {
+	LowerThreshold float64
+	UpperThreshold float64
+	AngleOffset    int
+}
+
+// Get the count of the color channel planes sorted according to the channel split mode
+func (mode ChannelSplitMode) channelCount() int {
+	switch mode {
+	case ChannelSplitNone:
+		return 0
+	case ChannelSplitRgb:
+		return 3
+	case ChannelSplitRgba:
+		return 4
+	default:
+		panic("sorter: undefined channel split mode specified")
+	}
+}
+
+// Create a copy of the sorter options used to sort the plane of the given color channel. The plane options are using the thresholds and
+// the angle offset of the channel. The planes sorted by their own value are sorted by the red channel of the grayscale plane image.
+func (options *SorterOptions) createChannelPlaneOptions(channel int) *SorterOptions {
+	planeOptions := *options
+	planeOptions.ChannelSplit = ChannelSplitNone
+	planeOptions.Angle = options.Angle + options.ChannelOptions[channel].AngleOffset
+	planeOptions.IntervalDeterminantLowerThreshold = options.ChannelOptions[channel].LowerThreshold
+	planeOptions.IntervalDeterminantUpperThreshold = options.ChannelOptions[channel].UpperThreshold
+
+	switch options.ChannelSplitDeterminant {
+	case ChannelSortByOwnValue:
+		planeOptions.SortDeterminant = SortByRedChannel
+	case ChannelSortBySharedDeterminant:
+		break
+	default:
+		panic("sorter: undefined channel split determinant specified")
+	}
+
+	return &planeOptions
+}
+
+// Function used to sort the color channels of the given image as separate planes and recombine them. Each plane is sorted by the provided
+// sort function using the plane options of the channel, so the whole scaling and rotation pipeline is performed per plane. The planes sorted
+// by their own value are grayscale images of the channel values, while the planes sorted by the shared determinant are the full color images
+// from which only the given channel is taken. The channels which are not split are taken from the scaled image. The function returns the
+// recombined image and the scaled image.
+func performChannelSplitSort(img *image.NRGBA, options *SorterOptions, sort func(plane *image.NRGBA, planeOptions *SorterOptions) (*image.NRGBA, error)) (*image.NRGBA, *image.NRGBA, error) {
+	var (
+		scaled *image.NRGBA = img
+		err    error
+	)
+
+	if options.Scale != 1.0 {
+		if scaled, err = utils.ScaleImageNrgba(img, options.Scale); err != nil {
+			return nil, nil, fmt.Errorf("sorter: failed to scale the target image: %w", err)
+		}
+	}
+
+	result := utils.GetImageCopyNrgba(scaled)
+
+	for channel := 0; channel < options.ChannelSplit.channelCount(); channel += 1 {
+		plane := img
+		planeChannel := channel
+
+		if options.ChannelSplitDeterminant == ChannelSortByOwnValue {
+			plane = createChannelPlaneImage(img, channel)
+			planeChannel = ChannelRed
+		}
+
+		sortedPlane, err := sort(plane, options.createChannelPlaneOptions(channel))
+		if err != nil {
+			return nil, nil, fmt.Errorf("sorter: failed to sort the color channel plane: %w", err)
+		}
+
+		if sortedPlane.Bounds().Size() != result.Bounds().Size() {
+			return nil, nil, fmt.Errorf("sorter: the sorted color channel plane bounds are not matching the image bounds")
+		}
+
+		for index := 0; index < len(result.Pix); index += 4 {
+			result.Pix[index+channel] = sortedPlane.Pix[index+planeChannel]
+		}
+	}
+
+	return result, scaled, nil
+}
+
+// Create the grayscale image representing the values of the given color channel of the image. The alpha of the color channel planes is
+// copied from the image, so the transparent pixels are still splitting the intervals. The alpha channel plane is fully opaque.
+func createChannelPlaneImage(img *image.NRGBA, channel int) *image.NRGBA {
+	plane := image.NewNRGBA(img.Bounds())
+
+	for index := 0; index < len(img.Pix); index += 4 {
+		value := img.Pix[index+channel]
+
+		plane.Pix[index+0] = value
+		plane.Pix[index+1] = value
+		plane.Pix[index+2] = value
+
+		if channel == ChannelAlpha {
+			plane.Pix[index+3] = 0xff
+		} else {
+			plane.Pix[index+3] = img.Pix[index+3]
+		}
+	}
+
+	return plane
+}
@@ -0,0 +1,99 @@
+package sorter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateChannelPlaneImageShouldCreateGrayscalePlane(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{10, 20, 30, 40})
+
+	assert.Equal(t, color.NRGBA{10, 10, 10, 40}, createChannelPlaneImage(img, ChannelRed).NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{20, 20, 20, 40}, createChannelPlaneImage(img, ChannelGreen).NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{30, 30, 30, 40}, createChannelPlaneImage(img, ChannelBlue).NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{40, 40, 40, 255}, createChannelPlaneImage(img, ChannelAlpha).NRGBAAt(0, 0))
+}
+
+func TestCreateChannelPlaneOptionsShouldUseChannelParameters(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.SortDeterminant = SortByHue
+	options.ChannelSplit = ChannelSplitRgb
+	options.Angle = 45
+	options.ChannelOptions[ChannelGreen] = ChannelOptions{LowerThreshold: 0.2, UpperThreshold: 0.7, AngleOffset: 10}
+
+	planeOptions := options.createChannelPlaneOptions(ChannelGreen)
+
+	assert.Equal(t, ChannelSplitNone, planeOptions.ChannelSplit)
+	assert.Equal(t, 55, planeOptions.Angle)
+	assert.Equal(t, 0.2, planeOptions.IntervalDeterminantLowerThreshold)
+	assert.Equal(t, 0.7, planeOptions.IntervalDeterminantUpperThreshold)
+	assert.Equal(t, SortByRedChannel, planeOptions.SortDeterminant)
+
+	options.ChannelSplitDeterminant = ChannelSortBySharedDeterminant
+
+	assert.Equal(t, SortByHue, options.createChannelPlaneOptions(ChannelGreen).SortDeterminant)
+	assert.Equal(t, ChannelSplitRgb, options.ChannelSplit)
+}
+
+func TestPerformChannelSplitSortShouldRecombineSortedPlanes(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{10, 20, 30, 255})
+	img.SetNRGBA(1, 0, color.NRGBA{40, 50, 60, 128})
+
+	options := GetDefaultSorterOptions()
+	options.ChannelSplit = ChannelSplitRgb
+
+	calls := 0
+	result, scaled, err := performChannelSplitSort(img, options, func(plane *image.NRGBA, planeOptions *SorterOptions) (*image.NRGBA, error) {
+		calls += 1
+
+		// NOTE: Only the green plane is reversed
+		sorted := image.NewNRGBA(plane.Bounds())
+		copy(sorted.Pix, plane.Pix)
+		if plane.Pix[0] == 20 {
+			copy(sorted.Pix[0:4], plane.Pix[4:8])
+			copy(sorted.Pix[4:8], plane.Pix[0:4])
+		}
+
+		return sorted, nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, img, scaled)
+	assert.Equal(t, color.NRGBA{10, 50, 30, 255}, result.NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{40, 20, 60, 128}, result.NRGBAAt(1, 0))
+}
+
+func TestPerformChannelSplitSortShouldTakeChannelsFromSharedDeterminantPlanes(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{10, 20, 30, 255})
+	img.SetNRGBA(1, 0, color.NRGBA{40, 50, 60, 255})
+
+	options := GetDefaultSorterOptions()
+	options.ChannelSplit = ChannelSplitRgba
+	options.ChannelSplitDeterminant = ChannelSortBySharedDeterminant
+	options.ChannelOptions[ChannelBlue].AngleOffset = 90
+
+	result, _, err := performChannelSplitSort(img, options, func(plane *image.NRGBA, planeOptions *SorterOptions) (*image.NRGBA, error) {
+		assert.Equal(t, img, plane)
+
+		// NOTE: Only the plane with the angle offset is reversed
+		sorted := image.NewNRGBA(plane.Bounds())
+		copy(sorted.Pix, plane.Pix)
+		if planeOptions.Angle == 90 {
+			copy(sorted.Pix[0:4], plane.Pix[4:8])
+			copy(sorted.Pix[4:8], plane.Pix[0:4])
+		}
+
+		return sorted, nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, color.NRGBA{10, 20, 60, 255}, result.NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{40, 50, 30, 255}, result.NRGBAAt(1, 0))
+}
@@ -0,0 +1,281 @@
+package sorter
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
+)
+
+const (
+	clusterSampleLimit   = 65536
+	clusterMaxIterations = 32
+	clusterMaxCount      = 255
+)
+
+// Structure representing the result of the k-means color clustering of a image. The labels are the cluster indices of the image pixels
+// and the palette contains the mean colors of the clusters. The transparent pixels are labeled with the index equal to the clusters count.
+type colorClusters struct {
+	labels  []uint8
+	palette color.Palette
+	width   int
+	height  int
+}
+
+// Create a palette-indexed image representing the cluster labels of the given image calculated according to the clustering related options.
+// The palette of the image contains the mean colors of the clusters and the transparent color used for the transparent pixels. The image
+// can be used to inspect the clusters and pick the cluster indices used by the SplitByClusters interval determinant. The sorters are fitting
+// the clusters on the same original image, so the indices are matching regardless of the scale and angle.
+func CreateClusterLabelImage(img image.Image, options *SorterOptions) (*image.Paletted, error) {
+	if img == nil {
+		return nil, errors.New("sorter: can not create the cluster label image for the provided nil image")
+	}
+
+	if options == nil {
+		options = GetDefaultSorterOptions()
+	}
+
+	if valid, msg := options.AreValid(); !valid {
+		return nil, fmt.Errorf("sorter: %s", msg)
+	}
+
+	if options.ClusterCount < 2 || options.ClusterCount > clusterMaxCount {
+		return nil, fmt.Errorf("sorter: the clusters count must be between values 2 and %d", clusterMaxCount)
+	}
+
+	clusters := calculateColorClusters(utils.ImageToNrgbaImage(img), options.ClusterCount, options.ClusterSeed)
+
+	return clusters.toPalettedImage(), nil
+}
+
+// Function used to cluster the colors of the given image into the given count of clusters using the seeded k-means algorithm performed in
+// the Oklab color space. The centroids are fitted on a random sample of the opaque pixels and all pixels are labeled with the nearest one.
+func calculateColorClusters(img *image.NRGBA, count int, seed int64) *colorClusters {
+	return labelColorClusters(img, fitColorClusterCentroids(img, count, seed))
+}
+
+// Function used to fit the given count of cluster centroids to the colors of the given image using the seeded k-means algorithm performed
+// in the Oklab color space. The sorters are fitting the centroids on the original image and labeling the scaled and rotated image with them,
+// so the cluster indices are matching the cluster label image regardless of the scale and angle.
+func fitColorClusterCentroids(img *image.NRGBA, count int, seed int64) [][3]float64 {
+	var (
+		width  int        = img.Bounds().Dx()
+		height int        = img.Bounds().Dy()
+		random *rand.Rand = rand.New(rand.NewSource(seed))
+		points [][3]float64
+	)
+
+	for y := 0; y < height; y += 1 {
+		for x := 0; x < width; x += 1 {
+			c := nrgbaPixelAt(img, x, y)
+			if c.A < 255 {
+				continue
+			}
+
+			l, a, b := utils.RgbaToOklab(c)
+			points = append(points, [3]float64{l, a, b})
+		}
+	}
+
+	if len(points) > clusterSampleLimit {
+		sample := make([][3]float64, clusterSampleLimit)
+		for index := range sample {
+			sample[index] = points[random.Intn(len(points))]
+		}
+
+		points = sample
+	}
+
+	centroids := initializeClusterCentroids(points, count, random)
+	assignments := make([]int, len(points))
+
+	for iteration := 0; iteration < clusterMaxIterations && len(points) > 0; iteration += 1 {
+		changed := false
+		for index, point := range points {
+			nearest := findNearestClusterCentroid(centroids, point)
+			if nearest != assignments[index] || iteration == 0 {
+				assignments[index] = nearest
+				changed = true
+			}
+		}
+
+		if !changed {
+			break
+		}
+
+		sums := make([][4]float64, count)
+		for index, point := range points {
+			sum := &sums[assignments[index]]
+			sum[0], sum[1], sum[2], sum[3] = sum[0]+point[0], sum[1]+point[1], sum[2]+point[2], sum[3]+1
+		}
+
+		for index, sum := range sums {
+			// NOTE: The centroids of the empty clusters are kept in place
+			if sum[3] == 0 {
+				continue
+			}
+
+			centroids[index] = [3]float64{sum[0] / sum[3], sum[1] / sum[3], sum[2] / sum[3]}
+		}
+	}
+
+	return centroids
+}
+
+// Function used to fit the color cluster centroids to the given original image if the options are using the clusters interval determinant
+func createClusterCentroids(img *image.NRGBA, options *SorterOptions) [][3]float64 {
+	if options.IntervalDeterminant != SplitByClusters {
+		return nil
+	}
+
+	return fitColorClusterCentroids(img, options.ClusterCount, options.ClusterSeed)
+}
+
+// Function used to pick the initial cluster centroids from the given points using the k-means++ method
+func initializeClusterCentroids(points [][3]float64, count int, random *rand.Rand) [][3]float64 {
+	centroids := make([][3]float64, 0, count)
+	if len(points) == 0 {
+		return append(centroids, make([][3]float64, count)...)
+	}
+
+	centroids = append(centroids, points[random.Intn(len(points))])
+	distances := make([]float64, len(points))
+
+	for len(centroids) < count {
+		total := 0.0
+		for index, point := range points {
+			distances[index] = clusterDistance(centroids[findNearestClusterCentroid(centroids, point)], point)
+			total += distances[index]
+		}
+
+		// NOTE: All points are already covered by the centroids, so the remaining centroids are duplicated
+		if total == 0 {
+			centroids = append(centroids, points[random.Intn(len(points))])
+			continue
+		}
+
+		target := random.Float64() * total
+		selected := len(points) - 1
+		for index, distance := range distances {
+			target -= distance
+			if target <= 0 {
+				selected = index
+				break
+			}
+		}
+
+		centroids = append(centroids, points[selected])
+	}
+
+	return centroids
+}
+
+// Function used to label all pixels of the given image with the index of the nearest centroid and calculate the mean colors of the clusters
+func labelColorClusters(img *image.NRGBA, centroids [][3]float64) *colorClusters {
+	var (
+		width    int = img.Bounds().Dx()
+		height   int = img.Bounds().Dy()
+		count    int = len(centroids)
+		clusters     = &colorClusters{
+			labels:  make([]uint8, width*height),
+			palette: make(color.Palette, 0, count+1),
+			width:   width,
+			height:  height,
+		}
+		sums      = make([][4]int, count)
+		sumsMutex = &sync.Mutex{}
+	)
+
+	wg := &sync.WaitGroup{}
+	for y := 0; y < height; y += 1 {
+		wg.Add(1)
+		go func(yIndex int) {
+			defer wg.Done()
+
+			rowSums := make([][4]int, count)
+			for x := 0; x < width; x += 1 {
+				c := nrgbaPixelAt(img, x, yIndex)
+				if c.A < 255 {
+					clusters.labels[yIndex*width+x] = uint8(count)
+					continue
+				}
+
+				l, a, b := utils.RgbaToOklab(c)
+				label := findNearestClusterCentroid(centroids, [3]float64{l, a, b})
+				clusters.labels[yIndex*width+x] = uint8(label)
+
+				sum := &rowSums[label]
+				sum[0], sum[1], sum[2], sum[3] = sum[0]+int(c.R), sum[1]+int(c.G), sum[2]+int(c.B), sum[3]+1
+			}
+
+			sumsMutex.Lock()
+			defer sumsMutex.Unlock()
+
+			for index := range rowSums {
+				for channel := range rowSums[index] {
+					sums[index][channel] += rowSums[index][channel]
+				}
+			}
+		}(y)
+	}
+
+	wg.Wait()
+
+	for _, sum := range sums {
+		if sum[3] == 0 {
+			clusters.palette = append(clusters.palette, color.RGBA{0, 0, 0, 255})
+			continue
+		}
+
+		clusters.palette = append(clusters.palette, color.RGBA{
+			R: uint8(sum[0] / sum[3]),
+			G: uint8(sum[1] / sum[3]),
+			B: uint8(sum[2] / sum[3]),
+			A: 255,
+		})
+	}
+
+	clusters.palette = append(clusters.palette, color.RGBA{0, 0, 0, 0})
+	return clusters
+}
+
+// Create a palette-indexed image representing the cluster labels
+func (clusters *colorClusters) toPalettedImage() *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, clusters.width, clusters.height), clusters.palette)
+	copy(img.Pix, clusters.labels)
+
+	return img
+}
+
+// Function used to find the index of the centroid nearest to the given point
+func findNearestClusterCentroid(centroids [][3]float64, point [3]float64) int {
+	var (
+		nearest     int     = 0
+		minDistance float64 = math.Inf(1)
+	)
+
+	for index, centroid := range centroids {
+		if distance := clusterDistance(centroid, point); distance < minDistance {
+			nearest, minDistance = index, distance
+		}
+	}
+
+	return nearest
+}
+
+// Function used to calculate the squared euclidean distance between two points
+func clusterDistance(a, b [3]float64) float64 {
+	return (a[0]-b[0])*(a[0]-b[0]) + (a[1]-b[1])*(a[1]-b[1]) + (a[2]-b[2])*(a[2]-b[2])
+}
+
+// Function used to read the pixel of the given NRGBA image at the given coordinates relative to the image bounds
+func nrgbaPixelAt(img *image.NRGBA, x, y int) color.RGBA {
+	index := y*img.Stride + x*4
+
+	return color.RGBA{img.Pix[index+0], img.Pix[index+1], img.Pix[index+2], img.Pix[index+3]}
+}
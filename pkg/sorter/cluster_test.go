@@ -0,0 +1,108 @@
+package sorter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateColorClustersShouldSeparateDistinctColors(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	colors := []color.NRGBA{
+		{250, 10, 10, 255}, {245, 15, 5, 255}, {10, 10, 250, 255}, {5, 15, 245, 255},
+		{10, 10, 250, 255}, {250, 10, 10, 255}, {10, 240, 10, 255}, {0, 0, 0, 0},
+	}
+
+	for index, c := range colors {
+		img.SetNRGBA(index%4, index/4, c)
+	}
+
+	clusters := calculateColorClusters(img, 3, 1)
+
+	assert.Len(t, clusters.labels, 8)
+	assert.Len(t, clusters.palette, 4)
+
+	assert.Equal(t, clusters.labels[0], clusters.labels[1])
+	assert.Equal(t, clusters.labels[0], clusters.labels[5])
+	assert.Equal(t, clusters.labels[2], clusters.labels[3])
+	assert.Equal(t, clusters.labels[2], clusters.labels[4])
+	assert.NotEqual(t, clusters.labels[0], clusters.labels[2])
+	assert.NotEqual(t, clusters.labels[0], clusters.labels[6])
+	assert.NotEqual(t, clusters.labels[2], clusters.labels[6])
+	assert.Equal(t, uint8(3), clusters.labels[7])
+}
+
+func TestCalculateColorClustersShouldBeDeterministicForTheSameSeed(t *testing.T) {
+	img := utils.ImageToNrgbaImage(mockTestBlackAndWhiteStripesImage())
+
+	a := calculateColorClusters(img, 4, 7)
+	b := calculateColorClusters(img, 4, 7)
+
+	assert.Equal(t, a, b)
+}
+
+func TestCalculateColorClustersShouldHandleFewerColorsThanClusters(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	for index := range img.Pix {
+		img.Pix[index] = 255
+	}
+
+	clusters := calculateColorClusters(img, 5, 0)
+
+	assert.Len(t, clusters.palette, 6)
+	for _, label := range clusters.labels {
+		assert.Less(t, label, uint8(5))
+	}
+}
+
+func TestCreateClusterLabelImageShouldCreatePalettedImage(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.ClusterCount = 2
+
+	img, err := CreateClusterLabelImage(mockTestBlackAndWhiteStripesImage(), options)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, img)
+	assert.Len(t, img.Palette, 3)
+	assert.Equal(t, mockTestBlackAndWhiteStripesImage().Bounds(), img.Bounds())
+}
+
+func TestCreateClusterLabelImageShouldNotCreateForNilImage(t *testing.T) {
+	img, err := CreateClusterLabelImage(nil, nil)
+
+	assert.Nil(t, img)
+	assert.NotNil(t, err)
+}
+
+func TestCreateClusterLabelImageShouldNotCreateForInvalidClusterCount(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.ClusterCount = 1
+
+	img, err := CreateClusterLabelImage(mockTestBlackAndWhiteStripesImage(), options)
+
+	assert.Nil(t, img)
+	assert.NotNil(t, err)
+}
+
+func TestSorterShouldFitClusterCentroidsOnTheOriginalImage(t *testing.T) {
+	img := createMockTestBlackAndWhiteStripesImage(40, 40)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByClusters
+	options.ClusterCount = 3
+	options.ClusterSeed = 5
+	options.Scale = 0.5
+	options.Angle = 30
+
+	s, err := CreateSorter(img, nil, nil, options)
+	assert.Nil(t, err)
+
+	_, err = s.Sort()
+	assert.Nil(t, err)
+
+	expected := fitColorClusterCentroids(utils.ImageToNrgbaImage(img), options.ClusterCount, options.ClusterSeed)
+	assert.Equal(t, expected, s.(*defaultSorter).clusterCentroids)
+}
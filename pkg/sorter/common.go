@@ -5,13 +5,15 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"math"
+	"math/rand"
 	"sync"
 
 	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
 )
 
 // Function used to iterate over image rows in parallel and invoking the image strip sorting on each row.
-func performParallelRowSorting(src, dst *image.RGBA, mask Mask, options *SorterOptions, ctx context.Context) error {
+func performParallelRowSorting(src, dst *image.RGBA, sources *sortingSources, options *SorterOptions, cycle int, ctx context.Context) error {
 	width := src.Bounds().Dx()
 	height := src.Bounds().Dy()
 
@@ -27,7 +29,7 @@ func performParallelRowSorting(src, dst *image.RGBA, mask Mask, options *SorterO
 				return
 			}
 
-			if err := performImageStripSort(src, dst, mask, options, 4*yIndex*width, 4*1, width, ctx); err != nil {
+			if err := performImageStripSort(src, dst, sources, options, cycle, 4*yIndex*width, 4*1, width, ctx); err != nil {
 				errt.Set(fmt.Errorf("sorter: failed to perform image strip sorting for row %d: %w", yIndex, err))
 				ctx.Done()
 				return
@@ -40,7 +42,7 @@ func performParallelRowSorting(src, dst *image.RGBA, mask Mask, options *SorterO
 }
 
 // Function used to iterate over image columns in paralle and ivoking the image strip sorting on each column
-func performParallelColumnSorting(src, dst *image.RGBA, mask Mask, options *SorterOptions, ctx context.Context) error {
+func performParallelColumnSorting(src, dst *image.RGBA, sources *sortingSources, options *SorterOptions, cycle int, ctx context.Context) error {
 	width := src.Bounds().Dx()
 	height := src.Bounds().Dy()
 
@@ -56,7 +58,7 @@ func performParallelColumnSorting(src, dst *image.RGBA, mask Mask, options *Sort
 				return
 			}
 
-			if err := performImageStripSort(src, dst, mask, options, 4*xIndex, 4*width, height, ctx); err != nil {
+			if err := performImageStripSort(src, dst, sources, options, cycle, 4*xIndex, 4*width, height, ctx); err != nil {
 				errt.Set(fmt.Errorf("sorter: failed to perform image strip sorting for column %d: %w", xIndex, err))
 				ctx.Done()
 				return
@@ -72,21 +74,29 @@ func performParallelColumnSorting(src, dst *image.RGBA, mask Mask, options *Sort
 // that the iteration is one-dimensional, we accept the start index and the iteration step size. The number of iteration steps is defined by the count. The
 // function iterates over the strip and checks whether the interval requirements are met. If yes, they are appended to the interval, if not, they are
 // written straight to the destination image. The intervals are also sorted and drawn into the image under some specific conditions.
-func performImageStripSort(src, dst *image.RGBA, mask Mask, options *SorterOptions, start, step, count int, ctx context.Context) error {
+func performImageStripSort(src, dst *image.RGBA, sources *sortingSources, options *SorterOptions, cycle, start, step, count int, ctx context.Context) error {
 	var (
-		buffer                     []color.RGBA = make([]color.RGBA, 0, count)
-		interval                   Interval     = CreateInterval(options.SortDeterminant)
-		intervalLength             int          = options.IntervalLength
-		intervalLengthRandomFactor int          = options.IntervalLengthRandomFactor
-		lowerThreshold             float64      = options.IntervalDeterminantLowerThreshold
-		upperThreshold             float64      = options.IntervalDeterminantUpperThreshold
-		intervalMaxLength          int          = calculateMaxIntervalLength(intervalLength, intervalLengthRandomFactor)
+		buffer            []color.RGBA      = make([]color.RGBA, 0, count)
+		interval          Interval          = CreateInterval(options.SortDeterminant)
+		condition         intervalCondition = createIntervalCondition(options)
+		stayCondition     intervalCondition = createIntervalStayCondition(options)
+		direction         *stripDirection   = createStripDirection(src, options, start, step)
+		intervalMaxLength int               = 0
+		gapLength         int               = 0
 	)
 
+	interval.SetGradient(options.GradientStops, options.GradientColorSpace)
+	interval.SetSortStrength(options.SortStrength)
+
+	random := createStripRandom(options.getSeed(), cycle, start, step)
+	interval.SetRandom(random)
+
 	var (
-		currentColor color.RGBA
-		isMasked     bool
-		err          error
+		currentColor      color.RGBA
+		lastIntervalColor color.RGBA
+		isMasked          bool
+		isMeeting         bool
+		err               error
 	)
 
 	for i, index := 0, start; i < count; i, index = i+1, index+step {
@@ -107,11 +117,11 @@ func performImageStripSort(src, dst *image.RGBA, mask Mask, options *SorterOptio
 		}
 
 		// NOTE: Dont pass to interval if the interval max length has been reached (Solved using K-Map)
-		if intervalMaxLength != 0 && interval.Count() >= intervalMaxLength {
+		if intervalMaxLength != 0 && interval.Count()+gapLength >= intervalMaxLength {
 			goto sortAndResetInterval
 		}
 
-		isMasked, err = mask.AtByIndexB(index / 4)
+		isMasked, err = sources.mask.AtByIndexT(index/4, options.MaskThreshold)
 		if err != nil {
 			return fmt.Errorf("sorter: failed to perform a lookup to the mask image: %w", err)
 		}
@@ -121,89 +131,348 @@ func performImageStripSort(src, dst *image.RGBA, mask Mask, options *SorterOptio
 			goto sortAndResetInterval
 		}
 
-		// NOTE: Dont pass to interval if the interval determinant requirements are not meet
-		if !isMeetingIntervalDeterminant(currentColor, options.IntervalDeterminant, lowerThreshold, upperThreshold, isMasked) {
+		// NOTE: The pixels extending an already started interval are checked against the stay thresholds if the hysteresis is used
+		if interval.Any() {
+			isMeeting = stayCondition.isMet(currentColor, isMasked, sources, index/4)
+		} else {
+			isMeeting = condition.isMet(currentColor, isMasked, sources, index/4)
+		}
+
+		// NOTE: Dont pass to interval if the interval determinant requirements are not meet, unless the pixel fits into the gap tolerance
+		if !isMeeting {
+			if interval.Any() && gapLength < options.IntervalGapTolerance {
+				gapLength += 1
+				continue
+			}
+
 			goto sortAndResetInterval
 		}
 
-		if err := interval.Append(currentColor); err != nil {
+		// NOTE: Start a new interval if the local difference or the cluster label change is splitting the interval from the current pixel. The
+		// current pixel is compared with the last interval pixel, so the pending gap pixels are not taken under account
+		if interval.Any() && isSplittingInterval(lastIntervalColor, currentColor, &condition, sources, (index-step*(gapLength+1))/4, index/4) {
+			drawIntervalIntoImage(src, dst, interval, &buffer, sources, options, direction, index-step*(gapLength+1), step)
+			copyPixelsIntoImage(src, dst, index-step, step, gapLength)
+			gapLength = 0
+		}
+
+		// NOTE: The pending gap pixels are surrounded by the interval pixels, so they are becoming a part of the interval
+		for gapIndex := index - step*gapLength; gapIndex < index; gapIndex += step {
+			gapColor := color.RGBA{src.Pix[gapIndex+0], src.Pix[gapIndex+1], src.Pix[gapIndex+2], src.Pix[gapIndex+3]}
+
+			if err = appendColorToInterval(interval, gapColor, sources, gapIndex/4); err != nil {
+				return fmt.Errorf("sorter: failed to append the gap color to the interval: %w", err)
+			}
+		}
+
+		gapLength = 0
+
+		// NOTE: The max length of the interval is determined by the pixel starting the interval
+		if !interval.Any() {
+			intervalMaxLength = sources.calculateMaxIntervalLength(options, random, index/4)
+		}
+
+		if err = appendColorToInterval(interval, currentColor, sources, index/4); err != nil {
 			return fmt.Errorf("sorter: failed to append the current color to the interval: %w", err)
 		}
 
+		lastIntervalColor = currentColor
 		continue
 
 	sortAndResetInterval:
 		if interval.Any() {
-			buffer = buffer[:0]
-
-			interval.SortToBuffer(options.SortDirection, options.IntervalPainting, &buffer)
-			intervalMaxLength = calculateMaxIntervalLength(intervalLength, intervalLengthRandomFactor)
-
-			drawBufferIntoImage(dst, append(buffer, currentColor), index, step)
-		} else {
-			dst.Pix[index+0] = currentColor.R
-			dst.Pix[index+1] = currentColor.G
-			dst.Pix[index+2] = currentColor.B
-			dst.Pix[index+3] = currentColor.A
+			drawIntervalIntoImage(src, dst, interval, &buffer, sources, options, direction, index-step*(gapLength+1), step)
 		}
+
+		copyPixelsIntoImage(src, dst, index, step, gapLength+1)
+		gapLength = 0
 	}
 
-	// TODO: Incorporate this statement into the sortAndresetInterval label procedure
+	end := start + step*(count-1)
 	if interval.Any() {
-		buffer = buffer[:0]
+		drawIntervalIntoImage(src, dst, interval, &buffer, sources, options, direction, end-step*gapLength, step)
+	}
+
+	copyPixelsIntoImage(src, dst, end, step, gapLength)
 
-		interval.SortToBuffer(options.SortDirection, options.IntervalPainting, &buffer)
+	return nil
+}
 
-		drawBufferIntoImage(dst, buffer, start+step*(count-1), step)
+// Function used to append the given color to the interval. The weight of the color is taken from the sort weights source if it is used.
+func appendColorToInterval(interval Interval, c color.RGBA, sources *sortingSources, pixelIndex int) error {
+	if sources.sortWeights != nil {
+		return interval.AppendWithWeight(c, sources.sortWeights[pixelIndex])
 	}
 
-	return nil
+	return interval.Append(c)
+}
+
+// Function used to sort the interval and draw it into the destination image. The buffer is used as the intermediate storage of the sorted colors.
+// The target position is determined by the iteration index and step value. The specified index is the ending index. The intervals shorter than
+// the min interval length are not sorted and the source pixels are copied to the destination image instead. The sort direction of the interval
+// is resolved by the direction scheme of the strip. The sorted colors are quantized by the quantizer of the sorting sources if the quantize
+// interval painting is used.
+func drawIntervalIntoImage(src, dst *image.RGBA, interval Interval, buffer *[]color.RGBA, sources *sortingSources, options *SorterOptions, direction *stripDirection, index, step int) {
+	if interval.Count() < options.IntervalMinLength {
+		copyPixelsIntoImage(src, dst, index, step, interval.Count())
+		interval.Reset()
+		return
+	}
+
+	*buffer = (*buffer)[:0]
+
+	interval.SortToBuffer(direction.next(src, sources, index, step, interval.Count()), options.IntervalPainting, buffer)
+
+	if sources.quantizer != nil {
+		sources.quantizer.quantizeBuffer(dst, *buffer, index, step)
+	}
+
+	drawBufferIntoImage(dst, *buffer, index, step)
+}
+
+// Function used to check if the interval should be split between the last interval pixel and the current pixel. The intervals are split by
+// the local difference determinant if the difference between the adjacent pixels exceeds the threshold and by the clusters determinant if
+// the cluster label of the pixels is changing.
+func isSplittingInterval(previous, current color.RGBA, condition *intervalCondition, sources *sortingSources, previousPixelIndex, pixelIndex int) bool {
+	switch condition.determinant {
+	case SplitByLocalDifference:
+		return calculateLocalDifference(previous, current, condition.differenceMetric) > condition.differenceThreshold
+	case SplitByClusters:
+		return sources.clusters.labels[previousPixelIndex] != sources.clusters.labels[pixelIndex]
+	default:
+		return false
+	}
 }
 
-// Function used to check if the the given color is meeting the current interval determinant requirements taking the thresholds under account
-func isMeetingIntervalDeterminant(c color.RGBA, determinant IntervalDeterminant, lowerThreshold, upperThreshold float64, isMasked bool) bool {
+// Function used to calculate the perceptual difference between two adjacent colors using the given difference metric
+func calculateLocalDifference(a, b color.RGBA, metric LocalDifferenceMetric) float64 {
+	switch metric {
+	case DifferenceBrightness:
+		return math.Abs(utils.CalculatePerceivedBrightness(a) - utils.CalculatePerceivedBrightness(b))
+	case DifferenceOklab:
+		return utils.OklabDistance(a, b)
+	default:
+		panic("sorter: invalid sorter state due to a corrupted local difference metric value")
+	}
+}
+
+// Structure representing the interval determinant along with its thresholds used to check if the pixels are meeting the interval requirements.
+// The composite interval expressions are represented as a tree of conditions, where only the clause conditions are describing a determinant.
+type intervalCondition struct {
+	operator            IntervalExpressionOperator
+	operands            []intervalCondition
+	determinant         IntervalDeterminant
+	lowerThreshold      float64
+	upperThreshold      float64
+	wrapAround          bool
+	channelThresholds   ChannelThresholds
+	differenceMetric    LocalDifferenceMetric
+	differenceThreshold float64
+	clusterSelection    []bool
+}
+
+// Create the interval condition described by the interval determinant related sorter options
+func createIntervalCondition(options *SorterOptions) intervalCondition {
+	if options.IntervalDeterminant == SplitByExpression {
+		return createExpressionIntervalCondition(options.IntervalExpression, options)
+	}
+
+	return intervalCondition{
+		operator:            ExpressionClause,
+		operands:            nil,
+		determinant:         options.IntervalDeterminant,
+		lowerThreshold:      options.IntervalDeterminantLowerThreshold,
+		upperThreshold:      options.IntervalDeterminantUpperThreshold,
+		wrapAround:          options.IntervalDeterminantWrapAround,
+		channelThresholds:   options.IntervalChannelThresholds,
+		differenceMetric:    options.IntervalDifferenceMetric,
+		differenceThreshold: options.IntervalDifferenceThreshold,
+		clusterSelection:    createClusterSelection(options),
+	}
+}
+
+// Create the lookup of the cluster indices selected by the options or nil if all clusters are selected
+func createClusterSelection(options *SorterOptions) []bool {
+	if len(options.ClusterIndices) == 0 {
+		return nil
+	}
+
+	selection := make([]bool, options.ClusterCount)
+	for _, clusterIndex := range options.ClusterIndices {
+		selection[clusterIndex] = true
+	}
+
+	return selection
+}
+
+// Create the interval condition used to check if the pixels are extending a already started interval. The stay thresholds are replacing the
+// interval determinant thresholds if the hysteresis is used, otherwise the condition is the same as the one used to start the intervals.
+func createIntervalStayCondition(options *SorterOptions) intervalCondition {
+	condition := createIntervalCondition(options)
+
+	if options.IntervalHysteresis {
+		condition.lowerThreshold = options.IntervalStayLowerThreshold
+		condition.upperThreshold = options.IntervalStayUpperThreshold
+	}
+
+	return condition
+}
+
+// Create the interval condition tree described by the given composite interval expression. The clause conditions are using the channel
+// thresholds specified by the options and the hue clauses are crossing the 0/360 degrees point if the lower threshold is the greater one
+// and the hue wrap-around is used.
+func createExpressionIntervalCondition(expression *IntervalExpression, options *SorterOptions) intervalCondition {
+	condition := intervalCondition{
+		operator:          expression.Operator,
+		operands:          make([]intervalCondition, 0, len(expression.Operands)),
+		determinant:       expression.Clause.Determinant,
+		lowerThreshold:    expression.Clause.LowerThreshold,
+		upperThreshold:    expression.Clause.UpperThreshold,
+		wrapAround:        options.IntervalDeterminantWrapAround && expression.Clause.Determinant == SplitByHue,
+		channelThresholds: options.IntervalChannelThresholds,
+	}
+
+	for _, operand := range expression.Operands {
+		condition.operands = append(condition.operands, createExpressionIntervalCondition(operand, options))
+	}
+
+	return condition
+}
+
+// Function used to check if the given color at the given pixel index is meeting the requirements of the condition tree
+func (condition *intervalCondition) isMet(c color.RGBA, isMasked bool, sources *sortingSources, pixelIndex int) bool {
+	switch condition.operator {
+	case ExpressionClause:
+		{
+			return isMeetingIntervalDeterminant(c, condition, isMasked, sources.sourceValueAt(condition.determinant, pixelIndex))
+		}
+	case ExpressionAnd:
+		{
+			for index := range condition.operands {
+				if !condition.operands[index].isMet(c, isMasked, sources, pixelIndex) {
+					return false
+				}
+			}
+
+			return true
+		}
+	case ExpressionOr:
+		{
+			for index := range condition.operands {
+				if condition.operands[index].isMet(c, isMasked, sources, pixelIndex) {
+					return true
+				}
+			}
+
+			return false
+		}
+	case ExpressionNot:
+		{
+			return !condition.operands[0].isMet(c, isMasked, sources, pixelIndex)
+		}
+	default:
+		panic("sorter: invalid sorter state due to a corrupted interval expression operator value")
+	}
+}
+
+// Function used to check if the the given color is meeting the interval condition requirements taking the thresholds under account. The source
+// value is the normalized interval map or noise value at the position of the given color and is only used by the map and noise determinants.
+func isMeetingIntervalDeterminant(c color.RGBA, condition *intervalCondition, isMasked bool, sourceValue float64) bool {
+	switch condition.determinant {
+	case SplitByMask, SplitByEdgeDetection:
+		{
+			return !isMasked
+		}
+	case SplitByLocalDifference:
+		{
+			// NOTE: All pixels are meeting the requirements, the intervals are split by the strip sorting based on the adjacent pixels difference
+			return true
+		}
+	case SplitByAbsoluteColor:
+		{
+			abs, _ := calculateIntervalDeterminantValue(c, condition.determinant, sourceValue)
+
+			return abs >= condition.lowerThreshold && abs < condition.upperThreshold
+		}
+	case SplitByHue:
+		{
+			hNorm, _ := calculateIntervalDeterminantValue(c, condition.determinant, sourceValue)
+
+			// NOTE: The hue range is crossing the 0/360 degrees point, so the hue must be above the lower or below the upper threshold
+			if condition.wrapAround && condition.lowerThreshold > condition.upperThreshold {
+				return hNorm >= condition.lowerThreshold || hNorm <= condition.upperThreshold
+			}
+
+			return hNorm >= condition.lowerThreshold && hNorm <= condition.upperThreshold
+		}
+	case SplitByClusters:
+		{
+			label := int(sourceValue)
+
+			return condition.clusterSelection == nil || (label < len(condition.clusterSelection) && condition.clusterSelection[label])
+		}
+	case SplitByRgbChannels:
+		{
+			rNorm, gNorm, bNorm := utils.RgbaToNormalizedComponents(c)
+			thresholds := &condition.channelThresholds
+
+			return rNorm >= thresholds.RedLower && rNorm <= thresholds.RedUpper &&
+				gNorm >= thresholds.GreenLower && gNorm <= thresholds.GreenUpper &&
+				bNorm >= thresholds.BlueLower && bNorm <= thresholds.BlueUpper
+		}
+	default:
+		{
+			value, ok := calculateIntervalDeterminantValue(c, condition.determinant, sourceValue)
+			if !ok {
+				panic("sorter: invalid sorter state due to a corrupted interval determinant value")
+			}
+
+			return value >= condition.lowerThreshold && value <= condition.upperThreshold
+		}
+	}
+}
+
+// Function used to calculate the normalized value (0.0 - 1.0) of the given color which is compared against the thresholds by the value-based
+// interval determinants. The returned boolean value indicates if the given interval determinant is value-based.
+func calculateIntervalDeterminantValue(c color.RGBA, determinant IntervalDeterminant, sourceValue float64) (float64, bool) {
 	switch determinant {
 	case SplitByBrightness:
 		{
-			brightness := utils.CalculatePerceivedBrightness(c)
-
-			return brightness >= lowerThreshold && brightness <= upperThreshold
+			return utils.CalculatePerceivedBrightness(c), true
 		}
 	case SplitByHue:
 		{
 			h, _, _, _ := utils.RgbaToHsla(c)
-			hNorm := float64(h) / 360.0
 
-			return hNorm >= lowerThreshold && hNorm <= upperThreshold
+			return float64(h) / 360.0, true
 		}
 	case SplitBySaturation:
 		{
 			_, s, _, _ := utils.RgbaToHsla(c)
 
-			return s >= lowerThreshold && s <= upperThreshold
+			return s, true
 		}
-	case SplitByMask, SplitByEdgeDetection:
+	case SplitByAbsoluteColor:
 		{
-			return !isMasked
+			return float64(int(c.R)*int(c.G)*int(c.B)) / 16581375.0, true
 		}
-	case SplitByAbsoluteColor:
+	case SplitByMap, SplitByNoise:
 		{
-			abs := float64(int(c.R)*int(c.G)*int(c.B)) / 16581375.0
-
-			return abs >= lowerThreshold && abs < upperThreshold
+			return sourceValue, true
 		}
 	default:
-		panic("sorter: invalid sorter state due to a corrupted interval determinant value")
+		return 0, false
 	}
 }
 
-// Function used to calculate the max interval length by taking the options and randomness factor under account
-func calculateMaxIntervalLength(intervalLength, intervalLengthRandomFactor int) int {
+// Function used to calculate the max interval length by taking the options and randomness factor under account. The random value is drawn
+// from the given random source.
+func calculateMaxIntervalLength(intervalLength, intervalLengthRandomFactor int, random *rand.Rand) int {
 	if intervalLength == 0 || intervalLengthRandomFactor == 0 {
 		return intervalLength
 	}
 
-	factor := utils.CIntn(2*intervalLengthRandomFactor) - intervalLengthRandomFactor
+	factor := random.Intn(2*intervalLengthRandomFactor) - intervalLengthRandomFactor
 
 	length := intervalLength + factor
 	if length < 1 {
@@ -213,6 +482,62 @@ func calculateMaxIntervalLength(intervalLength, intervalLengthRandomFactor int)
 	}
 }
 
+// Create the random source of the strip starting at the given index and iterated with the given step during the given sorting cycle. The
+// strip random sources are seeded with a value derived from the seed, the cycle index and the strip position using the SplitMix64 mixing
+// function, so each strip of each cycle is drawing an independent sequence of random values regardless of the order in which the strips
+// are sorted.
+func createStripRandom(seed int64, cycle, start, step int) *rand.Rand {
+	hash := uint64(seed)
+	for _, value := range []int{cycle, start, step} {
+		hash += uint64(value) + 0x9e3779b97f4a7c15
+		hash = (hash ^ (hash >> 30)) * 0xbf58476d1ce4e5b9
+		hash = (hash ^ (hash >> 27)) * 0x94d049bb133111eb
+		hash = hash ^ (hash >> 31)
+	}
+
+	return rand.New(rand.NewSource(int64(hash)))
+}
+
+// Function used to copy the given count of pixels from the source image to the destination image. The target position is determined by the
+// iteration index and step value. The specified index is the ending index.
+func copyPixelsIntoImage(src, dst *image.RGBA, index, step, count int) {
+	for dstIndex, i := index, 0; i < count; dstIndex, i = dstIndex-step, i+1 {
+		copy(dst.Pix[dstIndex:dstIndex+4], src.Pix[dstIndex:dstIndex+4])
+	}
+}
+
+// Function used to calculate the max interval length by drawing a random value from the interval length distribution specified by the options.
+// The given interval length is the central value of the distribution: the mean of the normal and exponential distributions, the median of the
+// log-normal distribution and the minimum of the power-law distribution. The uniform distribution is using the interval length random factor.
+// The random values are drawn from the given random source.
+func calculateDistributedIntervalLength(intervalLength int, options *SorterOptions, random *rand.Rand) int {
+	if intervalLength == 0 {
+		return 0
+	}
+
+	var (
+		length float64 = float64(intervalLength)
+		value  float64
+	)
+
+	switch options.IntervalLengthDistribution {
+	case DistributionUniform:
+		return calculateMaxIntervalLength(intervalLength, options.IntervalLengthRandomFactor, random)
+	case DistributionNormal:
+		value = length + random.NormFloat64()*options.IntervalLengthDeviation*length
+	case DistributionExponential:
+		value = -math.Log(1.0-random.Float64()) * length
+	case DistributionLogNormal:
+		value = math.Exp(random.NormFloat64()*options.IntervalLengthDeviation) * length
+	case DistributionPowerLaw:
+		value = math.Pow(1.0-random.Float64(), -1.0/(options.IntervalLengthExponent-1.0)) * length
+	default:
+		panic("sorter: invalid sorter state due to a corrupted interval length distribution value")
+	}
+
+	return int(utils.ClampFloat64(1.0, math.Round(value), math.MaxInt32))
+}
+
 // Function used to draw a color buffer to the destination image. The target position is determined by the iteration
 // index and step value. The specified index is the ending index.
 func drawBufferIntoImage(dst *image.RGBA, buffer []color.RGBA, index, step int) {
@@ -229,3 +554,112 @@ func drawBufferIntoImage(dst *image.RGBA, buffer []color.RGBA, index, step int)
 		dst.Pix[dstIndex+3] = color.A
 	}
 }
+
+// Function used to blend the sorted image into the source image according to the blending mode and the blending opacity specified by the
+// options. The optional opacity image is representing the per-pixel opacity multiplied by the blending opacity and must match the images.
+// If the soft mask is used, the blended image is mixed with the source image according to the grayscale value of the given mask image.
+func blendSortedImage(src, dst, maskImage, opacityImage *image.NRGBA, options *SorterOptions) (*image.NRGBA, error) {
+	var err error
+
+	if options.Blending != BlendingNone {
+		if dst, err = utils.BlendImagesNrgba(src, dst, options.Blending.toBlendingMode()); err != nil {
+			return nil, fmt.Errorf("sorter: failed to perform the image blending: %w", err)
+		}
+	}
+
+	if opacityImage != nil {
+		if dst, err = utils.MixImagesNrgbaByMap(src, dst, opacityImage, options.BlendingOpacity); err != nil {
+			return nil, fmt.Errorf("sorter: failed to perform the image blending using the opacity image: %w", err)
+		}
+	} else if options.BlendingOpacity != 1.0 {
+		if dst, err = utils.MixImagesNrgba(src, dst, options.BlendingOpacity); err != nil {
+			return nil, fmt.Errorf("sorter: failed to perform the image blending using the opacity: %w", err)
+		}
+	}
+
+	if options.UseMask && options.SoftMask && maskImage != nil {
+		if dst, err = utils.MixImagesNrgbaByMap(src, dst, maskImage, 1.0); err != nil {
+			return nil, fmt.Errorf("sorter: failed to perform the image blending using the soft mask: %w", err)
+		}
+	}
+
+	return dst, nil
+}
+
+// Function used to blend the sorted image, which is scaled according to the options, into the source image, which is not scaled. The source
+// image, the mask image and the blending opacity image of the given auxiliary images are scaled to match the sorted image only if the blending
+// is performed. The mask image is feathered according to the options.
+func blendScaledSortedImage(src, dst, maskImage *image.NRGBA, auxiliary *auxiliaryImagesNrgba, options *SorterOptions) (*image.NRGBA, error) {
+	var (
+		opacityImage *image.NRGBA = auxiliary.blendingOpacityImage
+		err          error
+	)
+
+	if !options.UseMask || !options.SoftMask {
+		maskImage = nil
+	}
+
+	if options.Blending == BlendingNone && options.BlendingOpacity == 1.0 && opacityImage == nil && maskImage == nil {
+		return dst, nil
+	}
+
+	if options.Scale != 1.0 {
+		if src, err = utils.ScaleImageNrgba(src, options.Scale); err != nil {
+			return nil, fmt.Errorf("sorter: failed to scale the target image: %w", err)
+		}
+
+		if maskImage != nil {
+			if maskImage, err = utils.ScaleImageNrgba(maskImage, options.Scale); err != nil {
+				return nil, fmt.Errorf("sorter: failed to scale the target image mask: %w", err)
+			}
+		}
+
+		if opacityImage != nil {
+			if opacityImage, err = utils.ScaleImageNrgba(opacityImage, options.Scale); err != nil {
+				return nil, fmt.Errorf("sorter: failed to scale the blending opacity image: %w", err)
+			}
+		}
+	}
+
+	maskImage = featherMaskImage(maskImage, options.MaskFeatherRadius*options.Scale)
+
+	return blendSortedImage(src, dst, maskImage, opacityImage, options)
+}
+
+// Return the utility blending mode corresponding to the result image blending other than the none blending
+func (blending ResultImageBlending) toBlendingMode() utils.BlendingMode {
+	switch blending {
+	case BlendingLighten:
+		return utils.LightenOnly
+	case BlendingDarken:
+		return utils.DarkenOnly
+	case BlendingMultiply:
+		return utils.Multiply
+	case BlendingScreen:
+		return utils.Screen
+	case BlendingOverlay:
+		return utils.Overlay
+	case BlendingSoftLight:
+		return utils.SoftLight
+	case BlendingHardLight:
+		return utils.HardLight
+	case BlendingDifference:
+		return utils.Difference
+	case BlendingExclusion:
+		return utils.Exclusion
+	case BlendingColorDodge:
+		return utils.ColorDodge
+	case BlendingColorBurn:
+		return utils.ColorBurn
+	case BlendingHue:
+		return utils.Hue
+	case BlendingSaturation:
+		return utils.Saturation
+	case BlendingColor:
+		return utils.Color
+	case BlendingLuminosity:
+		return utils.Luminosity
+	default:
+		panic("sorter: invalid blending mode specified")
+	}
+}
@@ -1,8 +1,11 @@
 package sorter
 
 import (
+	"context"
 	"image"
 	"image/color"
+	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -18,12 +21,68 @@ func TestCalculateMaxIntervalLengthShouldCalculateCorrectIntervalMaxLengthForGiv
 	}
 
 	for _, c := range cases {
-		actual := calculateMaxIntervalLength(c.length, c.randomFactor)
+		actual := calculateMaxIntervalLength(c.length, c.randomFactor, rand.New(rand.NewSource(0)))
 
 		assert.Equal(t, c.expected, actual)
 	}
 }
 
+func TestCalculateDistributedIntervalLengthShouldBeDeterministicForTheSameRandomSource(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalLengthDistribution = DistributionLogNormal
+
+	a, b := rand.New(rand.NewSource(5)), rand.New(rand.NewSource(5))
+	for i := 0; i < 100; i += 1 {
+		assert.Equal(t, calculateDistributedIntervalLength(50, options, a), calculateDistributedIntervalLength(50, options, b))
+	}
+}
+
+func TestCreateStripRandomShouldBeDeterministicAndIndependentPerStripAndCycle(t *testing.T) {
+	assert.Equal(t, createStripRandom(7, 0, 40, 4).Int63(), createStripRandom(7, 0, 40, 4).Int63())
+
+	assert.NotEqual(t, createStripRandom(7, 0, 40, 4).Int63(), createStripRandom(8, 0, 40, 4).Int63())
+	assert.NotEqual(t, createStripRandom(7, 0, 40, 4).Int63(), createStripRandom(7, 1, 40, 4).Int63())
+	assert.NotEqual(t, createStripRandom(7, 0, 40, 4).Int63(), createStripRandom(7, 0, 44, 4).Int63())
+	assert.NotEqual(t, createStripRandom(7, 0, 0, 4).Int63(), createStripRandom(7, 0, 0, 40).Int63())
+}
+
+func TestCalculateDistributedIntervalLengthShouldCalculateLengthsForGivenDistributions(t *testing.T) {
+	const iterations int = 100_000
+
+	cases := []struct {
+		distribution IntervalLengthDistribution
+		expectedMean float64
+		delta        float64
+	}{
+		{DistributionUniform, 49.5, 0.5},
+		{DistributionNormal, 50, 0.5},
+		{DistributionExponential, 50, 1.0},
+		{DistributionLogNormal, 50 * math.Exp(0.5*0.5/2), 1.0},
+		{DistributionPowerLaw, 50 * 3.0 / 2.0, 2.0},
+	}
+
+	for _, c := range cases {
+		options := GetDefaultSorterOptions()
+		options.IntervalLengthDistribution = c.distribution
+		options.IntervalLengthRandomFactor = 10
+		options.IntervalLengthDeviation = 0.5
+		options.IntervalLengthExponent = 4.0
+
+		random := rand.New(rand.NewSource(0))
+
+		sum := 0
+		for i := 0; i < iterations; i += 1 {
+			length := calculateDistributedIntervalLength(50, options, random)
+			assert.GreaterOrEqual(t, length, 1)
+
+			sum += length
+		}
+
+		assert.InDelta(t, c.expectedMean, float64(sum)/float64(iterations), c.delta)
+		assert.Equal(t, 0, calculateDistributedIntervalLength(0, options, random))
+	}
+}
+
 func TestDrawBufferToImageShouldCorrectlyAppendBufferToImage(t *testing.T) {
 	bounds := image.Rect(0, 0, 8, 1)
 	actualImg := image.NewRGBA(bounds)
@@ -50,3 +109,416 @@ func TestDrawBufferToImageShouldCorrectlyAppendBufferToImage(t *testing.T) {
 
 	assert.Equal(t, expectedImg.Pix, actualImg.Pix)
 }
+
+func TestIsMeetingIntervalDeterminantShouldHandleHueWrapAround(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByHue
+	options.IntervalDeterminantWrapAround = true
+	options.IntervalDeterminantLowerThreshold = 0.9
+	options.IntervalDeterminantUpperThreshold = 0.1
+
+	condition := createIntervalCondition(options)
+
+	cases := map[color.RGBA]bool{
+		{255, 0, 0, 255}:  true,
+		{255, 0, 30, 255}: true,
+		{255, 30, 0, 255}: true,
+		{0, 255, 0, 255}:  false,
+		{0, 0, 255, 255}:  false,
+	}
+
+	for c, expected := range cases {
+		actual := isMeetingIntervalDeterminant(c, &condition, false, 0)
+
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestIsMeetingIntervalDeterminantShouldHandleRgbChannelThresholds(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByRgbChannels
+	options.IntervalChannelThresholds = ChannelThresholds{
+		RedLower:   0.5,
+		RedUpper:   1.0,
+		GreenLower: 0.2,
+		GreenUpper: 1.0,
+		BlueLower:  0.0,
+		BlueUpper:  0.4,
+	}
+
+	condition := createIntervalCondition(options)
+
+	cases := map[color.RGBA]bool{
+		{255, 200, 50, 255}:  true,
+		{255, 200, 200, 255}: false,
+		{50, 200, 50, 255}:   false,
+		{255, 10, 50, 255}:   false,
+	}
+
+	for c, expected := range cases {
+		actual := isMeetingIntervalDeterminant(c, &condition, false, 0)
+
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestPerformImageStripSortShouldSplitIntervalsByLocalDifference(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	colors := []color.RGBA{
+		{10, 10, 10, 255},
+		{20, 20, 20, 255},
+		{200, 200, 200, 255},
+		{190, 190, 190, 255},
+	}
+
+	for x, c := range colors {
+		src.SetRGBA(x, 0, c)
+	}
+
+	dst := image.NewRGBA(src.Bounds())
+
+	options := GetDefaultSorterOptions()
+	options.SortDirection = SortDescending
+	options.IntervalDeterminant = SplitByLocalDifference
+	options.IntervalDifferenceMetric = DifferenceBrightness
+	options.IntervalDifferenceThreshold = 0.3
+
+	sources := &sortingSources{mask: CreateEmptyMask()}
+
+	err := performImageStripSort(src, dst, sources, options, 0, 0, 4, 4, context.Background())
+	assert.Nil(t, err)
+
+	expected := []color.RGBA{
+		{20, 20, 20, 255},
+		{10, 10, 10, 255},
+		{200, 200, 200, 255},
+		{190, 190, 190, 255},
+	}
+
+	for x, c := range expected {
+		assert.Equal(t, c, dst.RGBAAt(x, 0))
+	}
+}
+
+func TestSortingSourcesShouldCalculateMaxIntervalLengthFromLengthValues(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 10
+	options.IntervalLengthSource = IntervalLengthNoise
+
+	sources := &sortingSources{
+		mask:         CreateEmptyMask(),
+		lengthValues: []float64{0.0, 0.5, 1.0},
+	}
+
+	random := rand.New(rand.NewSource(0))
+
+	assert.Equal(t, 1, sources.calculateMaxIntervalLength(options, random, 0))
+	assert.Equal(t, 5, sources.calculateMaxIntervalLength(options, random, 1))
+	assert.Equal(t, 10, sources.calculateMaxIntervalLength(options, random, 2))
+
+	options.IntervalLength = 0
+	assert.Equal(t, 0, sources.calculateMaxIntervalLength(options, random, 2))
+}
+
+func TestCreateNoiseValuesShouldBeIndependentFromRotation(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.NoiseScale = 8
+	options.NoiseSeed = 5
+
+	bounds := image.Rect(0, 0, 30, 20)
+	values := createNoiseValues(bounds, bounds, options)
+
+	for _, value := range values {
+		assert.GreaterOrEqual(t, value, 0.0)
+		assert.LessOrEqual(t, value, 1.0)
+	}
+
+	options.Angle = 90
+	rotatedBounds := image.Rect(0, 0, 20, 30)
+	rotatedValues := createNoiseValues(rotatedBounds, bounds, options)
+
+	for y := 0; y < 20; y += 1 {
+		for x := 0; x < 30; x += 1 {
+			// NOTE: The counter-clockwise rotation by 90 degrees moves the (x, y) pixel to the (y, width-1-x) position
+			assert.InDelta(t, values[y*30+x], rotatedValues[(30-1-x)*20+y], 1e-9)
+		}
+	}
+}
+
+func TestIntervalConditionShouldEvaluateExpression(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByExpression
+	options.IntervalExpression = CreateIntervalAndExpression(
+		CreateIntervalClauseExpression(SplitByBrightness, 0.2, 0.8),
+		CreateIntervalNotExpression(CreateIntervalClauseExpression(SplitByHue, 0.3, 0.4)),
+		CreateIntervalClauseExpression(SplitByMask, 0.0, 1.0))
+
+	condition := createIntervalCondition(options)
+	sources := &sortingSources{mask: CreateEmptyMask()}
+
+	cases := []struct {
+		color    color.RGBA
+		isMasked bool
+		expected bool
+	}{
+		{color.RGBA{200, 50, 50, 255}, false, true},
+		{color.RGBA{200, 50, 50, 255}, true, false},
+		{color.RGBA{50, 200, 50, 255}, false, false},
+		{color.RGBA{10, 10, 10, 255}, false, false},
+		{color.RGBA{250, 250, 250, 255}, false, false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, condition.isMet(c.color, c.isMasked, sources, 0))
+	}
+}
+
+func TestIntervalConditionShouldEvaluateOrExpressionWithSources(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByExpression
+	options.IntervalExpression = CreateIntervalOrExpression(
+		CreateIntervalClauseExpression(SplitByMap, 0.5, 1.0),
+		CreateIntervalClauseExpression(SplitByNoise, 0.0, 0.2))
+
+	condition := createIntervalCondition(options)
+	sources := &sortingSources{
+		mask:        CreateEmptyMask(),
+		intervalMap: []float64{0.7, 0.1, 0.1},
+		noise:       []float64{0.5, 0.1, 0.5},
+	}
+
+	c := color.RGBA{128, 128, 128, 255}
+
+	assert.True(t, condition.isMet(c, false, sources, 0))
+	assert.True(t, condition.isMet(c, false, sources, 1))
+	assert.False(t, condition.isMet(c, false, sources, 2))
+}
+
+func TestPerformImageStripSortShouldExtendIntervalsUsingHysteresis(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.SortDirection = SortAscending
+	options.IntervalDeterminantLowerThreshold = 0.5
+	options.IntervalDeterminantUpperThreshold = 1.0
+	options.IntervalHysteresis = true
+	options.IntervalStayLowerThreshold = 0.2
+	options.IntervalStayUpperThreshold = 1.0
+
+	actual := mockPerformGrayscaleStripSort(t, []uint8{100, 200, 100, 60, 30}, options)
+
+	assert.Equal(t, []uint8{100, 60, 100, 200, 30}, actual)
+}
+
+func TestPerformImageStripSortShouldKeepGapsInsideIntervals(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.SortDirection = SortDescending
+	options.IntervalDeterminantLowerThreshold = 0.3
+	options.IntervalDeterminantUpperThreshold = 1.0
+	options.IntervalGapTolerance = 1
+
+	actual := mockPerformGrayscaleStripSort(t, []uint8{100, 120, 10, 110, 20, 20, 130}, options)
+
+	assert.Equal(t, []uint8{120, 110, 100, 10, 20, 20, 130}, actual)
+}
+
+func TestPerformImageStripSortShouldNotSortGapsAtTheStripEnd(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.SortDirection = SortDescending
+	options.IntervalDeterminantLowerThreshold = 0.3
+	options.IntervalDeterminantUpperThreshold = 1.0
+	options.IntervalGapTolerance = 2
+
+	actual := mockPerformGrayscaleStripSort(t, []uint8{100, 120, 10, 20}, options)
+
+	assert.Equal(t, []uint8{120, 100, 10, 20}, actual)
+}
+
+func TestPerformImageStripSortShouldNotSortIntervalsShorterThanMinLength(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.SortDirection = SortDescending
+	options.IntervalDeterminantLowerThreshold = 0.3
+	options.IntervalDeterminantUpperThreshold = 1.0
+	options.IntervalMinLength = 3
+
+	actual := mockPerformGrayscaleStripSort(t, []uint8{100, 120, 10, 110, 90, 130}, options)
+
+	assert.Equal(t, []uint8{100, 120, 10, 130, 110, 90}, actual)
+}
+
+func TestPerformImageStripSortShouldSplitIntervalsByClusterLabels(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.SortDirection = SortDescending
+	options.IntervalDeterminant = SplitByClusters
+	options.ClusterCount = 2
+
+	clusters := &colorClusters{labels: []uint8{0, 0, 0, 1, 1, 1}}
+	actual := mockPerformGrayscaleStripSortWithSources(t, []uint8{10, 50, 30, 200, 150, 250}, clusters, options)
+
+	assert.Equal(t, []uint8{50, 30, 10, 250, 200, 150}, actual)
+}
+
+func TestPerformImageStripSortShouldOnlySortSelectedClusters(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.SortDirection = SortDescending
+	options.IntervalDeterminant = SplitByClusters
+	options.ClusterCount = 2
+	options.ClusterIndices = []int{1}
+
+	clusters := &colorClusters{labels: []uint8{0, 0, 0, 1, 1, 1}}
+	actual := mockPerformGrayscaleStripSortWithSources(t, []uint8{10, 50, 30, 200, 150, 250}, clusters, options)
+
+	assert.Equal(t, []uint8{10, 50, 30, 250, 200, 150}, actual)
+}
+
+func TestPerformImageStripSortShouldExtendIntervalsWithGapsUsingHysteresis(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.SortDirection = SortAscending
+	options.IntervalDeterminantLowerThreshold = 0.5
+	options.IntervalDeterminantUpperThreshold = 1.0
+	options.IntervalHysteresis = true
+	options.IntervalStayLowerThreshold = 0.3
+	options.IntervalStayUpperThreshold = 1.0
+	options.IntervalGapTolerance = 1
+
+	actual := mockPerformGrayscaleStripSort(t, []uint8{100, 140, 200, 50, 90, 10, 160, 10, 10}, options)
+
+	assert.Equal(t, []uint8{100, 10, 50, 90, 140, 160, 200, 10, 10}, actual)
+}
+
+func mockPerformGrayscaleStripSort(t *testing.T, values []uint8, options *SorterOptions) []uint8 {
+	return mockPerformGrayscaleStripSortWithSources(t, values, nil, options)
+}
+
+func mockPerformGrayscaleStripSortWithSources(t *testing.T, values []uint8, clusters *colorClusters, options *SorterOptions) []uint8 {
+	src := image.NewRGBA(image.Rect(0, 0, len(values), 1))
+	for x, v := range values {
+		src.SetRGBA(x, 0, color.RGBA{v, v, v, 255})
+	}
+
+	dst := image.NewRGBA(src.Bounds())
+	sources := &sortingSources{mask: CreateEmptyMask(), clusters: clusters}
+
+	err := performImageStripSort(src, dst, sources, options, 0, 0, 4, len(values), context.Background())
+	assert.Nil(t, err)
+
+	actual := make([]uint8, 0, len(values))
+	for x := range values {
+		actual = append(actual, dst.RGBAAt(x, 0).R)
+	}
+
+	return actual
+}
+
+func TestCalculateLocalDifferenceShouldCalculateDifference(t *testing.T) {
+	black := color.RGBA{0, 0, 0, 255}
+	white := color.RGBA{255, 255, 255, 255}
+
+	metrics := []LocalDifferenceMetric{
+		DifferenceBrightness,
+		DifferenceOklab,
+	}
+
+	for _, metric := range metrics {
+		assert.InDelta(t, 0.0, calculateLocalDifference(black, black, metric), 1e-7)
+		assert.InDelta(t, 1.0, calculateLocalDifference(black, white, metric), 1e-3)
+	}
+}
+
+func TestBlendSortedImageShouldBlendAccordingToOpacity(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.SetNRGBA(0, 0, color.NRGBA{0, 0, 0, 0xff})
+	src.SetNRGBA(1, 0, color.NRGBA{0, 0, 0, 0xff})
+
+	dst := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	dst.SetNRGBA(0, 0, color.NRGBA{200, 100, 50, 0xff})
+	dst.SetNRGBA(1, 0, color.NRGBA{200, 100, 50, 0xff})
+
+	opacityImage := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	opacityImage.SetNRGBA(0, 0, color.NRGBA{0xff, 0xff, 0xff, 0xff})
+	opacityImage.SetNRGBA(1, 0, color.NRGBA{0, 0, 0, 0xff})
+
+	options := GetDefaultSorterOptions()
+	options.Blending = BlendingScreen
+	options.BlendingOpacity = 0.5
+
+	actual, err := blendSortedImage(src, dst, nil, nil, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, color.NRGBA{100, 50, 25, 0xff}, actual.NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{100, 50, 25, 0xff}, actual.NRGBAAt(1, 0))
+
+	actual, err = blendSortedImage(src, dst, nil, opacityImage, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, color.NRGBA{100, 50, 25, 0xff}, actual.NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{0, 0, 0, 0xff}, actual.NRGBAAt(1, 0))
+}
+
+func TestBlendSortedImageShouldKeepTheOriginalImageForZeroBlendingOpacity(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	src.SetNRGBA(0, 0, color.NRGBA{0, 0, 0, 0xff})
+
+	dst := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	dst.SetNRGBA(0, 0, color.NRGBA{200, 100, 50, 0xff})
+
+	options := GetDefaultSorterOptions()
+	options.BlendingOpacity = 0.0
+
+	actual, err := blendSortedImage(src, dst, nil, nil, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, color.NRGBA{0, 0, 0, 0xff}, actual.NRGBAAt(0, 0))
+}
+
+func TestBlendScaledSortedImageShouldScaleTheSourceImage(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	dst := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+
+	options := GetDefaultSorterOptions()
+	options.Blending = BlendingMultiply
+	options.Scale = 0.5
+
+	actual, err := blendScaledSortedImage(src, dst, nil, &auxiliaryImagesNrgba{blendingOpacityImage: src}, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, dst.Bounds(), actual.Bounds())
+}
+
+func TestResultImageBlendingShouldPanicOnInvalidBlendingMode(t *testing.T) {
+	assert.Panics(t, func() {
+		BlendingNone.toBlendingMode()
+	})
+
+	assert.Panics(t, func() {
+		ResultImageBlending(-1).toBlendingMode()
+	})
+}
+
+func TestBlendSortedImageShouldBlendAccordingToSoftMask(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	dst := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	maskImage := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+
+	for x, gray := range []uint8{0x00, 0x80, 0xff} {
+		src.SetNRGBA(x, 0, color.NRGBA{0, 0, 0, 0xff})
+		dst.SetNRGBA(x, 0, color.NRGBA{200, 100, 50, 0xff})
+		maskImage.SetNRGBA(x, 0, color.NRGBA{gray, gray, gray, 0xff})
+	}
+
+	options := GetDefaultSorterOptions()
+	options.UseMask = true
+	options.SoftMask = true
+
+	actual, err := blendSortedImage(src, dst, maskImage, nil, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, color.NRGBA{0, 0, 0, 0xff}, actual.NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{100, 50, 25, 0xff}, actual.NRGBAAt(1, 0))
+	assert.Equal(t, color.NRGBA{200, 100, 50, 0xff}, actual.NRGBAAt(2, 0))
+
+	options.SoftMask = false
+
+	actual, err = blendSortedImage(src, dst, maskImage, nil, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, color.NRGBA{200, 100, 50, 0xff}, actual.NRGBAAt(0, 0))
+}
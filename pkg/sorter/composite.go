@@ -0,0 +1,192 @@
+package sorter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sync"
+
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
+)
+
+// Flag representing the method used to composite the images sorted at the multiple angles
+type AngleCompositeMode int
+
+const (
+	AngleCompositeLighten AngleCompositeMode = iota
+	AngleCompositeDarken
+	AngleCompositeAverage
+	AngleCompositeMaxWeight
+)
+
+// NOTE: Approximate count of the image copies (rotated source, RGBA source and destination, sorted and reverted images) allocated per angle
+const angleCompositeImageCopies = 6
+
+// Create a copy of the sorter options used to sort the already scaled image at the given angle of the multi-angle composite
+func (options *SorterOptions) createCompositeAngleOptions(angle int) *SorterOptions {
+	angleOptions := *options
+	angleOptions.CompositeAngles = nil
+	angleOptions.Angle = angle
+
+	// NOTE: The image is already scaled, so the noise scale and the mask feather radius are adjusted to keep them expressed in the original
+	// image pixels
+	angleOptions.NoiseScale = options.NoiseScale * options.Scale
+	angleOptions.MaskFeatherRadius = options.MaskFeatherRadius * options.Scale
+	angleOptions.Scale = 1.0
+
+	return &angleOptions
+}
+
+// Function used to sort the given already scaled image at all composite angles specified by the options and composite the results according
+// to the angle composite mode. The angles are sorted in parallel by the provided sort function using the angle options. The count of angles
+// sorted at the same time is limited by the memory budget specified by the options.
+func performAngleCompositeSort(scaled *image.NRGBA, options *SorterOptions, sort func(angleOptions *SorterOptions) (*image.NRGBA, error)) (*image.NRGBA, error) {
+	results := make([]*image.NRGBA, len(options.CompositeAngles))
+	semaphore := make(chan struct{}, calculateAngleCompositeConcurrency(scaled.Bounds(), options))
+
+	wg := &sync.WaitGroup{}
+	errt := utils.NewErrorTrap()
+
+	for index, angle := range options.CompositeAngles {
+		wg.Add(1)
+		go func(index, angle int) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if errt.IsSet() {
+				return
+			}
+
+			result, err := sort(options.createCompositeAngleOptions(angle))
+			if err != nil {
+				errt.Set(fmt.Errorf("sorter: failed to sort the image at the composite angle %d: %w", angle, err))
+				return
+			}
+
+			if result.Bounds().Size() != scaled.Bounds().Size() {
+				errt.Set(fmt.Errorf("sorter: the image sorted at the composite angle %d bounds are not matching the image bounds", angle))
+				return
+			}
+
+			results[index] = result
+		}(index, angle)
+	}
+
+	wg.Wait()
+	if err := errt.Err(); err != nil {
+		return nil, err
+	}
+
+	return compositeAngleImages(results, options.AngleComposite, options.SortDeterminant), nil
+}
+
+// Calculate the count of angles that can be sorted at the same time without exceeding the memory budget specified by the options. The memory
+// used by a single angle is estimated from the size of the image rotated by the greatest angle. At least a single angle is always sorted.
+func calculateAngleCompositeConcurrency(bounds image.Rectangle, options *SorterOptions) int {
+	if options.CompositeMemoryBudget == 0 {
+		return len(options.CompositeAngles)
+	}
+
+	maxAngleMemory := 0.0
+	for _, angle := range options.CompositeAngles {
+		sin, cos := math.Sincos(math.Pi * float64(angle) / 180.0)
+
+		width := math.Abs(float64(bounds.Dx())*cos) + math.Abs(float64(bounds.Dy())*sin)
+		height := math.Abs(float64(bounds.Dx())*sin) + math.Abs(float64(bounds.Dy())*cos)
+
+		maxAngleMemory = math.Max(maxAngleMemory, width*height*4*angleCompositeImageCopies)
+	}
+
+	concurrency := int(float64(options.CompositeMemoryBudget) * 1024 * 1024 / maxAngleMemory)
+	if concurrency < 1 {
+		return 1
+	}
+
+	if concurrency > len(options.CompositeAngles) {
+		return len(options.CompositeAngles)
+	}
+
+	return concurrency
+}
+
+// Composite the images sorted at the multiple angles into a new image according to the given angle composite mode. The max weight composite
+// is selecting the pixel with the greatest weight calculated according to the given sort determinant.
+func compositeAngleImages(images []*image.NRGBA, mode AngleCompositeMode, determinant SortDeterminant) *image.NRGBA {
+	result := image.NewNRGBA(images[0].Bounds())
+
+	for index := 0; index < len(result.Pix); index += 4 {
+		switch mode {
+		case AngleCompositeLighten, AngleCompositeDarken:
+			{
+				copy(result.Pix[index:index+4], images[0].Pix[index:index+4])
+
+				for _, img := range images[1:] {
+					for channel := 0; channel < 4; channel += 1 {
+						if mode == AngleCompositeLighten {
+							result.Pix[index+channel] = utils.Max2Uint8(result.Pix[index+channel], img.Pix[index+channel])
+						} else {
+							result.Pix[index+channel] = utils.Min2Uint8(result.Pix[index+channel], img.Pix[index+channel])
+						}
+					}
+				}
+			}
+		case AngleCompositeAverage:
+			{
+				for channel := 0; channel < 4; channel += 1 {
+					sum := 0
+					for _, img := range images {
+						sum += int(img.Pix[index+channel])
+					}
+
+					result.Pix[index+channel] = uint8(math.Round(float64(sum) / float64(len(images))))
+				}
+			}
+		case AngleCompositeMaxWeight:
+			{
+				maxWeight := math.Inf(-1)
+				for _, img := range images {
+					c := color.NRGBA{img.Pix[index+0], img.Pix[index+1], img.Pix[index+2], img.Pix[index+3]}
+
+					if weight := calculateCompositeWeight(c, determinant); weight > maxWeight {
+						maxWeight = weight
+						copy(result.Pix[index:index+4], img.Pix[index:index+4])
+					}
+				}
+			}
+		default:
+			panic("sorter: invalid sorter state due to a corrupted angle composite mode value")
+		}
+	}
+
+	return result
+}
+
+// Calculate the normalized weight of the given color according to the sort determinant used by the max weight composite. The key image and
+// noise sort determinants are not depending on the color, so the perceived brightness is used instead.
+func calculateCompositeWeight(c color.NRGBA, determinant SortDeterminant) float64 {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+
+	switch determinant {
+	case SortByBrightness, SortByKeyImage, SortByNoise:
+		return utils.CalculatePerceivedBrightness(rgba)
+	case SortByHue:
+		h, _, _, _ := utils.RgbaToHsla(rgba)
+		return float64(h) / 360.0
+	case SortBySaturation:
+		_, s, _, _ := utils.RgbaToHsla(rgba)
+		return s
+	case SortByAbsoluteColor:
+		return float64(int(c.R)*int(c.G)*int(c.B)) / 16581375.0
+	case SortByRedChannel:
+		return float64(c.R) / 255.0
+	case SortByGreenChannel:
+		return float64(c.G) / 255.0
+	case SortByBlueChannel:
+		return float64(c.B) / 255.0
+	default:
+		panic("sorter: invalid sorter state due to a corrupted sort determinant value")
+	}
+}
@@ -0,0 +1,129 @@
+package sorter
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCompositeAngleOptionsShouldCreateAngleOptions(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.CompositeAngles = []int{0, 60, 120}
+	options.Angle = 15
+	options.Scale = 0.5
+	options.NoiseScale = 100
+
+	angleOptions := options.createCompositeAngleOptions(60)
+
+	assert.Nil(t, angleOptions.CompositeAngles)
+	assert.Equal(t, 60, angleOptions.Angle)
+	assert.Equal(t, 1.0, angleOptions.Scale)
+	assert.Equal(t, 50.0, angleOptions.NoiseScale)
+
+	assert.Equal(t, []int{0, 60, 120}, options.CompositeAngles)
+	assert.Equal(t, 15, options.Angle)
+}
+
+func TestCalculateAngleCompositeConcurrencyShouldRespectMemoryBudget(t *testing.T) {
+	bounds := image.Rect(0, 0, 1024, 1024)
+
+	cases := []struct {
+		budget   int
+		expected int
+	}{
+		{0, 3},
+		{1, 1},
+		{24, 1},
+		{50, 2},
+		{1024, 3},
+	}
+
+	for _, c := range cases {
+		options := GetDefaultSorterOptions()
+		options.CompositeAngles = []int{0, 90, 180}
+		options.CompositeMemoryBudget = c.budget
+
+		assert.Equal(t, c.expected, calculateAngleCompositeConcurrency(bounds, options))
+	}
+}
+
+func TestCompositeAngleImagesShouldCompositeImages(t *testing.T) {
+	a := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	a.SetNRGBA(0, 0, color.NRGBA{200, 10, 100, 255})
+
+	b := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	b.SetNRGBA(0, 0, color.NRGBA{50, 20, 101, 255})
+
+	cases := []struct {
+		mode        AngleCompositeMode
+		determinant SortDeterminant
+		expected    color.NRGBA
+	}{
+		{AngleCompositeLighten, SortByBrightness, color.NRGBA{200, 20, 101, 255}},
+		{AngleCompositeDarken, SortByBrightness, color.NRGBA{50, 10, 100, 255}},
+		{AngleCompositeAverage, SortByBrightness, color.NRGBA{125, 15, 101, 255}},
+		{AngleCompositeMaxWeight, SortByRedChannel, color.NRGBA{200, 10, 100, 255}},
+		{AngleCompositeMaxWeight, SortByGreenChannel, color.NRGBA{50, 20, 101, 255}},
+	}
+
+	for _, c := range cases {
+		actual := compositeAngleImages([]*image.NRGBA{a, b}, c.mode, c.determinant)
+
+		assert.Equal(t, c.expected, actual.NRGBAAt(0, 0))
+	}
+}
+
+func TestCompositeAngleImagesShouldPanicOnInvalidMode(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+
+	assert.Panics(t, func() {
+		compositeAngleImages([]*image.NRGBA{img}, AngleCompositeMode(-1), SortByBrightness)
+	})
+}
+
+func TestPerformAngleCompositeSortShouldSortAllAngles(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+
+	options := GetDefaultSorterOptions()
+	options.CompositeAngles = []int{0, 60, 120}
+	options.CompositeMemoryBudget = 1
+
+	var (
+		angles      []int
+		anglesMutex sync.Mutex
+	)
+
+	result, err := performAngleCompositeSort(img, options, func(angleOptions *SorterOptions) (*image.NRGBA, error) {
+		anglesMutex.Lock()
+		angles = append(angles, angleOptions.Angle)
+		anglesMutex.Unlock()
+
+		return image.NewNRGBA(img.Bounds()), nil
+	})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.ElementsMatch(t, []int{0, 60, 120}, angles)
+}
+
+func TestPerformAngleCompositeSortShouldReturnSortError(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+
+	options := GetDefaultSorterOptions()
+	options.CompositeAngles = []int{0, 90}
+
+	result, err := performAngleCompositeSort(img, options, func(angleOptions *SorterOptions) (*image.NRGBA, error) {
+		if angleOptions.Angle == 90 {
+			return nil, errors.New("sort failure")
+		}
+
+		return image.NewNRGBA(img.Bounds()), nil
+	})
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+}
@@ -12,18 +12,26 @@ import (
 )
 
 type defaultSorter struct {
-	image       *image.NRGBA
-	maskImage   *image.NRGBA
-	mask        Mask
-	logger      SorterLogger
-	options     *SorterOptions
-	cancel      func()
-	cancelMutex sync.Mutex
+	image            *image.NRGBA
+	maskImage        *image.NRGBA
+	mask             Mask
+	auxiliary        *auxiliaryImagesNrgba
+	clusterCentroids [][3]float64
+	logger           SorterLogger
+	options          *SorterOptions
+	cancel           func()
+	cancelMutex      sync.Mutex
 }
 
 // Create a new image sorter instance by providing the image to be sorted and optional parameters such as mask image
 // logger instance and custom sorter options. This function will return a new sorter instance or a error.
 func CreateSorter(image image.Image, mask image.Image, logger SorterLogger, options *SorterOptions) (Sorter, error) {
+	return CreateSorterWithAuxiliaryImages(image, mask, nil, logger, options)
+}
+
+// Create a new image sorter instance by providing the image to be sorted and optional parameters such as mask image,
+// auxiliary images, logger instance and custom sorter options. This function will return a new sorter instance or a error.
+func CreateSorterWithAuxiliaryImages(image image.Image, mask image.Image, auxiliary *AuxiliaryImages, logger SorterLogger, options *SorterOptions) (Sorter, error) {
 	if image == nil {
 		return nil, fmt.Errorf("sorter: can not create a sorter with the provided nil image")
 	}
@@ -41,6 +49,13 @@ func CreateSorter(image image.Image, mask image.Image, logger SorterLogger, opti
 		}
 	}
 
+	auxiliaryNrgba, err := createAuxiliaryImagesNrgba(auxiliary, image.Bounds())
+	if err != nil {
+		return nil, err
+	}
+
+	sorter.auxiliary = auxiliaryNrgba
+
 	if logger == nil {
 		sorter.logger = getDiscardLogger()
 	} else {
@@ -80,10 +95,7 @@ func (sorter *defaultSorter) CancelSort() bool {
 
 func (sorter *defaultSorter) Sort() (image.Image, error) {
 	var (
-		srcImageNrgba   *image.NRGBA
-		srcImageRgba    *image.RGBA
-		maskImage       *image.NRGBA
-		revertRotation  func(*image.NRGBA) *image.NRGBA
+		dstImageNrgba   *image.NRGBA
 		sortingExecTime time.Time = time.Now()
 		err             error     = nil
 	)
@@ -95,38 +107,196 @@ func (sorter *defaultSorter) Sort() (image.Image, error) {
 	sorter.cancel = cancel
 	sorter.cancelMutex.Unlock()
 
-	if sorter.options.Scale != 1.0 {
+	sorter.clusterCentroids = createClusterCentroids(sorter.image, sorter.options)
+
+	if len(sorter.options.CompositeAngles) > 0 {
+		dstImageNrgba, err = sorter.sortAngleComposite(sorter.image, sorter.options, ctx)
+	} else if sorter.options.ChannelSplit != ChannelSplitNone {
+		dstImageNrgba, _, err = performChannelSplitSort(sorter.image, sorter.options, func(plane *image.NRGBA, planeOptions *SorterOptions) (*image.NRGBA, error) {
+			return sorter.sortImageCycles(plane, planeOptions, ctx)
+		})
+	} else {
+		dstImageNrgba, err = sorter.sortImageCycles(sorter.image, sorter.options, ctx)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if dstImageNrgba, err = blendScaledSortedImage(sorter.image, dstImageNrgba, sorter.maskImage, sorter.auxiliary, sorter.options); err != nil {
+		return nil, err
+	}
+
+	sorter.logger.Debugf("Pixel sorting took: %s.", time.Since(sortingExecTime))
+	return dstImageNrgba, nil
+}
+
+// Perform the sorting of the given image according to the given options. If the options are specifying a cycle schedule, the consecutive
+// cycles with the same scheduled angle are performed in the same rotated space and the rotation is performed only when the angle is changing,
+// so the image is not resampled between such cycles. The images are scaled only once before the first cycle.
+func (sorter *defaultSorter) sortImageCycles(srcImage *image.NRGBA, options *SorterOptions, ctx context.Context) (*image.NRGBA, error) {
+	if options.CycleSchedule.IsEmpty() {
+		return sorter.sortImage(srcImage, options, ctx)
+	}
+
+	cycleSorter, dstImageNrgba, err := sorter.createScaledSorter(srcImage, options.Scale)
+	if err != nil {
+		return nil, err
+	}
+
+	createCycleOptions := func(cycle int) *SorterOptions {
+		cycleOptions := options.createCycleOptions(cycle)
+
+		// NOTE: The images are already scaled, so the noise scale is adjusted to keep the noise field expressed in the original image pixels
+		cycleOptions.NoiseScale = options.NoiseScale * options.Scale
+		cycleOptions.MaskFeatherRadius = options.MaskFeatherRadius * options.Scale
+		cycleOptions.Scale = 1.0
+
+		return cycleOptions
+	}
+
+	for c := 0; c < options.Cycles; {
+		cycleOptions := []*SorterOptions{createCycleOptions(c)}
+		for c+len(cycleOptions) < options.Cycles {
+			nextCycleOptions := createCycleOptions(c + len(cycleOptions))
+			if nextCycleOptions.Angle != cycleOptions[0].Angle {
+				break
+			}
+
+			cycleOptions = append(cycleOptions, nextCycleOptions)
+		}
+
+		if dstImageNrgba, err = cycleSorter.sortImageWithCycleOptions(dstImageNrgba, cycleOptions[0], cycleOptions, c, ctx); err != nil {
+			return nil, err
+		}
+
+		c += len(cycleOptions)
+	}
+
+	return dstImageNrgba, nil
+}
+
+// Create a copy of the sorter with the mask and auxiliary images scaled according to the given scale and return it together with the scaled
+// version of the given image. The sorter itself is returned if the scaling is not required.
+func (sorter *defaultSorter) createScaledSorter(srcImage *image.NRGBA, scale float64) (*defaultSorter, *image.NRGBA, error) {
+	if scale == 1.0 {
+		return sorter, srcImage, nil
+	}
+
+	var (
+		scaledSorter  *defaultSorter = &defaultSorter{image: sorter.image, clusterCentroids: sorter.clusterCentroids, logger: sorter.logger}
+		srcImageNrgba *image.NRGBA
+		err           error
+	)
+
+	if srcImageNrgba, err = utils.ScaleImageNrgba(srcImage, scale); err != nil {
+		return nil, nil, fmt.Errorf("sorter: failed to scale the target image: %w", err)
+	}
+
+	if sorter.maskImage != nil {
+		if scaledSorter.maskImage, err = utils.ScaleImageNrgba(sorter.maskImage, scale); err != nil {
+			return nil, nil, fmt.Errorf("sorter: failed to scale the target image mask: %w", err)
+		}
+	}
+
+	if scaledSorter.auxiliary, err = sorter.auxiliary.scale(scale); err != nil {
+		return nil, nil, fmt.Errorf("sorter: failed to scale the auxiliary images: %w", err)
+	}
+
+	return scaledSorter, srcImageNrgba, nil
+}
+
+// Perform the sorting of the given image at all composite angles specified by the options. The images are scaled only once and each angle
+// is sorted by a separate sorter sharing the scaled images, so the angles can be sorted in parallel.
+func (sorter *defaultSorter) sortAngleComposite(srcImage *image.NRGBA, options *SorterOptions, ctx context.Context) (*image.NRGBA, error) {
+	scaledSorter, srcImageNrgba, err := sorter.createScaledSorter(srcImage, options.Scale)
+	if err != nil {
+		return nil, err
+	}
+
+	return performAngleCompositeSort(srcImageNrgba, options, func(angleOptions *SorterOptions) (*image.NRGBA, error) {
+		angleSorter := &defaultSorter{
+			image:            sorter.image,
+			maskImage:        scaledSorter.maskImage,
+			auxiliary:        scaledSorter.auxiliary,
+			clusterCentroids: scaledSorter.clusterCentroids,
+			logger:           sorter.logger,
+		}
+
+		return angleSorter.sortImageCycles(srcImageNrgba, angleOptions, ctx)
+	})
+}
+
+// Perform the scaling, rotation and sorting of the given image according to the given options and return the sorted image with the
+// rotation reverted. The blending of the sorted image is not performed.
+func (sorter *defaultSorter) sortImage(srcImage *image.NRGBA, options *SorterOptions, ctx context.Context) (*image.NRGBA, error) {
+	cycleOptions := make([]*SorterOptions, options.Cycles)
+	for c := range cycleOptions {
+		cycleOptions[c] = options
+	}
+
+	return sorter.sortImageWithCycleOptions(srcImage, options, cycleOptions, 0, ctx)
+}
+
+// Perform the scaling, mask feathering and rotation of the given image and the sorter images according to the given options and create the
+// sorting sources of the prepared images. The function returns the prepared image, the sorting sources and the function used to revert the
+// rotation, which is nil if the image is not rotated.
+func (sorter *defaultSorter) prepareSortingImages(srcImage *image.NRGBA, options *SorterOptions) (*image.NRGBA, *sortingSources, func(*image.NRGBA) *image.NRGBA, error) {
+	var (
+		srcImageNrgba  *image.NRGBA
+		maskImage      *image.NRGBA
+		auxiliary      *auxiliaryImagesNrgba
+		sources        *sortingSources
+		clusters       *colorClusters
+		scaledBounds   image.Rectangle
+		revertRotation func(*image.NRGBA) *image.NRGBA
+		err            error = nil
+	)
+
+	if options.Scale != 1.0 {
 		scalingExecTime := time.Now()
 
-		if srcImageNrgba, err = utils.ScaleImageNrgba(sorter.image, sorter.options.Scale); err != nil {
-			return nil, fmt.Errorf("sorter: failed to scale the target image: %w", err)
+		if srcImageNrgba, err = utils.ScaleImageNrgba(srcImage, options.Scale); err != nil {
+			return nil, nil, nil, fmt.Errorf("sorter: failed to scale the target image: %w", err)
 		}
 
 		if sorter.maskImage != nil {
-			if maskImage, err = utils.ScaleImageNrgba(sorter.maskImage, sorter.options.Scale); err != nil {
-				return nil, fmt.Errorf("sorter: failed to scale the target image mask: %w", err)
+			if maskImage, err = utils.ScaleImageNrgba(sorter.maskImage, options.Scale); err != nil {
+				return nil, nil, nil, fmt.Errorf("sorter: failed to scale the target image mask: %w", err)
 			}
 		}
 
+		if auxiliary, err = sorter.auxiliary.scale(options.Scale); err != nil {
+			return nil, nil, nil, fmt.Errorf("sorter: failed to scale the auxiliary images: %w", err)
+		}
+
 		sorter.logger.Debugf("Input images scaling took: %s", time.Since(scalingExecTime))
 	} else {
-		srcImageNrgba = sorter.image
+		srcImageNrgba = srcImage
 		maskImage = sorter.maskImage
+		auxiliary = sorter.auxiliary
 	}
 
-	if sorter.options.Angle != 0 {
-		srcImageNrgba, revertRotation = utils.RotateImageWithRevertNrgba(srcImageNrgba, sorter.options.Angle)
+	// NOTE: The mask feather radius is expressed in the original image pixels
+	maskImage = featherMaskImage(maskImage, options.MaskFeatherRadius*options.Scale)
+
+	scaledBounds = srcImageNrgba.Bounds()
+
+	if options.Angle != 0 {
+		srcImageNrgba, revertRotation = utils.RotateImageWithRevertNrgba(srcImageNrgba, options.Angle)
 
 		if maskImage != nil {
-			maskImage = utils.RotateImageNrgba(maskImage, sorter.options.Angle)
+			maskImage = utils.RotateImageNrgba(maskImage, options.Angle)
 		}
+
+		auxiliary = auxiliary.rotate(options.Angle)
 	}
 
-	if sorter.options.IntervalDeterminant == SplitByEdgeDetection {
+	if options.usesIntervalDeterminant(SplitByEdgeDetection) {
 		edgeDetectionExecTime := time.Now()
 		maskImage, err = img.PerformEdgeDetection(srcImageNrgba, false, true)
 		if err != nil {
-			return nil, fmt.Errorf("sorter: failed to perform the edge detection on the provided image: %w", err)
+			return nil, nil, nil, fmt.Errorf("sorter: failed to perform the edge detection on the provided image: %w", err)
 		}
 
 		sorter.logger.Debugf("Edge detection took: %s.", time.Since(edgeDetectionExecTime))
@@ -135,7 +305,7 @@ func (sorter *defaultSorter) Sort() (image.Image, error) {
 	if maskImage != nil {
 		maskExecTime := time.Now()
 		if sorter.mask, err = CreateMaskFromNrgba(maskImage); err != nil {
-			return nil, fmt.Errorf("sorter: failed to create a new mask instance: %w", err)
+			return nil, nil, nil, fmt.Errorf("sorter: failed to create a new mask instance: %w", err)
 		}
 
 		sorter.logger.Debugf("Mask parsing took: %s.", time.Since(maskExecTime))
@@ -143,78 +313,101 @@ func (sorter *defaultSorter) Sort() (image.Image, error) {
 		sorter.mask = CreateEmptyMask()
 	}
 
-	srcImageRgba = utils.NrgbaToRgbaImage(srcImageNrgba)
+	if options.IntervalDeterminant == SplitByClusters {
+		clusteringExecTime := time.Now()
+		if sorter.clusterCentroids == nil {
+			sorter.clusterCentroids = createClusterCentroids(sorter.image, options)
+		}
+
+		clusters = labelColorClusters(srcImageNrgba, sorter.clusterCentroids)
+
+		sorter.logger.Debugf("Color clustering took: %s.", time.Since(clusteringExecTime))
+	}
+
+	if sources, err = createSortingSources(sorter.mask, auxiliary, clusters, srcImageNrgba.Bounds(), scaledBounds, options); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return srcImageNrgba, sources, revertRotation, nil
+}
+
+// Perform the scaling, rotation and sorting of the given image and return the sorted image with the rotation reverted. The images are
+// prepared according to the given options and each sorting cycle is performed using the options of the cycle, which can only differ by
+// the parameters that are not affecting the scaled and rotated images (e.g. the thresholds, interval length, sort order and direction). The
+// first cycle index is the index of the first given cycle within all cycles of the sort, which is used to derive the strip random sources.
+func (sorter *defaultSorter) sortImageWithCycleOptions(srcImage *image.NRGBA, options *SorterOptions, cycleOptions []*SorterOptions, firstCycle int, ctx context.Context) (*image.NRGBA, error) {
+	srcImageNrgba, sources, revertRotation, err := sorter.prepareSortingImages(srcImage, options)
+	if err != nil {
+		return nil, err
+	}
+
+	srcImageRgba := utils.NrgbaToRgbaImage(srcImageNrgba)
 	dstImageRgba := utils.GetImageCopyRgba(srcImageRgba)
 
-	for c := 0; c < sorter.options.Cycles; c += 1 {
-		switch sorter.options.SortOrder {
+	// NOTE: The thresholds of all cycles are resolved using the image before the first cycle
+	sortOptions := make([]*SorterOptions, len(cycleOptions))
+	for c := range cycleOptions {
+		if c > 0 && cycleOptions[c] == cycleOptions[c-1] {
+			sortOptions[c] = sortOptions[c-1]
+			continue
+		}
+
+		if sortOptions[c], err = resolveIntervalDeterminantThresholds(srcImageRgba, sources, cycleOptions[c], sorter.logger); err != nil {
+			return nil, err
+		}
+	}
+
+	for c := range sortOptions {
+		options := sortOptions[c]
+
+		switch options.SortOrder {
 		case SortVertical:
 			{
-				if err = performParallelColumnSorting(srcImageRgba, dstImageRgba, sorter.mask, sorter.options, ctx); err != nil {
+				if err = performParallelColumnSorting(srcImageRgba, dstImageRgba, sources, options, firstCycle+c, ctx); err != nil {
 					return nil, fmt.Errorf("sorter: failed to perform the vertical column sort: %w", err)
 				}
 			}
 		case SortHorizontal:
 			{
-				if err = performParallelRowSorting(srcImageRgba, dstImageRgba, sorter.mask, sorter.options, ctx); err != nil {
+				if err = performParallelRowSorting(srcImageRgba, dstImageRgba, sources, options, firstCycle+c, ctx); err != nil {
 					return nil, fmt.Errorf("sorter: failed to perform the horizontal row sort: %w", err)
 				}
 			}
 		case SortVerticalAndHorizontal:
 			{
-				if err = performParallelColumnSorting(srcImageRgba, dstImageRgba, sorter.mask, sorter.options, ctx); err != nil {
+				if err = performParallelColumnSorting(srcImageRgba, dstImageRgba, sources, options, firstCycle+c, ctx); err != nil {
 					return nil, fmt.Errorf("sorter: failed to perform the vertical column sort: %w", err)
 				}
 
 				copy(srcImageRgba.Pix, dstImageRgba.Pix)
 
-				if err = performParallelRowSorting(srcImageRgba, dstImageRgba, sorter.mask, sorter.options, ctx); err != nil {
+				if err = performParallelRowSorting(srcImageRgba, dstImageRgba, sources, options, firstCycle+c, ctx); err != nil {
 					return nil, fmt.Errorf("sorter: failed to perform the horizontal row sort: %w", err)
 				}
 			}
 		case SortHorizontalAndVertical:
 			{
-				if err = performParallelRowSorting(srcImageRgba, dstImageRgba, sorter.mask, sorter.options, ctx); err != nil {
+				if err = performParallelRowSorting(srcImageRgba, dstImageRgba, sources, options, firstCycle+c, ctx); err != nil {
 					return nil, fmt.Errorf("sorter: failed to perform the horizontal row sort: %w", err)
 				}
 
 				copy(srcImageRgba.Pix, dstImageRgba.Pix)
 
-				if err = performParallelColumnSorting(srcImageRgba, dstImageRgba, sorter.mask, sorter.options, ctx); err != nil {
+				if err = performParallelColumnSorting(srcImageRgba, dstImageRgba, sources, options, firstCycle+c, ctx); err != nil {
 					return nil, fmt.Errorf("sorter: failed to perform the vertical column sort: %w", err)
 				}
 			}
 		}
 
-		if sorter.options.Cycles > 1 {
+		if len(sortOptions) > 1 {
 			copy(srcImageRgba.Pix, dstImageRgba.Pix)
 		}
 	}
 
 	dstImageNrgba := utils.RgbaToNrgbaImage(dstImageRgba)
-	if sorter.options.Angle != 0 {
+	if options.Angle != 0 {
 		dstImageNrgba = revertRotation(dstImageNrgba)
 	}
 
-	switch sorter.options.Blending {
-	case BlendingLighten:
-		{
-			if dstImageNrgba, err = utils.BlendImagesNrgba(sorter.image, dstImageNrgba, utils.LightenOnly); err != nil {
-				return nil, fmt.Errorf("sorter: failed to perform the image lighten blending: %w", err)
-			}
-		}
-	case BlendingDarken:
-		{
-			if dstImageNrgba, err = utils.BlendImagesNrgba(sorter.image, dstImageNrgba, utils.DarkenOnly); err != nil {
-				return nil, fmt.Errorf("sorter: failed to perform the image darken blending: %w", err)
-			}
-		}
-	case BlendingNone:
-		break
-	default:
-		panic("sorter: invalid blending mode specified")
-	}
-
-	sorter.logger.Debugf("Pixel sorting took: %s.", time.Since(sortingExecTime))
 	return dstImageNrgba, nil
 }
@@ -5,6 +5,7 @@ import (
 	"image/color"
 	"testing"
 
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/goleak"
 )
@@ -156,6 +157,55 @@ func TestDefaultOptionsAndSortDeterminantBlueChannel(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestDefaultOptionsAndSortDeterminantKeyImage(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.SortDeterminant = SortByKeyImage
+
+	auxiliary := &AuxiliaryImages{
+		KeyImage: mockTestBlackAndWhiteStripesImage(),
+	}
+
+	sorter, err := CreateSorterWithAuxiliaryImages(mockTestBlackAndWhiteStripesImage(), nil, auxiliary, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndSortDeterminantKeyImageShouldFailWithoutKeyImage(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.SortDeterminant = SortByKeyImage
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+}
+
+func TestDefaultSorterShouldNotCreateForKeyImageWithNotMatchingBounds(t *testing.T) {
+	auxiliary := &AuxiliaryImages{
+		KeyImage: createMockTestBlackAndWhiteStripesImage(2, 2),
+	}
+
+	sorter, err := CreateSorterWithAuxiliaryImages(mockTestBlackAndWhiteStripesImage(), nil, auxiliary, nil, nil)
+
+	assert.Nil(t, sorter)
+	assert.NotNil(t, err)
+}
+
 func TestDefaultOptionsAndAngle45Degrees(t *testing.T) {
 	defer goleak.VerifyNone(t)
 
@@ -626,6 +676,980 @@ func TestDefaultOptionsAndIntervalPaintingAverage(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestDefaultOptionsAndIntervalDeterminantMap(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByMap
+	options.IntervalDeterminantLowerThreshold = 0.4
+	options.IntervalDeterminantUpperThreshold = 1.0
+
+	auxiliary := &AuxiliaryImages{
+		IntervalMapImage: mockTestBlackAndWhiteStripesImage(),
+	}
+
+	sorter, err := CreateSorterWithAuxiliaryImages(mockTestBlackAndWhiteStripesImage(), nil, auxiliary, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalDeterminantMapShouldFailWithoutMapImage(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByMap
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalThresholdSelectionOtsu(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalThresholdSelection = ThresholdOtsu
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalThresholdSelectionPercentile(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalThresholdSelection = ThresholdPercentile
+	options.IntervalLowerPercentile = 20
+	options.IntervalUpperPercentile = 85
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalDeterminantHueWrapAround(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByHue
+	options.IntervalDeterminantWrapAround = true
+	options.IntervalDeterminantLowerThreshold = 0.9
+	options.IntervalDeterminantUpperThreshold = 0.1
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalDeterminantRgbChannels(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByRgbChannels
+	options.IntervalChannelThresholds.RedLower = 0.5
+	options.IntervalChannelThresholds.BlueUpper = 0.4
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalDeterminantLocalDifferenceBrightness(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByLocalDifference
+	options.IntervalDifferenceMetric = DifferenceBrightness
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalDeterminantLocalDifferenceOklab(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByLocalDifference
+	options.IntervalDifferenceMetric = DifferenceOklab
+	options.IntervalDifferenceThreshold = 0.2
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndNoiseIntervalDeterminant(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByNoise
+	options.IntervalDeterminantLowerThreshold = 0.4
+	options.IntervalDeterminantUpperThreshold = 0.8
+	options.NoiseSeed = 42
+	options.NoiseScale = 10
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndNoiseSortDeterminant(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.SortDeterminant = SortByNoise
+	options.NoiseScale = 10
+	options.Angle = 45
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndNoiseIntervalLengthSource(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 20
+	options.IntervalLengthSource = IntervalLengthNoise
+	options.NoiseScale = 10
+	options.Scale = 0.5
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalExpression(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByExpression
+	options.IntervalExpression = CreateIntervalAndExpression(
+		CreateIntervalClauseExpression(SplitByBrightness, 0.2, 0.8),
+		CreateIntervalNotExpression(CreateIntervalClauseExpression(SplitByHue, 0.55, 0.7)),
+		CreateIntervalClauseExpression(SplitByNoise, 0.3, 1.0))
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalExpressionWithEdge(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByExpression
+	options.IntervalExpression = CreateIntervalOrExpression(
+		CreateIntervalClauseExpression(SplitByEdgeDetection, 0, 1),
+		CreateIntervalClauseExpression(SplitByBrightness, 0.9, 1.0))
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalHysteresisGapToleranceAndMinLength(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminantLowerThreshold = 0.4
+	options.IntervalDeterminantUpperThreshold = 0.8
+	options.IntervalHysteresis = true
+	options.IntervalStayLowerThreshold = 0.2
+	options.IntervalStayUpperThreshold = 0.9
+	options.IntervalGapTolerance = 3
+	options.IntervalMinLength = 4
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalLengthMapSource(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 30
+	options.IntervalLengthSource = IntervalLengthMap
+	options.Angle = 30
+
+	auxiliary := &AuxiliaryImages{
+		IntervalLengthMapImage: mockTestBlackAndWhiteStripesImage(),
+	}
+
+	sorter, err := CreateSorterWithAuxiliaryImages(mockTestBlackAndWhiteStripesImage(), nil, auxiliary, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalLengthMapSourceShouldFailWithoutLengthMap(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 30
+	options.IntervalLengthSource = IntervalLengthMap
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalLengthNormalDistribution(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 30
+	options.IntervalLengthDistribution = DistributionNormal
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalLengthExponentialDistribution(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 30
+	options.IntervalLengthDistribution = DistributionExponential
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalLengthLogNormalDistribution(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 30
+	options.IntervalLengthDistribution = DistributionLogNormal
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalLengthPowerLawDistribution(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalLength = 30
+	options.IntervalLengthDistribution = DistributionPowerLaw
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndClusterIntervalDeterminant(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByClusters
+	options.ClusterCount = 3
+	options.ClusterIndices = []int{0, 1}
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalPaintingReverse(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalReverse
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalPaintingMirror(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalMirror
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalPaintingMedian(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalMedian
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalPaintingSmear(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalSmear
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalPaintingGradientStopsInOklab(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalGradient
+	options.GradientStops = 5
+	options.GradientColorSpace = GradientOklab
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalPaintingGradientInHsl(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalGradient
+	options.GradientColorSpace = GradientHsl
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalPaintingQuantize(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalQuantize
+	options.QuantizeLevels = 3
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndIntervalPaintingQuantizeWithPaletteAndDithering(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.IntervalPainting = IntervalQuantize
+	options.QuantizePalette = color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{128, 128, 128, 255}, color.RGBA{255, 255, 255, 255}}
+	options.QuantizeDithering = DitheringBayer8x8
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndSortStrength(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.SortStrength = 0.5
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndZeroSortStrengthShouldKeepTheOriginalOrder(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	image := createMockTestBlackAndWhiteStripesImage(16, 16)
+
+	options := GetDefaultSorterOptions()
+	options.SortStrength = 0.0
+
+	sorter, err := CreateSorter(image, nil, nil, options)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+	assert.Nil(t, err)
+
+	assert.Equal(t, utils.ImageToNrgbaImage(image).Pix, utils.ImageToNrgbaImage(result).Pix)
+}
+
+func TestDefaultOptionsAndSortStrengthWithMaskAndCycles(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.SortStrength = 0.25
+	options.UseMask = true
+	options.Cycles = 3
+	options.SortDirection = SortRandom
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), mockTestBlackAndWhiteStripesImage(), nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndChannelSplitRgb(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.ChannelSplit = ChannelSplitRgb
+	options.ChannelOptions[ChannelRed].AngleOffset = 15
+	options.ChannelOptions[ChannelBlue] = ChannelOptions{LowerThreshold: 0.2, UpperThreshold: 0.8, AngleOffset: -30}
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndChannelSplitRgbaWithSharedDeterminantAndScale(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.ChannelSplit = ChannelSplitRgba
+	options.ChannelSplitDeterminant = ChannelSortBySharedDeterminant
+	options.ChannelOptions[ChannelGreen].AngleOffset = 45
+	options.Scale = 0.5
+	options.UseMask = true
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), mockTestBlackAndWhiteStripesImage(), nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndDirectionAlternateStrips(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.DirectionScheme = DirectionAlternateStrips
+	options.SortDirection = SortDescending
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndDirectionAlternateIntervalsAndAngle(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.DirectionScheme = DirectionAlternateIntervals
+	options.IntervalLength = 20
+	options.Angle = 45
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndDirectionByNoise(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.DirectionScheme = DirectionByNoise
+	options.NoiseSeed = 7
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndDirectionByMap(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.DirectionScheme = DirectionByMap
+	options.Scale = 0.5
+
+	auxiliary := &AuxiliaryImages{
+		DirectionMapImage: mockTestBlackAndWhiteStripesImage(),
+	}
+
+	sorter, err := CreateSorterWithAuxiliaryImages(mockTestBlackAndWhiteStripesImage(), nil, auxiliary, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndDirectionByBrightness(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.DirectionScheme = DirectionByBrightness
+	options.DirectionBrightnessThreshold = 0.3
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndSeedShouldProduceReproducibleResults(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.SortDirection = Shuffle
+	options.IntervalLength = 20
+	options.IntervalLengthRandomFactor = 10
+	options.Angle = 30
+
+	results := make([]image.Image, 0, 3)
+	for _, seed := range []int64{42, 42, 43} {
+		options.Seed = &seed
+
+		sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+		assert.NotNil(t, sorter)
+		assert.Nil(t, err)
+
+		result, err := sorter.Sort()
+
+		assert.NotNil(t, result)
+		assert.Nil(t, err)
+
+		results = append(results, result)
+	}
+
+	assert.Equal(t, results[0], results[1])
+	assert.NotEqual(t, results[0], results[2])
+}
+
+func TestDefaultOptionsAndNoSeedShouldProduceNonReproducibleResults(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.SortDirection = Shuffle
+
+	results := make([]image.Image, 0, 2)
+	for i := 0; i < 2; i += 1 {
+		sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+		assert.NotNil(t, sorter)
+		assert.Nil(t, err)
+
+		result, err := sorter.Sort()
+
+		assert.NotNil(t, result)
+		assert.Nil(t, err)
+
+		results = append(results, result)
+	}
+
+	assert.NotEqual(t, results[0], results[1])
+}
+
+func TestDefaultOptionsAndCycleScheduleAngleStepAndAlternatingOrder(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.Cycles = 3
+	options.CycleSchedule.Angle = ParameterSchedule{Step: 15}
+	options.CycleSchedule.SortOrder = []SortOrder{SortHorizontal, SortVertical}
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndCycleScheduleAndScaleAndMask(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.Cycles = 2
+	options.Scale = 0.5
+	options.UseMask = true
+	options.CycleSchedule.UpperThreshold = ParameterSchedule{Values: []float64{0.9, 0.6}}
+	options.CycleSchedule.IntervalLength = ParameterSchedule{Step: 10}
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), mockTestBlackAndWhiteStripesImage(), nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndAngleCompositeLighten(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.CompositeAngles = []int{0, 60, 120}
+	options.AngleComposite = AngleCompositeLighten
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndAngleCompositeMaxWeightWithScaleAndMemoryBudget(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.CompositeAngles = []int{0, 45, 90}
+	options.AngleComposite = AngleCompositeMaxWeight
+	options.Scale = 0.5
+	options.CompositeMemoryBudget = 1
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndAngleCompositeAverageWithCycleSchedule(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.CompositeAngles = []int{0, 90}
+	options.AngleComposite = AngleCompositeAverage
+	options.Cycles = 2
+	options.CycleSchedule.Angle = ParameterSchedule{Step: 15}
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndBlendingModeSoftLightWithOpacity(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.Blending = BlendingSoftLight
+	options.BlendingOpacity = 0.5
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndBlendingModeLuminosityWithOpacityImageAndScale(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.Blending = BlendingLuminosity
+	options.Scale = 0.5
+	options.Angle = 45
+
+	auxiliary := &AuxiliaryImages{
+		BlendingOpacityImage: mockTestBlackAndWhiteStripesImage(),
+	}
+
+	sorter, err := CreateSorterWithAuxiliaryImages(mockTestBlackAndWhiteStripesImage(), nil, auxiliary, nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndSoftMaskWithFeatherRadiusAndScale(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.UseMask = true
+	options.SoftMask = true
+	options.MaskFeatherRadius = 2
+	options.MaskThreshold = 32
+	options.Scale = 0.5
+	options.Angle = 30
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), mockTestBlackAndWhiteStripesImage(), nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestDefaultOptionsAndSoftMaskWithFeatherRadiusAndCompositeAngles(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	options := GetDefaultSorterOptions()
+	options.UseMask = true
+	options.SoftMask = true
+	options.MaskFeatherRadius = 1.5
+	options.CompositeAngles = []int{0, 90}
+
+	sorter, err := CreateSorter(mockTestBlackAndWhiteStripesImage(), mockTestBlackAndWhiteStripesImage(), nil, options)
+
+	assert.NotNil(t, sorter)
+	assert.Nil(t, err)
+
+	result, err := sorter.Sort()
+
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
+
 const (
 	mock_image_width  = 5
 	mock_image_height = 5
@@ -0,0 +1,107 @@
+package sorter
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
+)
+
+// Flag representing the scheme used to choose the sort direction of each interval. The schemes other than the uniform one are alternating
+// the intervals between the sort direction specified by the options and the reversed sort direction.
+type DirectionScheme int
+
+const (
+	DirectionUniform DirectionScheme = iota
+	DirectionAlternateStrips
+	DirectionAlternateIntervals
+	DirectionByNoise
+	DirectionByMap
+	DirectionByBrightness
+)
+
+// Structure representing the state of the direction scheme of a single image strip (row or column)
+type stripDirection struct {
+	scheme              DirectionScheme
+	direction           SortDirection
+	brightnessThreshold float64
+	stripIndex          int
+	intervalIndex       int
+}
+
+// Create the direction scheme state of the strip starting at the given index of the image and iterated with the given step
+func createStripDirection(img *image.RGBA, options *SorterOptions, start, step int) *stripDirection {
+	// NOTE: The rows are iterated pixel by pixel, while the columns are iterated row by row
+	stripIndex := start / 4
+	if step == 4 {
+		stripIndex = start / img.Stride
+	}
+
+	return &stripDirection{
+		scheme:              options.DirectionScheme,
+		direction:           options.SortDirection,
+		brightnessThreshold: options.DirectionBrightnessThreshold,
+		stripIndex:          stripIndex,
+		intervalIndex:       0,
+	}
+}
+
+// Resolve the sort direction of the next interval of the strip. The interval is represented by the source image pixels between the given
+// ending index and the start index determined by the step value and the count of interval pixels.
+func (strip *stripDirection) next(src *image.RGBA, sources *sortingSources, index, step, count int) SortDirection {
+	var (
+		reversed   bool
+		startIndex int = index - step*(count-1)
+	)
+
+	switch strip.scheme {
+	case DirectionUniform:
+		return strip.direction
+	case DirectionAlternateStrips:
+		reversed = strip.stripIndex%2 == 1
+	case DirectionAlternateIntervals:
+		reversed = strip.intervalIndex%2 == 1
+	case DirectionByNoise, DirectionByMap:
+		reversed = sources.directions[startIndex/4] >= 0.5
+	case DirectionByBrightness:
+		reversed = calculateStripAverageBrightness(src, index, step, count) >= strip.brightnessThreshold
+	default:
+		panic("sorter: invalid sorter state due to a corrupted direction scheme value")
+	}
+
+	strip.intervalIndex += 1
+
+	if reversed {
+		return reverseSortDirection(strip.direction)
+	}
+
+	return strip.direction
+}
+
+// Return the opposite of the given ascending or descending sort direction
+func reverseSortDirection(direction SortDirection) SortDirection {
+	switch direction {
+	case SortAscending:
+		return SortDescending
+	case SortDescending:
+		return SortAscending
+	default:
+		panic("sorter: only the ascending and descending sort directions can be reversed")
+	}
+}
+
+// Calculate the average perceived brightness of the given count of source image pixels. The specified index is the ending index.
+func calculateStripAverageBrightness(src *image.RGBA, index, step, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for srcIndex, i := index, 0; i < count; srcIndex, i = srcIndex-step, i+1 {
+		c := color.RGBA{src.Pix[srcIndex+0], src.Pix[srcIndex+1], src.Pix[srcIndex+2], src.Pix[srcIndex+3]}
+
+		sum += utils.CalculatePerceivedBrightness(c)
+	}
+
+	return sum / float64(count)
+}
@@ -0,0 +1,98 @@
+package sorter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripSortShouldAlternateDirectionPerInterval(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminantLowerThreshold = 0.1
+	options.IntervalDeterminantUpperThreshold = 0.9
+	options.DirectionScheme = DirectionAlternateIntervals
+
+	actual := mockPerformGrayscaleStripSort(t, []uint8{100, 50, 150, 5, 100, 50, 150, 5, 100, 50, 150}, options)
+
+	assert.Equal(t, []uint8{50, 100, 150, 5, 150, 100, 50, 5, 50, 100, 150}, actual)
+}
+
+func TestStripSortShouldReverseDirectionOfBrightIntervals(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminantLowerThreshold = 0.1
+	options.IntervalDeterminantUpperThreshold = 0.9
+	options.DirectionScheme = DirectionByBrightness
+	options.DirectionBrightnessThreshold = 0.5
+
+	actual := mockPerformGrayscaleStripSort(t, []uint8{40, 30, 50, 5, 200, 220, 210}, options)
+
+	assert.Equal(t, []uint8{30, 40, 50, 5, 220, 210, 200}, actual)
+}
+
+func TestCreateStripDirectionShouldResolveStripIndex(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 5, 3))
+	options := GetDefaultSorterOptions()
+
+	assert.Equal(t, 0, createStripDirection(img, options, 0, 4).stripIndex)
+	assert.Equal(t, 2, createStripDirection(img, options, 4*5*2, 4).stripIndex)
+	assert.Equal(t, 3, createStripDirection(img, options, 4*3, 4*5).stripIndex)
+}
+
+func TestStripDirectionShouldAlternateDirectionPerStrip(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	sources := &sortingSources{mask: CreateEmptyMask()}
+
+	options := GetDefaultSorterOptions()
+	options.SortDirection = SortDescending
+	options.DirectionScheme = DirectionAlternateStrips
+
+	for y := 0; y < 4; y += 1 {
+		expected := SortDescending
+		if y%2 == 1 {
+			expected = SortAscending
+		}
+
+		direction := createStripDirection(img, options, 4*4*y, 4)
+
+		assert.Equal(t, expected, direction.next(img, sources, 4*4*y+12, 4, 4))
+		assert.Equal(t, expected, direction.next(img, sources, 4*4*y+12, 4, 4))
+	}
+}
+
+func TestStripDirectionShouldReverseDirectionBySourceValueAtIntervalStart(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	sources := &sortingSources{mask: CreateEmptyMask(), directions: []float64{0.2, 0.7, 0.1, 0.9}}
+
+	for _, scheme := range []DirectionScheme{DirectionByNoise, DirectionByMap} {
+		options := GetDefaultSorterOptions()
+		options.DirectionScheme = scheme
+
+		direction := createStripDirection(img, options, 0, 4)
+
+		assert.Equal(t, SortAscending, direction.next(img, sources, 4, 4, 2))
+		assert.Equal(t, SortDescending, direction.next(img, sources, 8, 4, 2))
+		assert.Equal(t, SortAscending, direction.next(img, sources, 12, 4, 2))
+		assert.Equal(t, SortDescending, direction.next(img, sources, 12, 4, 1))
+	}
+}
+
+func TestReverseSortDirectionShouldReverseDirection(t *testing.T) {
+	assert.Equal(t, SortDescending, reverseSortDirection(SortAscending))
+	assert.Equal(t, SortAscending, reverseSortDirection(SortDescending))
+
+	assert.Panics(t, func() { reverseSortDirection(Shuffle) })
+	assert.Panics(t, func() { reverseSortDirection(SortRandom) })
+}
+
+func TestCalculateStripAverageBrightnessShouldCalculateAverage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	img.SetRGBA(0, 0, color.RGBA{0, 0, 0, 255})
+	img.SetRGBA(1, 0, color.RGBA{255, 255, 255, 255})
+	img.SetRGBA(2, 0, color.RGBA{255, 255, 255, 255})
+
+	assert.InDelta(t, 0.5, calculateStripAverageBrightness(img, 4, 4, 2), 1e-3)
+	assert.InDelta(t, 1.0, calculateStripAverageBrightness(img, 8, 4, 2), 1e-3)
+	assert.Equal(t, 0.0, calculateStripAverageBrightness(img, 8, 4, 0))
+}
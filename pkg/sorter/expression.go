@@ -0,0 +1,510 @@
+package sorter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Flag representing the operator of a composite interval determinant expression node
+type IntervalExpressionOperator int
+
+const (
+	ExpressionClause IntervalExpressionOperator = iota
+	ExpressionAnd
+	ExpressionOr
+	ExpressionNot
+)
+
+// Structure representing a single interval determinant along with its own thresholds used as a leaf of the composite interval
+// determinant expression. The thresholds are ignored by the mask, edge and rgb channels determinants. The rgb channels determinant
+// is using the channel thresholds specified by the sorter options. A hue clause with the lower threshold greater than the upper
+// threshold is crossing the 0/360 degrees point.
+type IntervalClause struct {
+	Determinant    IntervalDeterminant
+	LowerThreshold float64
+	UpperThreshold float64
+}
+
+// Structure representing a node of the composite interval determinant expression used by the SplitByExpression interval determinant.
+// The clause nodes are evaluated as the single interval determinants, the AND and OR nodes are combining at least two operands and
+// the NOT node is negating a single operand. The expression can be represented as text, for example:
+//
+//	brightness(0.2, 0.8) and not hue(0.55, 0.7) and mask
+//
+// The NOT operator has the highest precedence, followed by the AND and the OR operator. Parentheses can be used for grouping.
+type IntervalExpression struct {
+	Operator IntervalExpressionOperator
+	Operands []*IntervalExpression
+	Clause   IntervalClause
+}
+
+// Create a new interval expression clause node with the given determinant and thresholds
+func CreateIntervalClauseExpression(determinant IntervalDeterminant, lowerThreshold, upperThreshold float64) *IntervalExpression {
+	return &IntervalExpression{
+		Operator: ExpressionClause,
+		Operands: nil,
+		Clause: IntervalClause{
+			Determinant:    determinant,
+			LowerThreshold: lowerThreshold,
+			UpperThreshold: upperThreshold,
+		},
+	}
+}
+
+// Create a new interval expression node combining the given operands with the AND operator
+func CreateIntervalAndExpression(operands ...*IntervalExpression) *IntervalExpression {
+	return &IntervalExpression{Operator: ExpressionAnd, Operands: operands}
+}
+
+// Create a new interval expression node combining the given operands with the OR operator
+func CreateIntervalOrExpression(operands ...*IntervalExpression) *IntervalExpression {
+	return &IntervalExpression{Operator: ExpressionOr, Operands: operands}
+}
+
+// Create a new interval expression node negating the given operand
+func CreateIntervalNotExpression(operand *IntervalExpression) *IntervalExpression {
+	return &IntervalExpression{Operator: ExpressionNot, Operands: []*IntervalExpression{operand}}
+}
+
+// Return a boolean value indicating if the given interval expression is valid and a string containing validation failure message
+func (expression *IntervalExpression) AreValid() (bool, string) {
+	if expression == nil {
+		return false, "the interval expression must not be empty"
+	}
+
+	var (
+		usesMask bool
+		usesEdge bool
+	)
+
+	err := expression.walk(func(node *IntervalExpression) error {
+		switch node.Operator {
+		case ExpressionClause:
+			{
+				if len(node.Operands) != 0 {
+					return errors.New("the interval expression clause must not have operands")
+				}
+
+				switch node.Clause.Determinant {
+				case SplitByBrightness, SplitByHue, SplitBySaturation, SplitByAbsoluteColor, SplitByMap, SplitByRgbChannels, SplitByNoise:
+				case SplitByMask:
+					usesMask = true
+				case SplitByEdgeDetection:
+					usesEdge = true
+				default:
+					return errors.New("the interval expression clause supports only the brightness, hue, saturation, mask, absolute, edge, map, rgb and noise determinants")
+				}
+
+				lower, upper := node.Clause.LowerThreshold, node.Clause.UpperThreshold
+				if lower < 0.0 || lower > 1.0 || upper < 0.0 || upper > 1.0 {
+					return errors.New("the interval expression clause thresholds must be between values 0 and 1")
+				}
+
+				if lower > upper && node.Clause.Determinant != SplitByHue {
+					return errors.New("the interval expression clause lower threshold must no be greater than the upper one unless the hue determinant is used")
+				}
+			}
+		case ExpressionAnd, ExpressionOr:
+			{
+				if len(node.Operands) < 2 {
+					return errors.New("the interval expression and/or operators require at least two operands")
+				}
+			}
+		case ExpressionNot:
+			{
+				if len(node.Operands) != 1 {
+					return errors.New("the interval expression not operator requires exactly one operand")
+				}
+			}
+		default:
+			return errors.New("the interval expression contains a invalid operator")
+		}
+
+		for _, operand := range node.Operands {
+			if operand == nil {
+				return errors.New("the interval expression operands must not be empty")
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return false, err.Error()
+	}
+
+	if usesMask && usesEdge {
+		return false, "the interval expression can not use the mask and edge determinants at the same time"
+	}
+
+	return true, ""
+}
+
+// Return a boolean value indicating if the given interval determinant is used by any clause of the expression
+func (expression *IntervalExpression) usesDeterminant(determinant IntervalDeterminant) bool {
+	if expression == nil {
+		return false
+	}
+
+	found := errors.New("found")
+	return expression.walk(func(node *IntervalExpression) error {
+		if node.Operator == ExpressionClause && node.Clause.Determinant == determinant {
+			return found
+		}
+
+		return nil
+	}) == found
+}
+
+// Return a boolean value indicating if any hue clause of the expression is crossing the 0/360 degrees point
+func (expression *IntervalExpression) usesHueWrapAround() bool {
+	if expression == nil {
+		return false
+	}
+
+	found := errors.New("found")
+	return expression.walk(func(node *IntervalExpression) error {
+		if node.Operator == ExpressionClause && node.Clause.Determinant == SplitByHue && node.Clause.LowerThreshold > node.Clause.UpperThreshold {
+			return found
+		}
+
+		return nil
+	}) == found
+}
+
+// Function used to visit all nodes of the expression in depth-first order. The walk is stopped on the first returned error.
+func (expression *IntervalExpression) walk(visit func(node *IntervalExpression) error) error {
+	if err := visit(expression); err != nil {
+		return err
+	}
+
+	for _, operand := range expression.Operands {
+		if operand == nil {
+			continue
+		}
+
+		if err := operand.walk(visit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Return the text representation of the interval expression which can be parsed using the ParseIntervalExpression function
+func (expression *IntervalExpression) String() string {
+	if expression == nil {
+		return ""
+	}
+
+	switch expression.Operator {
+	case ExpressionClause:
+		{
+			name, ok := intervalExpressionDeterminantNames[expression.Clause.Determinant]
+			if !ok {
+				return "invalid"
+			}
+
+			switch expression.Clause.Determinant {
+			case SplitByMask, SplitByEdgeDetection, SplitByRgbChannels:
+				return name
+			default:
+				return fmt.Sprintf("%s(%s, %s)", name,
+					strconv.FormatFloat(expression.Clause.LowerThreshold, 'f', -1, 64),
+					strconv.FormatFloat(expression.Clause.UpperThreshold, 'f', -1, 64))
+			}
+		}
+	case ExpressionAnd, ExpressionOr:
+		{
+			separator := " and "
+			if expression.Operator == ExpressionOr {
+				separator = " or "
+			}
+
+			operands := make([]string, 0, len(expression.Operands))
+			for _, operand := range expression.Operands {
+				operands = append(operands, operand.operandString())
+			}
+
+			return strings.Join(operands, separator)
+		}
+	case ExpressionNot:
+		{
+			if len(expression.Operands) != 1 {
+				return "not ()"
+			}
+
+			return "not " + expression.Operands[0].operandString()
+		}
+	default:
+		return "invalid"
+	}
+}
+
+// Return the text representation of the expression used as a operand, the and/or operands are wrapped in parentheses
+func (expression *IntervalExpression) operandString() string {
+	if expression != nil && (expression.Operator == ExpressionAnd || expression.Operator == ExpressionOr) {
+		return "(" + expression.String() + ")"
+	}
+
+	return expression.String()
+}
+
+// Implementation of the encoding.TextMarshaler interface allowing to store the expression as text in the configuration files
+func (expression *IntervalExpression) MarshalText() ([]byte, error) {
+	if valid, msg := expression.AreValid(); !valid {
+		return nil, fmt.Errorf("sorter: %s", msg)
+	}
+
+	return []byte(expression.String()), nil
+}
+
+// Implementation of the encoding.TextUnmarshaler interface allowing to read the expression as text from the configuration files
+func (expression *IntervalExpression) UnmarshalText(text []byte) error {
+	parsed, err := ParseIntervalExpression(string(text))
+	if err != nil {
+		return err
+	}
+
+	*expression = *parsed
+	return nil
+}
+
+var intervalExpressionDeterminantNames = map[IntervalDeterminant]string{
+	SplitByBrightness:    "brightness",
+	SplitByHue:           "hue",
+	SplitBySaturation:    "saturation",
+	SplitByMask:          "mask",
+	SplitByAbsoluteColor: "absolute",
+	SplitByEdgeDetection: "edge",
+	SplitByMap:           "map",
+	SplitByRgbChannels:   "rgb",
+	SplitByNoise:         "noise",
+}
+
+// Parse the text representation of the composite interval determinant expression. The clauses are written as the determinant name
+// followed by the lower and upper threshold in parentheses, for example brightness(0.2, 0.8). The thresholds can be omitted, which is
+// equivalent to the full 0.0 - 1.0 range. The clauses are combined using the and, or, not operators and grouped with parentheses.
+func ParseIntervalExpression(text string) (*IntervalExpression, error) {
+	parser := &intervalExpressionParser{tokens: tokenizeIntervalExpression(text)}
+
+	expression, err := parser.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("sorter: failed to parse the interval expression: %w", err)
+	}
+
+	if token, ok := parser.peek(); ok {
+		return nil, fmt.Errorf("sorter: failed to parse the interval expression: unexpected token %q", token)
+	}
+
+	if valid, msg := expression.AreValid(); !valid {
+		return nil, fmt.Errorf("sorter: %s", msg)
+	}
+
+	return expression, nil
+}
+
+// Function used to split the expression text into name, number and punctuation tokens
+func tokenizeIntervalExpression(text string) []string {
+	tokens := make([]string, 0)
+	runes := []rune(text)
+
+	for index := 0; index < len(runes); {
+		r := runes[index]
+
+		switch {
+		case unicode.IsSpace(r):
+			index += 1
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, string(r))
+			index += 1
+		default:
+			start := index
+			for index < len(runes) && !unicode.IsSpace(runes[index]) && !strings.ContainsRune("(),", runes[index]) {
+				index += 1
+			}
+
+			tokens = append(tokens, strings.ToLower(string(runes[start:index])))
+		}
+	}
+
+	return tokens
+}
+
+// Recursive descent parser of the interval expression tokens
+type intervalExpressionParser struct {
+	tokens []string
+	index  int
+}
+
+func (parser *intervalExpressionParser) peek() (string, bool) {
+	if parser.index >= len(parser.tokens) {
+		return "", false
+	}
+
+	return parser.tokens[parser.index], true
+}
+
+func (parser *intervalExpressionParser) next() (string, bool) {
+	token, ok := parser.peek()
+	if ok {
+		parser.index += 1
+	}
+
+	return token, ok
+}
+
+func (parser *intervalExpressionParser) expect(expected string) error {
+	token, ok := parser.next()
+	if !ok {
+		return fmt.Errorf("expected %q but the expression has ended", expected)
+	}
+
+	if token != expected {
+		return fmt.Errorf("expected %q but found %q", expected, token)
+	}
+
+	return nil
+}
+
+func (parser *intervalExpressionParser) parseOr() (*IntervalExpression, error) {
+	return parser.parseBinary("or", ExpressionOr, parser.parseAnd)
+}
+
+func (parser *intervalExpressionParser) parseAnd() (*IntervalExpression, error) {
+	return parser.parseBinary("and", ExpressionAnd, parser.parseUnary)
+}
+
+func (parser *intervalExpressionParser) parseBinary(keyword string, operator IntervalExpressionOperator, parseOperand func() (*IntervalExpression, error)) (*IntervalExpression, error) {
+	operand, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []*IntervalExpression{operand}
+	for {
+		if token, ok := parser.peek(); !ok || token != keyword {
+			break
+		}
+
+		parser.index += 1
+
+		if operand, err = parseOperand(); err != nil {
+			return nil, err
+		}
+
+		operands = append(operands, operand)
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+
+	return &IntervalExpression{Operator: operator, Operands: operands}, nil
+}
+
+func (parser *intervalExpressionParser) parseUnary() (*IntervalExpression, error) {
+	token, ok := parser.peek()
+	if !ok {
+		return nil, errors.New("unexpected end of the expression")
+	}
+
+	switch token {
+	case "not":
+		{
+			parser.index += 1
+
+			operand, err := parser.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+
+			return CreateIntervalNotExpression(operand), nil
+		}
+	case "(":
+		{
+			parser.index += 1
+
+			expression, err := parser.parseOr()
+			if err != nil {
+				return nil, err
+			}
+
+			if err := parser.expect(")"); err != nil {
+				return nil, err
+			}
+
+			return expression, nil
+		}
+	default:
+		return parser.parseClause()
+	}
+}
+
+func (parser *intervalExpressionParser) parseClause() (*IntervalExpression, error) {
+	name, _ := parser.next()
+
+	var (
+		determinant IntervalDeterminant
+		found       bool = false
+	)
+
+	for d, n := range intervalExpressionDeterminantNames {
+		if n == name {
+			determinant, found = d, true
+			break
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("invalid interval determinant %q", name)
+	}
+
+	clause := CreateIntervalClauseExpression(determinant, 0.0, 1.0)
+
+	if token, ok := parser.peek(); !ok || token != "(" {
+		return clause, nil
+	}
+
+	parser.index += 1
+
+	lower, err := parser.parseNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := parser.expect(","); err != nil {
+		return nil, err
+	}
+
+	upper, err := parser.parseNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := parser.expect(")"); err != nil {
+		return nil, err
+	}
+
+	clause.Clause.LowerThreshold = lower
+	clause.Clause.UpperThreshold = upper
+
+	return clause, nil
+}
+
+func (parser *intervalExpressionParser) parseNumber() (float64, error) {
+	token, ok := parser.next()
+	if !ok {
+		return 0, errors.New("expected a threshold but the expression has ended")
+	}
+
+	value, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid threshold %q", token)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,145 @@
+package sorter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIntervalExpressionShouldParseClause(t *testing.T) {
+	expression, err := ParseIntervalExpression("brightness(0.2, 0.8)")
+
+	assert.Nil(t, err)
+	assert.Equal(t, CreateIntervalClauseExpression(SplitByBrightness, 0.2, 0.8), expression)
+}
+
+func TestParseIntervalExpressionShouldParseClauseWithoutThresholds(t *testing.T) {
+	expression, err := ParseIntervalExpression("MASK")
+
+	assert.Nil(t, err)
+	assert.Equal(t, CreateIntervalClauseExpression(SplitByMask, 0.0, 1.0), expression)
+}
+
+func TestParseIntervalExpressionShouldRespectOperatorPrecedence(t *testing.T) {
+	expression, err := ParseIntervalExpression("brightness(0.2, 0.8) and not hue(0.55, 0.7) and mask or saturation(0.5, 1)")
+
+	expected := CreateIntervalOrExpression(
+		CreateIntervalAndExpression(
+			CreateIntervalClauseExpression(SplitByBrightness, 0.2, 0.8),
+			CreateIntervalNotExpression(CreateIntervalClauseExpression(SplitByHue, 0.55, 0.7)),
+			CreateIntervalClauseExpression(SplitByMask, 0.0, 1.0)),
+		CreateIntervalClauseExpression(SplitBySaturation, 0.5, 1.0))
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, expression)
+}
+
+func TestParseIntervalExpressionShouldParseParentheses(t *testing.T) {
+	expression, err := ParseIntervalExpression("not (noise(0, 0.5) or map(0.1, 0.2)) and rgb")
+
+	expected := CreateIntervalAndExpression(
+		CreateIntervalNotExpression(CreateIntervalOrExpression(
+			CreateIntervalClauseExpression(SplitByNoise, 0.0, 0.5),
+			CreateIntervalClauseExpression(SplitByMap, 0.1, 0.2))),
+		CreateIntervalClauseExpression(SplitByRgbChannels, 0.0, 1.0))
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, expression)
+}
+
+func TestParseIntervalExpressionShouldNotParseInvalidExpressions(t *testing.T) {
+	cases := []string{
+		"",
+		"brightness and",
+		"unknown(0.1, 0.2)",
+		"brightness(0.1 0.2)",
+		"brightness(0.1, x)",
+		"(brightness",
+		"brightness)",
+		"brightness(0.8, 0.2)",
+		"brightness(0.1, 1.2)",
+		"mask and edge",
+		"difference",
+	}
+
+	for _, text := range cases {
+		expression, err := ParseIntervalExpression(text)
+
+		assert.Nil(t, expression, text)
+		assert.NotNil(t, err, text)
+	}
+}
+
+func TestParseIntervalExpressionShouldAllowHueWrapAroundClause(t *testing.T) {
+	expression, err := ParseIntervalExpression("hue(0.9, 0.1)")
+
+	assert.Nil(t, err)
+	assert.Equal(t, CreateIntervalClauseExpression(SplitByHue, 0.9, 0.1), expression)
+}
+
+func TestIntervalExpressionStringShouldBeParsable(t *testing.T) {
+	texts := []string{
+		"brightness(0.2, 0.8) and not hue(0.55, 0.7) and mask",
+		"not (noise(0, 0.5) or map(0.1, 0.2)) and rgb",
+		"edge or absolute(0.25, 0.75)",
+	}
+
+	for _, text := range texts {
+		expression, err := ParseIntervalExpression(text)
+		assert.Nil(t, err)
+
+		reparsed, err := ParseIntervalExpression(expression.String())
+		assert.Nil(t, err)
+
+		assert.Equal(t, expression, reparsed)
+	}
+}
+
+func TestIntervalExpressionStringShouldWrapNestedOperands(t *testing.T) {
+	expression := CreateIntervalAndExpression(
+		CreateIntervalNotExpression(CreateIntervalOrExpression(
+			CreateIntervalClauseExpression(SplitByNoise, 0.0, 0.5),
+			CreateIntervalClauseExpression(SplitByMap, 0.1, 0.2))),
+		CreateIntervalClauseExpression(SplitByRgbChannels, 0.0, 1.0))
+
+	assert.Equal(t, "not (noise(0, 0.5) or map(0.1, 0.2)) and rgb", expression.String())
+}
+
+func TestIntervalExpressionShouldMarshalAndUnmarshalAsText(t *testing.T) {
+	type config struct {
+		Expression *IntervalExpression `json:"expression"`
+	}
+
+	var parsed config
+	err := json.Unmarshal([]byte(`{"expression": "brightness(0.2, 0.8) and not mask"}`), &parsed)
+
+	assert.Nil(t, err)
+	assert.Equal(t, CreateIntervalAndExpression(
+		CreateIntervalClauseExpression(SplitByBrightness, 0.2, 0.8),
+		CreateIntervalNotExpression(CreateIntervalClauseExpression(SplitByMask, 0.0, 1.0))), parsed.Expression)
+
+	data, err := json.Marshal(parsed)
+
+	assert.Nil(t, err)
+	assert.Equal(t, `{"expression":"brightness(0.2, 0.8) and not mask"}`, string(data))
+}
+
+func TestIntervalExpressionShouldNotValidateInvalidOperands(t *testing.T) {
+	cases := []*IntervalExpression{
+		nil,
+		CreateIntervalAndExpression(CreateIntervalClauseExpression(SplitByHue, 0, 1)),
+		CreateIntervalOrExpression(),
+		CreateIntervalNotExpression(nil),
+		{Operator: ExpressionNot},
+		{Operator: -1},
+		CreateIntervalClauseExpression(SplitByExpression, 0, 1),
+	}
+
+	for _, expression := range cases {
+		valid, msg := expression.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
@@ -19,12 +19,30 @@ type Interval interface {
 	// Add a RGBA color to the given interval
 	Append(color color.RGBA) error
 
+	// Add a RGBA color to the given interval with a explicitly provided weight instead of the one calculated by the weight determinant
+	AppendWithWeight(color color.RGBA, weight float64) error
+
 	// Get the count of colors stored in the interval
 	Count() int
 
 	// Get a boolean value representing the presence of elements in the given interval
 	Any() bool
 
+	// Remove all colors from the interval without sorting them
+	Reset()
+
+	// Specify the count of the gradient stops and the interpolation color space used by the gradient interval painting. The zero
+	// stops count is representing the three-point quadratic blend of the first, middle and last color of the sorted interval.
+	SetGradient(stops int, space GradientColorSpace)
+
+	// Specify the sort strength in range from 0.0 to 1.0, which is representing the part of the way each color is moved from its
+	// original position toward its sorted position. The strength of 1.0 is representing the full sort.
+	SetSortStrength(strength float64)
+
+	// Specify the source of the random decisions (random direction and shuffle) made while sorting the interval. The intervals are
+	// using a time-seeded source by default.
+	SetRandom(random *rand.Rand)
+
 	// Sort all interval colors by weight in the specified direction and return the interval as a new slice of RGBA
 	// colors. The internal interval items collection will be cleared after the sort.
 	Sort(direction SortDirection, painting IntervalPainting) []color.RGBA
@@ -37,6 +55,10 @@ type Interval interface {
 type genericInterval[T int | float64] struct {
 	items                 []genericIntervalItem[T]
 	weightDeterminantFunc func(color.RGBA) T
+	gradientStops         int
+	gradientInterpolation func(a, b color.RGBA, t float64) color.RGBA
+	sortStrength          float64
+	random                *rand.Rand
 }
 
 type genericIntervalItem[T int | float64] struct {
@@ -53,6 +75,10 @@ func CreateValueWeightInterval(weightDeterminantFunc func(color.RGBA) int) Inter
 	return &genericInterval[int]{
 		items:                 make([]genericIntervalItem[int], 0, defaultIntervalCapacity),
 		weightDeterminantFunc: weightDeterminantFunc,
+		gradientStops:         0,
+		gradientInterpolation: utils.InterpolateRgba,
+		sortStrength:          1.0,
+		random:                rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
@@ -65,6 +91,10 @@ func CreateNormalizedWeightInterval(weightDeterminantFunc func(color.RGBA) float
 	return &genericInterval[float64]{
 		items:                 make([]genericIntervalItem[float64], 0, defaultIntervalCapacity),
 		weightDeterminantFunc: weightDeterminantFunc,
+		gradientStops:         0,
+		gradientInterpolation: utils.InterpolateRgba,
+		sortStrength:          1.0,
+		random:                rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
@@ -115,6 +145,13 @@ func CreateInterval(sort SortDeterminant) Interval {
 				return int(c.B)
 			})
 		}
+	case SortByKeyImage, SortByNoise:
+		{
+			// NOTE: The key image and noise weights are provided via AppendWithWeight, the brightness is only used as a fallback
+			return CreateNormalizedWeightInterval(func(c color.RGBA) float64 {
+				return utils.CalculatePerceivedBrightness(c)
+			})
+		}
 	default:
 		panic("sorter: invalid sorter state due to a corrupted sorter weight determinant function value")
 	}
@@ -130,6 +167,15 @@ func (interval *genericInterval[T]) Append(color color.RGBA) error {
 	return nil
 }
 
+func (interval *genericInterval[T]) AppendWithWeight(color color.RGBA, weight float64) error {
+	interval.items = append(interval.items, genericIntervalItem[T]{
+		color:  color,
+		weight: T(weight),
+	})
+
+	return nil
+}
+
 func (interval *genericInterval[T]) Count() int {
 	return len(interval.items)
 }
@@ -138,6 +184,39 @@ func (interval *genericInterval[T]) Any() bool {
 	return len(interval.items) > 0
 }
 
+func (interval *genericInterval[T]) Reset() {
+	interval.items = interval.items[:0]
+}
+
+func (interval *genericInterval[T]) SetGradient(stops int, space GradientColorSpace) {
+	switch space {
+	case GradientSrgb:
+		interval.gradientInterpolation = utils.InterpolateRgba
+	case GradientLinearRgb:
+		interval.gradientInterpolation = utils.InterpolateRgbaLinear
+	case GradientOklab:
+		interval.gradientInterpolation = utils.InterpolateRgbaOklab
+	case GradientHsl:
+		interval.gradientInterpolation = utils.InterpolateRgbaHsl
+	default:
+		panic("sorter: undefined gradient color space specified")
+	}
+
+	interval.gradientStops = stops
+}
+
+func (interval *genericInterval[T]) SetSortStrength(strength float64) {
+	interval.sortStrength = strength
+}
+
+func (interval *genericInterval[T]) SetRandom(random *rand.Rand) {
+	if random == nil {
+		panic("sorter: the provided random source is nil")
+	}
+
+	interval.random = random
+}
+
 func (interval *genericInterval[T]) Sort(direction SortDirection, painting IntervalPainting) []color.RGBA {
 	buffer := make([]color.RGBA, 0, interval.Count())
 
@@ -191,51 +270,94 @@ func (interval *genericInterval[T]) SortToBuffer(direction SortDirection, painti
 
 			return
 		}
-	case IntervalFill:
+	case IntervalFill, IntervalQuantize:
 		{
-			if direction == SortRandom {
-				if utils.CIntn(2) == 1 {
-					direction = SortAscending
+			// NOTE: The quantization of the sorted colors is performed while drawing the interval, because the ordered dithering depends on the pixel position
+			interval.sortItems(direction)
+
+			for i := 0; i < interval.Count(); i += 1 {
+				*buffer = append(*buffer, interval.items[i].color)
+			}
+
+			return
+		}
+	case IntervalReverse:
+		{
+			for i := interval.Count() - 1; i >= 0; i -= 1 {
+				*buffer = append(*buffer, interval.items[i].color)
+			}
+
+			return
+		}
+	case IntervalMirror:
+		{
+			interval.sortItems(direction)
+
+			// NOTE: The sorted items are placed alternately from both ends towards the middle, so the first items of the sorted
+			// sequence are placed at the interval ends and the last items are placed in the middle of the interval
+			offset := len(*buffer)
+			*buffer = append(*buffer, make([]color.RGBA, interval.Count())...)
+
+			left, right := offset, offset+interval.Count()-1
+			for i := 0; i < interval.Count(); i += 1 {
+				if i%2 == 0 {
+					(*buffer)[left] = interval.items[i].color
+					left += 1
 				} else {
-					direction = SortDescending
+					(*buffer)[right] = interval.items[i].color
+					right -= 1
 				}
 			}
 
-			switch direction {
-			case SortAscending:
-				{
-					sort.Slice(interval.items, func(i, j int) bool {
-						return interval.items[i].weight < interval.items[j].weight
-					})
-				}
-			case SortDescending:
-				{
-					sort.Slice(interval.items, func(i, j int) bool {
-						return interval.items[i].weight > interval.items[j].weight
-					})
-				}
-			case Shuffle:
-				{
-					// TODO: Implement a math/rand independent shuffle
-					random := rand.New(rand.NewSource(time.Now().UnixNano()))
-					random.Shuffle(len(interval.items), func(i, j int) {
-						interval.items[i], interval.items[j] = interval.items[j], interval.items[i]
-					})
-				}
-			default:
-				panic("sorter: undefined sort direction specified")
+			return
+		}
+	case IntervalMedian:
+		{
+			channels := make([][]uint8, 4)
+			for index := range channels {
+				channels[index] = make([]uint8, 0, interval.Count())
+			}
+
+			for _, item := range interval.items {
+				channels[0] = append(channels[0], item.color.R)
+				channels[1] = append(channels[1], item.color.G)
+				channels[2] = append(channels[2], item.color.B)
+				channels[3] = append(channels[3], item.color.A)
+			}
+
+			median := color.RGBA{
+				R: calculateChannelMedian(channels[0]),
+				G: calculateChannelMedian(channels[1]),
+				B: calculateChannelMedian(channels[2]),
+				A: calculateChannelMedian(channels[3]),
 			}
 
+			for range interval.items {
+				*buffer = append(*buffer, median)
+			}
+
+			return
+		}
+	case IntervalSmear:
+		{
 			for i := 0; i < interval.Count(); i += 1 {
-				*buffer = append(*buffer, interval.items[i].color)
+				t := float64(i) / float64(interval.Count()-1)
+
+				*buffer = append(*buffer, utils.InterpolateRgba(interval.items[0].color, interval.items[i].color, t))
 			}
 
 			return
 		}
 	case IntervalGradient:
 		{
+			if interval.gradientStops != 0 {
+				interval.sortItems(direction)
+				interval.paintGradientStops(buffer)
+				return
+			}
+
 			if direction == SortRandom {
-				if utils.CIntn(2) == 1 {
+				if interval.random.Intn(2) == 1 {
 					direction = SortAscending
 				} else {
 					direction = SortDescending
@@ -283,17 +405,15 @@ func (interval *genericInterval[T]) SortToBuffer(direction SortDirection, painti
 					for i := 0; i < interval.Count(); i += 1 {
 						t := float64(i) / float64(interval.Count()-1)
 
-						abColorLerp := utils.InterpolateRgba(a.color, b.color, t)
-						bcColorLerp := utils.InterpolateRgba(b.color, c.color, t)
+						abColorLerp := interval.gradientInterpolation(a.color, b.color, t)
+						bcColorLerp := interval.gradientInterpolation(b.color, c.color, t)
 
-						*buffer = append(*buffer, utils.InterpolateRgba(abColorLerp, bcColorLerp, t))
+						*buffer = append(*buffer, interval.gradientInterpolation(abColorLerp, bcColorLerp, t))
 					}
 				}
 			case Shuffle:
 				{
-					// TODO: Implement a meth/rand independent shuffle
-					random := rand.New(rand.NewSource(time.Now().UnixNano()))
-					random.Shuffle(len(interval.items), func(i, j int) {
+					interval.random.Shuffle(len(interval.items), func(i, j int) {
 						interval.items[i], interval.items[j] = interval.items[j], interval.items[i]
 					})
 
@@ -304,10 +424,10 @@ func (interval *genericInterval[T]) SortToBuffer(direction SortDirection, painti
 					for i := 0; i < interval.Count(); i += 1 {
 						t := float64(i) / float64(interval.Count()-1)
 
-						abColorLerp := utils.InterpolateRgba(a, b, t)
-						bcColorLerp := utils.InterpolateRgba(b, c, t)
+						abColorLerp := interval.gradientInterpolation(a, b, t)
+						bcColorLerp := interval.gradientInterpolation(b, c, t)
 
-						*buffer = append(*buffer, utils.InterpolateRgba(abColorLerp, bcColorLerp, t))
+						*buffer = append(*buffer, interval.gradientInterpolation(abColorLerp, bcColorLerp, t))
 					}
 				}
 			default:
@@ -318,3 +438,119 @@ func (interval *genericInterval[T]) SortToBuffer(direction SortDirection, painti
 		panic("sorter: undefined interval painting specified")
 	}
 }
+
+// Sort the interval items by weight in the specified direction. The random direction is resolved to ascending or descending. If the
+// sort strength is lower than 1.0 the items are only moved part of the way from their original positions toward the sorted positions.
+func (interval *genericInterval[T]) sortItems(direction SortDirection) {
+	var original []genericIntervalItem[T] = nil
+	if interval.sortStrength < 1.0 {
+		original = make([]genericIntervalItem[T], len(interval.items))
+		copy(original, interval.items)
+
+		defer interval.interpolateItemsOrder(original)
+	}
+
+	if direction == SortRandom {
+		if interval.random.Intn(2) == 1 {
+			direction = SortAscending
+		} else {
+			direction = SortDescending
+		}
+	}
+
+	switch direction {
+	case SortAscending:
+		{
+			sort.Slice(interval.items, func(i, j int) bool {
+				return interval.items[i].weight < interval.items[j].weight
+			})
+		}
+	case SortDescending:
+		{
+			sort.Slice(interval.items, func(i, j int) bool {
+				return interval.items[i].weight > interval.items[j].weight
+			})
+		}
+	case Shuffle:
+		{
+			interval.random.Shuffle(len(interval.items), func(i, j int) {
+				interval.items[i], interval.items[j] = interval.items[j], interval.items[i]
+			})
+		}
+	default:
+		panic("sorter: undefined sort direction specified")
+	}
+}
+
+// Reorder the sorted interval items by interpolating the position of each item between its position in the given original items and its
+// sorted position according to the sort strength. The items are ordered by the interpolated positions and the ties are resolved according
+// to the sorted positions, so the original order is kept for the strength of 0.0 and the sorted order is kept for the strength of 1.0.
+func (interval *genericInterval[T]) interpolateItemsOrder(original []genericIntervalItem[T]) {
+	// NOTE: Equal items are indistinguishable, so the sorted positions of the equal items are assigned in the original order
+	sortedPositions := make(map[genericIntervalItem[T]][]int, len(interval.items))
+	for index, item := range interval.items {
+		sortedPositions[item] = append(sortedPositions[item], index)
+	}
+
+	ranks := make([]int, len(original))
+	for index, item := range original {
+		ranks[index] = sortedPositions[item][0]
+		sortedPositions[item] = sortedPositions[item][1:]
+	}
+
+	positions := make([]float64, len(original))
+	order := make([]int, len(original))
+	for index := range original {
+		positions[index] = utils.Lerp(float64(index), float64(ranks[index]), interval.sortStrength)
+		order[index] = index
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		if positions[order[i]] != positions[order[j]] {
+			return positions[order[i]] < positions[order[j]]
+		}
+
+		return ranks[order[i]] < ranks[order[j]]
+	})
+
+	for index, originalIndex := range order {
+		interval.items[index] = original[originalIndex]
+	}
+}
+
+// Paint the gradient of the interval items by interpolating between the gradient stops taken from evenly spaced quantiles of the
+// ordered interval items
+func (interval *genericInterval[T]) paintGradientStops(buffer *[]color.RGBA) {
+	count := interval.Count()
+	stops := make([]color.RGBA, interval.gradientStops)
+	for index := range stops {
+		itemIndex := int(math.Round(float64(index) * float64(count-1) / float64(len(stops)-1)))
+		stops[index] = interval.items[itemIndex].color
+	}
+
+	for i := 0; i < count; i += 1 {
+		position := float64(i) / float64(count-1) * float64(len(stops)-1)
+
+		segment := int(position)
+		if segment >= len(stops)-1 {
+			segment = len(stops) - 2
+		}
+
+		*buffer = append(*buffer, interval.gradientInterpolation(stops[segment], stops[segment+1], position-float64(segment)))
+	}
+}
+
+// Calculate the median of the given channel values. The median of a even count of values is the mean of the two middle values.
+// The provided slice is sorted in place.
+func calculateChannelMedian(values []uint8) uint8 {
+	sort.Slice(values, func(i, j int) bool {
+		return values[i] < values[j]
+	})
+
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return uint8((int(values[middle-1]) + int(values[middle])) / 2)
+	}
+
+	return values[middle]
+}
@@ -2,6 +2,7 @@ package sorter
 
 import (
 	"image/color"
+	"math/rand"
 	"sort"
 	"testing"
 
@@ -44,6 +45,16 @@ func TestCreateIntervalShouldCreateIntervalForSortByBlueChannel(t *testing.T) {
 	assert.NotNil(t, interval)
 }
 
+func TestCreateIntervalShouldCreateIntervalForSortByKeyImage(t *testing.T) {
+	interval := CreateInterval(SortByKeyImage)
+	assert.NotNil(t, interval)
+}
+
+func TestCreateIntervalShouldCreateIntervalForSortByNoise(t *testing.T) {
+	interval := CreateInterval(SortByNoise)
+	assert.NotNil(t, interval)
+}
+
 func TestCreateIntervalShouldPanicForInvalidSortDeterminant(t *testing.T) {
 	assert.Panics(t, func() {
 		CreateInterval(-1)
@@ -113,6 +124,7 @@ func TestValueWeightIntervalShouldPanicForInvalidDirectionAndPaintingValues(t *t
 	}{
 		{-100, IntervalFill},
 		{-100, IntervalGradient},
+		{-100, IntervalMirror},
 		{-100, -100},
 	}
 
@@ -246,6 +258,38 @@ func TestValueWeightIntervalShouldSortAscendingPaintFill(t *testing.T) {
 	assert.Equal(t, expectedResult, actualResult)
 }
 
+func TestNormalizedWeightIntervalShouldSortAscendingByExplicitWeightsPaintFill(t *testing.T) {
+	interval := CreateNormalizedWeightInterval(mockTestNormalizedWeightDeterminant())
+	assert.NotNil(t, interval)
+
+	colors := []color.RGBA{
+		{16, 16, 16, 255},
+		{0, 0, 0, 255},
+		{255, 255, 255, 255},
+		{100, 100, 100, 255},
+	}
+
+	weights := []float64{0.9, 0.1, 0.5, 0.0}
+
+	expectedResult := []color.RGBA{
+		{100, 100, 100, 255},
+		{0, 0, 0, 255},
+		{255, 255, 255, 255},
+		{16, 16, 16, 255},
+	}
+
+	for index, color := range colors {
+		err := interval.AppendWithWeight(color, weights[index])
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, len(colors), interval.Count())
+
+	actualResult := interval.Sort(SortAscending, IntervalFill)
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
 func TestValueWeightIntervalShouldSortDescendingPaintFill(t *testing.T) {
 	interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
 	assert.NotNil(t, interval)
@@ -303,6 +347,36 @@ func TestValueWeightIntervalShouldShufflePaintFill(t *testing.T) {
 	assert.ElementsMatch(t, colors, actualResult)
 }
 
+func TestValueWeightIntervalShouldShuffleDeterministicallyForTheSameRandomSource(t *testing.T) {
+	colors := make([]color.RGBA, 0, 32)
+	for i := 0; i < 32; i += 1 {
+		colors = append(colors, color.RGBA{uint8(i * 8), uint8(i * 8), uint8(i * 8), 255})
+	}
+
+	results := make([][]color.RGBA, 0, 2)
+	for i := 0; i < 2; i += 1 {
+		interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+		interval.SetRandom(rand.New(rand.NewSource(11)))
+
+		for _, color := range colors {
+			err := interval.Append(color)
+			assert.Nil(t, err)
+		}
+
+		results = append(results, interval.Sort(Shuffle, IntervalFill))
+	}
+
+	assert.Equal(t, results[0], results[1])
+	assert.ElementsMatch(t, colors, results[0])
+	assert.NotEqual(t, colors, results[0])
+}
+
+func TestIntervalShouldNotAcceptNilRandomSource(t *testing.T) {
+	interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+
+	assert.Panics(t, func() { interval.SetRandom(nil) })
+}
+
 func TestValueWeightIntervalShouldSortRandomPaintFill(t *testing.T) {
 	interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
 	assert.NotNil(t, interval)
@@ -471,6 +545,320 @@ func TestValueWeightIntervalShouldSortRandomPaintGradient(t *testing.T) {
 	assert.False(t, !isSortedAscending && !isSortedDescending)
 }
 
+func TestValueWeightIntervalShouldPaintReverse(t *testing.T) {
+	cases := []SortDirection{
+		SortAscending,
+		SortDescending,
+		Shuffle,
+		SortRandom,
+	}
+
+	for _, sortDirection := range cases {
+		interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+		assert.NotNil(t, interval)
+
+		colors := []color.RGBA{
+			{16, 16, 16, 255},
+			{0, 0, 0, 255},
+			{255, 255, 255, 255},
+			{100, 100, 100, 255},
+		}
+
+		expectedResult := []color.RGBA{
+			{100, 100, 100, 255},
+			{255, 255, 255, 255},
+			{0, 0, 0, 255},
+			{16, 16, 16, 255},
+		}
+
+		for _, color := range colors {
+			err := interval.Append(color)
+			assert.Nil(t, err)
+		}
+
+		actualResult := interval.Sort(sortDirection, IntervalReverse)
+
+		assert.Equal(t, expectedResult, actualResult)
+	}
+}
+
+func TestValueWeightIntervalShouldPaintMedian(t *testing.T) {
+	cases := []SortDirection{
+		SortAscending,
+		SortDescending,
+		Shuffle,
+		SortRandom,
+	}
+
+	for _, sortDirection := range cases {
+		interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+		assert.NotNil(t, interval)
+
+		colors := []color.RGBA{
+			{16, 16, 16, 255},
+			{0, 0, 0, 255},
+			{255, 255, 255, 255},
+			{100, 100, 100, 255},
+		}
+
+		expectedResult := []color.RGBA{
+			{58, 58, 58, 255},
+			{58, 58, 58, 255},
+			{58, 58, 58, 255},
+			{58, 58, 58, 255},
+		}
+
+		for _, color := range colors {
+			err := interval.Append(color)
+			assert.Nil(t, err)
+		}
+
+		actualResult := interval.Sort(sortDirection, IntervalMedian)
+
+		assert.Equal(t, expectedResult, actualResult)
+	}
+}
+
+func TestValueWeightIntervalShouldPaintSmear(t *testing.T) {
+	cases := []SortDirection{
+		SortAscending,
+		SortDescending,
+		Shuffle,
+		SortRandom,
+	}
+
+	for _, sortDirection := range cases {
+		interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+		assert.NotNil(t, interval)
+
+		colors := []color.RGBA{
+			{16, 16, 16, 255},
+			{0, 0, 0, 255},
+			{255, 255, 255, 255},
+			{100, 100, 100, 255},
+		}
+
+		expectedResult := []color.RGBA{
+			{16, 16, 16, 255},
+			{10, 10, 10, 255},
+			{175, 175, 175, 255},
+			{100, 100, 100, 255},
+		}
+
+		for _, color := range colors {
+			err := interval.Append(color)
+			assert.Nil(t, err)
+		}
+
+		actualResult := interval.Sort(sortDirection, IntervalSmear)
+
+		assert.Equal(t, expectedResult, actualResult)
+	}
+}
+
+func TestValueWeightIntervalShouldSortAscendingPaintMirror(t *testing.T) {
+	interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+	assert.NotNil(t, interval)
+
+	colors := []color.RGBA{
+		{16, 16, 16, 255},
+		{0, 0, 0, 255},
+		{255, 255, 255, 255},
+		{100, 100, 100, 255},
+	}
+
+	expectedResult := []color.RGBA{
+		{0, 0, 0, 255},
+		{100, 100, 100, 255},
+		{255, 255, 255, 255},
+		{16, 16, 16, 255},
+	}
+
+	for _, color := range colors {
+		err := interval.Append(color)
+		assert.Nil(t, err)
+	}
+
+	actualResult := interval.Sort(SortAscending, IntervalMirror)
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestValueWeightIntervalShouldSortDescendingPaintMirror(t *testing.T) {
+	interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+	assert.NotNil(t, interval)
+
+	colors := []color.RGBA{
+		{16, 16, 16, 255},
+		{0, 0, 0, 255},
+		{255, 255, 255, 255},
+		{100, 100, 100, 255},
+	}
+
+	expectedResult := []color.RGBA{
+		{255, 255, 255, 255},
+		{16, 16, 16, 255},
+		{0, 0, 0, 255},
+		{100, 100, 100, 255},
+	}
+
+	for _, color := range colors {
+		err := interval.Append(color)
+		assert.Nil(t, err)
+	}
+
+	actualResult := interval.Sort(SortDescending, IntervalMirror)
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestValueWeightIntervalShouldSortAscendingPaintGradientStops(t *testing.T) {
+	cases := map[int][]color.RGBA{
+		2: {{0, 0, 0, 255}, {85, 85, 85, 255}, {170, 170, 170, 255}, {255, 255, 255, 255}},
+		3: {{0, 0, 0, 255}, {66, 66, 66, 255}, {151, 151, 151, 255}, {255, 255, 255, 255}},
+		4: {{0, 0, 0, 255}, {16, 16, 16, 255}, {100, 100, 100, 255}, {255, 255, 255, 255}},
+	}
+
+	for stops, expectedResult := range cases {
+		interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+		assert.NotNil(t, interval)
+
+		interval.SetGradient(stops, GradientSrgb)
+
+		colors := []color.RGBA{
+			{16, 16, 16, 255},
+			{0, 0, 0, 255},
+			{255, 255, 255, 255},
+			{100, 100, 100, 255},
+		}
+
+		for _, color := range colors {
+			err := interval.Append(color)
+			assert.Nil(t, err)
+		}
+
+		actualResult := interval.Sort(SortAscending, IntervalGradient)
+
+		assert.Equal(t, expectedResult, actualResult)
+	}
+}
+
+func TestValueWeightIntervalShouldPaintGradientStopsInColorSpaces(t *testing.T) {
+	spaces := []GradientColorSpace{
+		GradientSrgb,
+		GradientLinearRgb,
+		GradientOklab,
+		GradientHsl,
+	}
+
+	for _, space := range spaces {
+		interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+		assert.NotNil(t, interval)
+
+		interval.SetGradient(2, space)
+
+		colors := []color.RGBA{
+			{0, 0, 255, 255},
+			{255, 0, 0, 255},
+			{100, 0, 100, 255},
+		}
+
+		for _, color := range colors {
+			err := interval.Append(color)
+			assert.Nil(t, err)
+		}
+
+		actualResult := interval.Sort(SortAscending, IntervalGradient)
+
+		assert.Len(t, actualResult, 3)
+		assert.Equal(t, color.RGBA{0, 0, 255, 255}, actualResult[0])
+		assert.Equal(t, color.RGBA{255, 0, 0, 255}, actualResult[2])
+	}
+}
+
+func TestValueWeightIntervalShouldPanicForInvalidGradientColorSpace(t *testing.T) {
+	interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+	assert.NotNil(t, interval)
+
+	assert.Panics(t, func() {
+		interval.SetGradient(2, -1)
+	})
+}
+
+func TestValueWeightIntervalShouldSortAscendingPaintFillWithSortStrength(t *testing.T) {
+	cases := map[float64][]uint8{
+		0.0:  {50, 0, 10, 20, 30, 40},
+		0.25: {0, 50, 10, 20, 30, 40},
+		0.5:  {0, 10, 20, 50, 30, 40},
+		1.0:  {0, 10, 20, 30, 40, 50},
+	}
+
+	for strength, expected := range cases {
+		interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+		assert.NotNil(t, interval)
+
+		interval.SetSortStrength(strength)
+
+		for _, color := range mockGrayscaleColors(50, 0, 10, 20, 30, 40) {
+			err := interval.Append(color)
+			assert.Nil(t, err)
+		}
+
+		actualResult := interval.Sort(SortAscending, IntervalFill)
+
+		assert.Equal(t, mockGrayscaleColors(expected...), actualResult)
+	}
+}
+
+func TestValueWeightIntervalShouldSortDescendingPaintFillWithSortStrength(t *testing.T) {
+	interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+	assert.NotNil(t, interval)
+
+	interval.SetSortStrength(0.5)
+
+	for _, color := range mockGrayscaleColors(0, 50, 40, 30, 20, 10) {
+		err := interval.Append(color)
+		assert.Nil(t, err)
+	}
+
+	actualResult := interval.Sort(SortDescending, IntervalFill)
+
+	assert.Equal(t, mockGrayscaleColors(50, 40, 30, 0, 20, 10), actualResult)
+}
+
+func TestValueWeightIntervalShouldKeepEqualColorsWithSortStrength(t *testing.T) {
+	interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+	assert.NotNil(t, interval)
+
+	interval.SetSortStrength(0.5)
+
+	for _, color := range mockGrayscaleColors(10, 10, 0) {
+		err := interval.Append(color)
+		assert.Nil(t, err)
+	}
+
+	actualResult := interval.Sort(SortAscending, IntervalFill)
+
+	assert.Equal(t, mockGrayscaleColors(10, 0, 10), actualResult)
+}
+
+func TestValueWeightIntervalShouldShufflePaintFillWithSortStrength(t *testing.T) {
+	interval := CreateValueWeightInterval(mockTestValueWeightDeterminant())
+	assert.NotNil(t, interval)
+
+	interval.SetSortStrength(0.3)
+
+	colors := mockGrayscaleColors(50, 0, 10, 20, 30, 40)
+	for _, color := range colors {
+		err := interval.Append(color)
+		assert.Nil(t, err)
+	}
+
+	actualResult := interval.Sort(Shuffle, IntervalFill)
+
+	assert.ElementsMatch(t, colors, actualResult)
+}
+
 func TestNormalizedWeightIntervalShouldCreate(t *testing.T) {
 	interval := CreateNormalizedWeightInterval(mockTestNormalizedWeightDeterminant())
 	assert.NotNil(t, interval)
@@ -522,6 +910,7 @@ func TestNormalizedWeightIntervalShouldPanicForInvalidDirectionAndPaintingValues
 	}{
 		{-100, IntervalFill},
 		{-100, IntervalGradient},
+		{-100, IntervalMirror},
 		{-100, -100},
 	}
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"image"
 
+	"github.com/Krzysztofz01/imaging"
 	"github.com/Krzysztofz01/pimit"
 	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
 )
@@ -21,6 +22,9 @@ type Mask interface {
 
 	// Return a boolean value representing if the mask is masking at the given position represented by the i index (value < 127 is masked).
 	AtByIndexB(i int) (bool, error)
+
+	// Return a boolean value representing if the mask is masking at the given position represented by the i index (value < threshold is masked).
+	AtByIndexT(i int, threshold uint8) (bool, error)
 }
 
 type mask struct {
@@ -116,14 +120,28 @@ func (m *mask) AtByIndex(i int) (uint8, error) {
 }
 
 func (m *mask) AtByIndexB(i int) (bool, error) {
+	return m.AtByIndexT(i, 127)
+}
+
+func (m *mask) AtByIndexT(i int, threshold uint8) (bool, error) {
 	at, err := m.AtByIndex(i)
 	if err != nil {
 		return false, err
 	}
 
-	if at < 127 {
+	if at < threshold {
 		return true, nil
 	} else {
 		return false, nil
 	}
 }
+
+// Create a feathered copy of the mask image by performing the gaussian blur with the given radius. The radius is the standard deviation
+// of the gaussian function in pixels. The mask image itself is returned if the radius is zero.
+func featherMaskImage(maskImage *image.NRGBA, radius float64) *image.NRGBA {
+	if maskImage == nil || radius == 0.0 {
+		return maskImage
+	}
+
+	return imaging.Blur(maskImage, radius)
+}
@@ -125,6 +125,50 @@ func TestMaskShouldReturnErrorOnInvalidAt(t *testing.T) {
 
 	_, err = mask.AtByIndexB(testMaskImageWidth * testMaskImageHeight)
 	assert.NotNil(t, err)
+
+	_, err = mask.AtByIndexT(-1, 127)
+	assert.NotNil(t, err)
+}
+
+func TestMaskShouldUseThresholdForIndexLookup(t *testing.T) {
+	gray := color.NRGBA{0x40, 0x40, 0x40, 0xff}
+	mask, err := CreateMaskFromNrgba(mockMaskTestImageNrgba(whiteNrgba, gray))
+
+	assert.Nil(t, err)
+	assert.NotNil(t, mask)
+
+	atb, err := mask.AtByIndexB(0)
+	assert.Nil(t, err)
+	assert.True(t, atb)
+
+	atb, err = mask.AtByIndexT(0, 0x40)
+	assert.Nil(t, err)
+	assert.False(t, atb)
+
+	atb, err = mask.AtByIndexT(0, 0x41)
+	assert.Nil(t, err)
+	assert.True(t, atb)
+
+	atb, err = mask.AtByIndexT(1, 0xff)
+	assert.Nil(t, err)
+	assert.False(t, atb)
+}
+
+func TestFeatherMaskImageShouldBlurMaskImage(t *testing.T) {
+	maskImage := mockMaskTestImageNrgba(whiteNrgba, blackNrgba)
+
+	assert.Same(t, maskImage, featherMaskImage(maskImage, 0))
+	assert.Nil(t, featherMaskImage(nil, 2))
+
+	feathered := featherMaskImage(maskImage, 2)
+
+	assert.Equal(t, maskImage.Bounds(), feathered.Bounds())
+
+	c := feathered.NRGBAAt(4, 4)
+	assert.Greater(t, c.R, uint8(0x00))
+	assert.Less(t, c.R, uint8(0xff))
+	assert.Equal(t, c.R, c.G)
+	assert.Equal(t, c.R, c.B)
 }
 
 const (
@@ -0,0 +1,168 @@
+package sorter
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Structure representing the parameters of the quantization performed by the quantize interval painting. The global palette is
+// used if it is provided, otherwise the palette is created separately for each interval from the given count of levels.
+type intervalQuantizer struct {
+	palette       []color.RGBA
+	paletteSpread float64
+	levels        int
+	ditherMatrix  []float64
+	ditherSize    int
+}
+
+// Create the interval quantizer according to the quantization related options
+func createIntervalQuantizer(options *SorterOptions) *intervalQuantizer {
+	quantizer := &intervalQuantizer{
+		palette:       nil,
+		paletteSpread: 0,
+		levels:        options.QuantizeLevels,
+		ditherMatrix:  nil,
+		ditherSize:    0,
+	}
+
+	if len(options.QuantizePalette) > 0 {
+		quantizer.palette = make([]color.RGBA, 0, len(options.QuantizePalette))
+		for _, c := range options.QuantizePalette {
+			quantizer.palette = append(quantizer.palette, color.RGBAModel.Convert(c).(color.RGBA))
+		}
+
+		quantizer.paletteSpread = calculatePaletteSpread(quantizer.palette)
+	}
+
+	switch options.QuantizeDithering {
+	case DitheringNone:
+		break
+	case DitheringBayer4x4:
+		quantizer.ditherMatrix, quantizer.ditherSize = createBayerMatrix(4), 4
+	case DitheringBayer8x8:
+		quantizer.ditherMatrix, quantizer.ditherSize = createBayerMatrix(8), 8
+	default:
+		panic("sorter: undefined quantize dithering specified")
+	}
+
+	return quantizer
+}
+
+// Quantize the colors of the sorted interval buffer in place. The target position of the buffer in the destination image is determined
+// by the iteration index and step value in the same way as while drawing the buffer and is used to pick the ordered dithering threshold.
+func (quantizer *intervalQuantizer) quantizeBuffer(dst *image.RGBA, buffer []color.RGBA, index, step int) {
+	palette, spread := quantizer.palette, quantizer.paletteSpread
+	if palette == nil {
+		palette = createIntervalPalette(buffer, quantizer.levels)
+		spread = calculatePaletteSpread(palette)
+	}
+
+	for dstIndex, bufferIndex := index, len(buffer)-1; bufferIndex >= 0; dstIndex, bufferIndex = dstIndex-step, bufferIndex-1 {
+		offset := 0.0
+		if quantizer.ditherMatrix != nil {
+			x := (dstIndex % dst.Stride) / 4
+			y := dstIndex / dst.Stride
+
+			offset = quantizer.ditherMatrix[(y%quantizer.ditherSize)*quantizer.ditherSize+x%quantizer.ditherSize] * spread
+		}
+
+		c := buffer[bufferIndex]
+		nearest := findNearestPaletteColor(palette, float64(c.R)+offset, float64(c.G)+offset, float64(c.B)+offset)
+
+		buffer[bufferIndex] = color.RGBA{nearest.R, nearest.G, nearest.B, c.A}
+	}
+}
+
+// Create the palette of the given count of levels from the sorted interval colors. The interval is split into evenly sized bands and
+// the mean color of each band is a palette color.
+func createIntervalPalette(buffer []color.RGBA, levels int) []color.RGBA {
+	if levels > len(buffer) {
+		levels = len(buffer)
+	}
+
+	palette := make([]color.RGBA, 0, levels)
+	for level := 0; level < levels; level += 1 {
+		start, end := level*len(buffer)/levels, (level+1)*len(buffer)/levels
+
+		sumR, sumG, sumB := 0, 0, 0
+		for _, c := range buffer[start:end] {
+			sumR += int(c.R)
+			sumG += int(c.G)
+			sumB += int(c.B)
+		}
+
+		count := end - start
+		palette = append(palette, color.RGBA{uint8(sumR / count), uint8(sumG / count), uint8(sumB / count), 0xff})
+	}
+
+	return palette
+}
+
+// Calculate the mean distance between the palette colors and their nearest neighbours, which is used as the amplitude of the dithering
+func calculatePaletteSpread(palette []color.RGBA) float64 {
+	if len(palette) < 2 {
+		return 0
+	}
+
+	sum := 0.0
+	for i, a := range palette {
+		nearest := math.Inf(1)
+		for j, b := range palette {
+			if i == j {
+				continue
+			}
+
+			dR, dG, dB := float64(a.R)-float64(b.R), float64(a.G)-float64(b.G), float64(a.B)-float64(b.B)
+			nearest = math.Min(nearest, math.Sqrt(dR*dR+dG*dG+dB*dB))
+		}
+
+		sum += nearest
+	}
+
+	return sum / float64(len(palette))
+}
+
+// Function used to find the palette color nearest to the given RGB components using the squared euclidean distance
+func findNearestPaletteColor(palette []color.RGBA, r, g, b float64) color.RGBA {
+	var (
+		nearest     color.RGBA = palette[0]
+		minDistance float64    = math.Inf(1)
+	)
+
+	for _, c := range palette {
+		dR, dG, dB := r-float64(c.R), g-float64(c.G), b-float64(c.B)
+		if distance := dR*dR + dG*dG + dB*dB; distance < minDistance {
+			nearest, minDistance = c, distance
+		}
+	}
+
+	return nearest
+}
+
+// Create the Bayer ordered dithering matrix of the given size (power of two) with the thresholds normalized to range from -0.5 to 0.5
+func createBayerMatrix(size int) []float64 {
+	indices := []int{0}
+	for n := 1; n < size; n *= 2 {
+		next := make([]int, 4*n*n)
+		for y := 0; y < n; y += 1 {
+			for x := 0; x < n; x += 1 {
+				value := 4 * indices[y*n+x]
+
+				next[y*2*n+x] = value
+				next[y*2*n+x+n] = value + 2
+				next[(y+n)*2*n+x] = value + 3
+				next[(y+n)*2*n+x+n] = value + 1
+			}
+		}
+
+		indices = next
+	}
+
+	matrix := make([]float64, len(indices))
+	for index, value := range indices {
+		matrix[index] = (float64(value)+0.5)/float64(len(indices)) - 0.5
+	}
+
+	return matrix
+}
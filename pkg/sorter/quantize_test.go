@@ -0,0 +1,112 @@
+package sorter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateBayerMatrixShouldCreateOrderedThresholds(t *testing.T) {
+	expectedIndices := []int{
+		0, 8, 2, 10,
+		12, 4, 14, 6,
+		3, 11, 1, 9,
+		15, 7, 13, 5,
+	}
+
+	matrix := createBayerMatrix(4)
+
+	assert.Len(t, matrix, 16)
+	for index, expected := range expectedIndices {
+		assert.InDelta(t, (float64(expected)+0.5)/16.0-0.5, matrix[index], 1e-9)
+	}
+
+	assert.Len(t, createBayerMatrix(8), 64)
+}
+
+func TestIntervalQuantizerShouldQuantizeToIntervalLevels(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.QuantizeLevels = 2
+
+	quantizer := createIntervalQuantizer(options)
+	dst := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	buffer := mockGrayscaleColors(10, 20, 200, 210)
+
+	quantizer.quantizeBuffer(dst, buffer, 12, 4)
+
+	assert.Equal(t, mockGrayscaleColors(15, 15, 205, 205), buffer)
+}
+
+func TestIntervalQuantizerShouldQuantizeToGlobalPalette(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.QuantizePalette = color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}}
+
+	quantizer := createIntervalQuantizer(options)
+	dst := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	buffer := mockGrayscaleColors(10, 120, 140, 250)
+
+	quantizer.quantizeBuffer(dst, buffer, 12, 4)
+
+	assert.Equal(t, mockGrayscaleColors(0, 0, 255, 255), buffer)
+}
+
+func TestIntervalQuantizerShouldDitherToGlobalPalette(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.QuantizePalette = color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}}
+	options.QuantizeDithering = DitheringBayer4x4
+
+	quantizer := createIntervalQuantizer(options)
+	dst := image.NewRGBA(image.Rect(0, 0, 16, 1))
+	buffer := make([]color.RGBA, 16)
+	for index := range buffer {
+		buffer[index] = color.RGBA{128, 128, 128, 255}
+	}
+
+	quantizer.quantizeBuffer(dst, buffer, 60, 4)
+
+	dark, bright := 0, 0
+	for _, c := range buffer {
+		switch c {
+		case color.RGBA{0, 0, 0, 255}:
+			dark += 1
+		case color.RGBA{255, 255, 255, 255}:
+			bright += 1
+		}
+	}
+
+	assert.Equal(t, 8, dark)
+	assert.Equal(t, 8, bright)
+}
+
+func TestIntervalQuantizerShouldPreserveAlpha(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.QuantizePalette = color.Palette{color.RGBA{255, 0, 0, 255}}
+
+	quantizer := createIntervalQuantizer(options)
+	dst := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	buffer := []color.RGBA{{10, 10, 10, 100}}
+
+	quantizer.quantizeBuffer(dst, buffer, 0, 4)
+
+	assert.Equal(t, []color.RGBA{{255, 0, 0, 100}}, buffer)
+}
+
+func TestCreateIntervalQuantizerShouldPanicForInvalidDithering(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.QuantizeDithering = -1
+
+	assert.Panics(t, func() {
+		createIntervalQuantizer(options)
+	})
+}
+
+func mockGrayscaleColors(values ...uint8) []color.RGBA {
+	colors := make([]color.RGBA, 0, len(values))
+	for _, v := range values {
+		colors = append(colors, color.RGBA{v, v, v, 255})
+	}
+
+	return colors
+}
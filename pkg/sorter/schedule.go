@@ -0,0 +1,117 @@
+package sorter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Structure representing the schedule of a numeric sorter parameter across the sorting cycles. The explicit values are used by the
+// subsequent cycles (repeated from the start if there are less values than cycles), otherwise the step is added to the base value
+// of the parameter in each subsequent cycle.
+type ParameterSchedule struct {
+	Values []float64
+	Step   float64
+}
+
+// Return a boolean value indicating if the schedule is not changing the parameter value
+func (schedule ParameterSchedule) IsEmpty() bool {
+	return len(schedule.Values) == 0 && schedule.Step == 0
+}
+
+// Get the value of the scheduled parameter in the given cycle
+func (schedule ParameterSchedule) valueAt(base float64, cycle int) float64 {
+	if len(schedule.Values) > 0 {
+		return schedule.Values[cycle%len(schedule.Values)]
+	}
+
+	return base + float64(cycle)*schedule.Step
+}
+
+// Parse the parameter schedule from its text representation. The schedule can be given as a step expression, which is a signed
+// number (e.g. "+15" or "-0.05") added to the parameter in each cycle, or as a comma-separated list of explicit values (e.g. "0,45,90").
+func ParseParameterSchedule(text string) (ParameterSchedule, error) {
+	text = strings.TrimSpace(text)
+	if len(text) == 0 {
+		return ParameterSchedule{}, nil
+	}
+
+	if strings.HasPrefix(text, "+") || strings.HasPrefix(text, "-") {
+		step, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsNaN(step) || math.IsInf(step, 0) {
+			return ParameterSchedule{}, fmt.Errorf("sorter: invalid parameter schedule step specified (%s)", text)
+		}
+
+		return ParameterSchedule{Values: nil, Step: step}, nil
+	}
+
+	values := make([]float64, 0)
+	for _, field := range strings.Split(text, ",") {
+		value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return ParameterSchedule{}, fmt.Errorf("sorter: invalid parameter schedule value specified (%s)", field)
+		}
+
+		values = append(values, value)
+	}
+
+	return ParameterSchedule{Values: values, Step: 0}, nil
+}
+
+// Structure representing the changes of the sorter parameters applied in the subsequent sorting cycles. The sort order and sort direction
+// lists are used by the subsequent cycles and repeated from the start if there are less values than cycles.
+type CycleSchedule struct {
+	Angle          ParameterSchedule
+	LowerThreshold ParameterSchedule
+	UpperThreshold ParameterSchedule
+	IntervalLength ParameterSchedule
+	SortOrder      []SortOrder
+	SortDirection  []SortDirection
+}
+
+// Return a boolean value indicating if the schedule is not changing any parameters between the cycles
+func (schedule *CycleSchedule) IsEmpty() bool {
+	return schedule.Angle.IsEmpty() &&
+		schedule.LowerThreshold.IsEmpty() &&
+		schedule.UpperThreshold.IsEmpty() &&
+		schedule.IntervalLength.IsEmpty() &&
+		len(schedule.SortOrder) == 0 &&
+		len(schedule.SortDirection) == 0
+}
+
+// Create a copy of the sorter options used to perform the single given cycle according to the cycle schedule. The returned options are
+// describing a single cycle without a schedule.
+func (options *SorterOptions) createCycleOptions(cycle int) *SorterOptions {
+	schedule := &options.CycleSchedule
+
+	cycleOptions := *options
+	cycleOptions.Cycles = 1
+	cycleOptions.CycleSchedule = CycleSchedule{}
+
+	cycleOptions.Angle = int(math.Round(schedule.Angle.valueAt(float64(options.Angle), cycle)))
+	cycleOptions.IntervalDeterminantLowerThreshold = schedule.LowerThreshold.valueAt(options.IntervalDeterminantLowerThreshold, cycle)
+	cycleOptions.IntervalDeterminantUpperThreshold = schedule.UpperThreshold.valueAt(options.IntervalDeterminantUpperThreshold, cycle)
+	cycleOptions.IntervalLength = int(math.Round(schedule.IntervalLength.valueAt(float64(options.IntervalLength), cycle)))
+
+	if len(schedule.SortOrder) > 0 {
+		cycleOptions.SortOrder = schedule.SortOrder[cycle%len(schedule.SortOrder)]
+	}
+
+	if len(schedule.SortDirection) > 0 {
+		cycleOptions.SortDirection = schedule.SortDirection[cycle%len(schedule.SortDirection)]
+	}
+
+	return &cycleOptions
+}
+
+// Return a boolean value indicating if the options of all scheduled cycles are valid and a string containing the validation failure message
+func (options *SorterOptions) areCycleOptionsValid() (bool, string) {
+	for cycle := 0; cycle < options.Cycles; cycle += 1 {
+		if valid, msg := options.createCycleOptions(cycle).AreValid(); !valid {
+			return false, fmt.Sprintf("invalid scheduled options of the cycle %d: %s", cycle+1, msg)
+		}
+	}
+
+	return true, ""
+}
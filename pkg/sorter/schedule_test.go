@@ -0,0 +1,139 @@
+package sorter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseParameterScheduleShouldParseSchedule(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected ParameterSchedule
+	}{
+		{"", ParameterSchedule{}},
+		{"+15", ParameterSchedule{Values: nil, Step: 15}},
+		{"-0.05", ParameterSchedule{Values: nil, Step: -0.05}},
+		{"0, 45,90", ParameterSchedule{Values: []float64{0, 45, 90}, Step: 0}},
+		{"0.5", ParameterSchedule{Values: []float64{0.5}, Step: 0}},
+	}
+
+	for _, c := range cases {
+		actual, err := ParseParameterSchedule(c.text)
+
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, actual)
+	}
+}
+
+func TestParseParameterScheduleShouldNotParseInvalidSchedule(t *testing.T) {
+	cases := []string{
+		"+",
+		"+x",
+		"1,,2",
+		"1,a",
+		"+Inf",
+		"NaN",
+	}
+
+	for _, text := range cases {
+		_, err := ParseParameterSchedule(text)
+
+		assert.NotNil(t, err)
+	}
+}
+
+func TestParameterScheduleShouldCalculateCycleValues(t *testing.T) {
+	step := ParameterSchedule{Step: 15}
+	assert.Equal(t, 10.0, step.valueAt(10, 0))
+	assert.Equal(t, 40.0, step.valueAt(10, 2))
+
+	values := ParameterSchedule{Values: []float64{1, 2}}
+	assert.Equal(t, 1.0, values.valueAt(10, 0))
+	assert.Equal(t, 2.0, values.valueAt(10, 1))
+	assert.Equal(t, 1.0, values.valueAt(10, 2))
+
+	assert.True(t, ParameterSchedule{}.IsEmpty())
+	assert.False(t, step.IsEmpty())
+	assert.False(t, values.IsEmpty())
+}
+
+func TestCreateCycleOptionsShouldApplySchedule(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.Cycles = 3
+	options.Angle = 10
+	options.IntervalDeterminantUpperThreshold = 0.9
+	options.CycleSchedule = CycleSchedule{
+		Angle:          ParameterSchedule{Step: 15},
+		UpperThreshold: ParameterSchedule{Step: -0.1},
+		IntervalLength: ParameterSchedule{Values: []float64{20, 40}},
+		SortOrder:      []SortOrder{SortHorizontal, SortVertical},
+	}
+
+	cycleOptions := options.createCycleOptions(2)
+
+	assert.Equal(t, 1, cycleOptions.Cycles)
+	assert.True(t, cycleOptions.CycleSchedule.IsEmpty())
+	assert.Equal(t, 40, cycleOptions.Angle)
+	assert.InDelta(t, 0.7, cycleOptions.IntervalDeterminantUpperThreshold, 1e-9)
+	assert.Equal(t, 20, cycleOptions.IntervalLength)
+	assert.Equal(t, SortHorizontal, cycleOptions.SortOrder)
+	assert.Equal(t, SortAscending, cycleOptions.SortDirection)
+
+	assert.Equal(t, 3, options.Cycles)
+	assert.Equal(t, 10, options.Angle)
+}
+
+func TestSorterOptionsShouldNotValidateScheduleLeavingValidRange(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.Cycles = 4
+	options.IntervalDeterminantUpperThreshold = 0.5
+	options.CycleSchedule.UpperThreshold = ParameterSchedule{Step: 0.2}
+
+	valid, msg := options.AreValid()
+
+	assert.False(t, valid)
+	assert.Contains(t, msg, "cycle 4")
+
+	options.Cycles = 3
+
+	valid, _ = options.AreValid()
+	assert.True(t, valid)
+}
+
+func TestScheduleWithConstantAngleShouldSortTheSameAsNonScheduledCycles(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 24))
+	for y := 0; y < img.Bounds().Dy(); y += 1 {
+		for x := 0; x < img.Bounds().Dx(); x += 1 {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x*37 + y*11), uint8(x*5 + y*53), uint8(x * y), 0xff})
+		}
+	}
+
+	sort := func(options *SorterOptions) []uint8 {
+		sorter, err := CreateSorter(img, nil, nil, options)
+		assert.Nil(t, err)
+
+		result, err := sorter.Sort()
+		assert.Nil(t, err)
+
+		return utils.ImageToNrgbaImage(result).Pix
+	}
+
+	options := GetDefaultSorterOptions()
+	options.Cycles = 3
+	options.Angle = 30
+	options.IntervalDeterminantLowerThreshold = 0.2
+
+	expected := sort(options)
+
+	options.CycleSchedule = CycleSchedule{
+		Angle:          ParameterSchedule{Values: []float64{30}},
+		LowerThreshold: ParameterSchedule{Values: []float64{0.2}},
+		UpperThreshold: ParameterSchedule{Values: []float64{1.0}},
+	}
+
+	assert.Equal(t, expected, sort(options))
+}
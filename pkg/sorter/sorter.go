@@ -2,7 +2,12 @@ package sorter
 
 import (
 	"errors"
+	"fmt"
 	"image"
+	"image/color"
+	"math"
+
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
 )
 
 // Flag representing the determinant parameter for the sorting process
@@ -16,6 +21,8 @@ const (
 	SortByRedChannel
 	SortByGreenChannel
 	SortByBlueChannel
+	SortByKeyImage
+	SortByNoise
 )
 
 // Flag representing the order in which should be the image sorted
@@ -48,6 +55,60 @@ const (
 	SplitByMask
 	SplitByAbsoluteColor
 	SplitByEdgeDetection
+	SplitByMap
+	SplitByRgbChannels
+	SplitByLocalDifference
+	SplitByNoise
+	SplitByExpression
+	SplitByClusters
+)
+
+// Flag representing the source of the max interval length
+type IntervalLengthSource int
+
+const (
+	IntervalLengthFixed IntervalLengthSource = iota
+	IntervalLengthNoise
+	IntervalLengthMap
+)
+
+// Flag representing the statistical distribution of the max interval lengths
+type IntervalLengthDistribution int
+
+const (
+	DistributionUniform IntervalLengthDistribution = iota
+	DistributionNormal
+	DistributionExponential
+	DistributionLogNormal
+	DistributionPowerLaw
+)
+
+// Flag representing the metric used to measure the difference between adjacent pixels by the SplitByLocalDifference interval determinant
+type LocalDifferenceMetric int
+
+const (
+	DifferenceBrightness LocalDifferenceMetric = iota
+	DifferenceOklab
+)
+
+// Structure representing the separate lower and upper interval determinant thresholds of the RGB channels used by the SplitByRgbChannels
+// interval determinant. The thresholds are normalized channel values (0.0 - 1.0).
+type ChannelThresholds struct {
+	RedLower   float64
+	RedUpper   float64
+	GreenLower float64
+	GreenUpper float64
+	BlueLower  float64
+	BlueUpper  float64
+}
+
+// Flag representing the method used to select the interval determinant thresholds
+type IntervalThresholdSelection int
+
+const (
+	ThresholdManual IntervalThresholdSelection = iota
+	ThresholdOtsu
+	ThresholdPercentile
 )
 
 type ResultImageBlending int
@@ -56,6 +117,19 @@ const (
 	BlendingNone ResultImageBlending = iota
 	BlendingLighten
 	BlendingDarken
+	BlendingMultiply
+	BlendingScreen
+	BlendingOverlay
+	BlendingSoftLight
+	BlendingHardLight
+	BlendingDifference
+	BlendingExclusion
+	BlendingColorDodge
+	BlendingColorBurn
+	BlendingHue
+	BlendingSaturation
+	BlendingColor
+	BlendingLuminosity
 )
 
 // Flag representing the behaviour of interval painting process
@@ -66,24 +140,92 @@ const (
 	IntervalGradient
 	IntervalRepeat
 	IntervalAverage
+	IntervalReverse
+	IntervalMirror
+	IntervalMedian
+	IntervalSmear
+	IntervalQuantize
+)
+
+// Flag representing the ordered dithering used by the quantize interval painting
+type QuantizeDithering int
+
+const (
+	DitheringNone QuantizeDithering = iota
+	DitheringBayer4x4
+	DitheringBayer8x8
+)
+
+// Flag representing the color space in which the colors of the gradient interval painting are interpolated
+type GradientColorSpace int
+
+const (
+	GradientSrgb GradientColorSpace = iota
+	GradientLinearRgb
+	GradientOklab
+	GradientHsl
 )
 
 // Structure representing all the parameters for the sorter
 type SorterOptions struct {
 	SortDeterminant                   SortDeterminant
 	SortDirection                     SortDirection
+	DirectionScheme                   DirectionScheme
+	DirectionBrightnessThreshold      float64
 	SortOrder                         SortOrder
 	IntervalDeterminant               IntervalDeterminant
 	IntervalPainting                  IntervalPainting
+	SortStrength                      float64
+	ChannelSplit                      ChannelSplitMode
+	ChannelSplitDeterminant           ChannelSplitDeterminant
+	ChannelOptions                    [4]ChannelOptions
+	GradientStops                     int
+	GradientColorSpace                GradientColorSpace
+	QuantizeLevels                    int
+	QuantizePalette                   color.Palette
+	QuantizeDithering                 QuantizeDithering
 	IntervalDeterminantLowerThreshold float64
 	IntervalDeterminantUpperThreshold float64
+	IntervalDeterminantWrapAround     bool
+	IntervalExpression                *IntervalExpression
+	ClusterCount                      int
+	ClusterSeed                       int64
+	ClusterIndices                    []int
+	IntervalHysteresis                bool
+	IntervalStayLowerThreshold        float64
+	IntervalStayUpperThreshold        float64
+	IntervalGapTolerance              int
+	IntervalMinLength                 int
+	IntervalChannelThresholds         ChannelThresholds
+	IntervalDifferenceMetric          LocalDifferenceMetric
+	IntervalDifferenceThreshold       float64
+	IntervalThresholdSelection        IntervalThresholdSelection
+	IntervalLowerPercentile           float64
+	IntervalUpperPercentile           float64
 	IntervalLength                    int
 	IntervalLengthRandomFactor        int
+	IntervalLengthSource              IntervalLengthSource
+	IntervalLengthDistribution        IntervalLengthDistribution
+	IntervalLengthDeviation           float64
+	IntervalLengthExponent            float64
+	Seed                              *int64
+	NoiseSeed                         int64
+	NoiseScale                        float64
+	NoiseOctaves                      int
+	NoisePersistence                  float64
 	Angle                             int
 	UseMask                           bool
+	MaskThreshold                     uint8
+	MaskFeatherRadius                 float64
+	SoftMask                          bool
 	Cycles                            int
+	CycleSchedule                     CycleSchedule
+	CompositeAngles                   []int
+	AngleComposite                    AngleCompositeMode
+	CompositeMemoryBudget             int
 	Scale                             float64
 	Blending                          ResultImageBlending
+	BlendingOpacity                   float64
 }
 
 // Return a boolean value indicating if the given sorter options combination is valid
@@ -98,13 +240,110 @@ func (options *SorterOptions) AreValid() (bool, string) {
 	}
 
 	if options.IntervalDeterminantLowerThreshold > options.IntervalDeterminantUpperThreshold {
-		return false, "lower interval determinant threshold must no be greater than the upper one"
+		if !options.IntervalDeterminantWrapAround || options.IntervalDeterminant != SplitByHue {
+			return false, "lower interval determinant threshold must no be greater than the upper one unless the hue wrap-around is used"
+		}
+	}
+
+	if valid, msg := options.IntervalChannelThresholds.areValid(); !valid {
+		return false, msg
+	}
+
+	if options.IntervalDeterminant == SplitByExpression {
+		if valid, msg := options.IntervalExpression.AreValid(); !valid {
+			return false, msg
+		}
+
+		if !options.IntervalDeterminantWrapAround && options.IntervalExpression.usesHueWrapAround() {
+			return false, "the interval expression hue clause lower threshold must no be greater than the upper one unless the hue wrap-around is used"
+		}
+	}
+
+	if options.IntervalHysteresis {
+		if valid, msg := options.areStayThresholdsValid(); !valid {
+			return false, msg
+		}
+	}
+
+	if options.IntervalThresholdSelection != ThresholdManual {
+		switch options.IntervalDeterminant {
+		case SplitByMask, SplitByEdgeDetection, SplitByRgbChannels, SplitByLocalDifference, SplitByExpression, SplitByClusters:
+			return false, "the automatic threshold selection is not supported by the mask, edge, rgb channels, local difference, expression and clusters interval determinants"
+		}
+	}
+
+	if options.IntervalDeterminant == SplitByClusters {
+		if options.ClusterCount < 2 || options.ClusterCount > clusterMaxCount {
+			return false, fmt.Sprintf("the clusters count must be between values 2 and %d", clusterMaxCount)
+		}
+
+		for _, clusterIndex := range options.ClusterIndices {
+			if clusterIndex < 0 || clusterIndex >= options.ClusterCount {
+				return false, "the selected cluster indices must be between 0 and the clusters count (exclusive)"
+			}
+		}
+	}
+
+	if options.IntervalDifferenceThreshold < 0.0 || options.IntervalDifferenceThreshold > 1.0 {
+		return false, "the local difference interval threshold must be between values 0 and 1"
+	}
+
+	if options.IntervalLowerPercentile < 0.0 || options.IntervalLowerPercentile > 100.0 {
+		return false, "lower interval determinant percentile must be between values 0 and 100"
+	}
+
+	if options.IntervalUpperPercentile < 0.0 || options.IntervalUpperPercentile > 100.0 {
+		return false, "upper interval determinant percentile must be between values 0 and 100"
+	}
+
+	if options.IntervalLowerPercentile > options.IntervalUpperPercentile {
+		return false, "lower interval determinant percentile must no be greater than the upper one"
+	}
+
+	if options.DirectionScheme != DirectionUniform && options.SortDirection != SortAscending && options.SortDirection != SortDescending {
+		return false, "the direction schemes can only be used with the ascending or descending sort direction"
+	}
+
+	if options.DirectionBrightnessThreshold < 0.0 || options.DirectionBrightnessThreshold > 1.0 {
+		return false, "the direction brightness threshold must be between values 0 and 1"
+	}
+
+	if options.SortStrength < 0.0 || options.SortStrength > 1.0 {
+		return false, "the sort strength must be in range from 0.0 to 1.0"
+	}
+
+	for channel := 0; channel < options.ChannelSplit.channelCount(); channel += 1 {
+		if valid, msg := options.createChannelPlaneOptions(channel).AreValid(); !valid {
+			return false, fmt.Sprintf("invalid color channel plane options: %s", msg)
+		}
 	}
 
 	if options.Cycles < 1 {
 		return false, "the cycles count must be 1 or greater"
 	}
 
+	if !options.CycleSchedule.IsEmpty() {
+		if valid, msg := options.areCycleOptionsValid(); !valid {
+			return false, msg
+		}
+	}
+
+	if len(options.CompositeAngles) > 0 && options.ChannelSplit != ChannelSplitNone {
+		return false, "the multi-angle composite can not be used together with the color channel split"
+	}
+
+	if options.CompositeMemoryBudget < 0 {
+		return false, "the composite memory budget must not be negative"
+	}
+
+	if options.MaskFeatherRadius < 0.0 {
+		return false, "the mask feather radius must not be negative"
+	}
+
+	if options.BlendingOpacity < 0.0 || options.BlendingOpacity > 1.0 {
+		return false, "the blending opacity must be between values 0 and 1"
+	}
+
 	if options.Scale <= 0.0 || options.Scale > 1.0 {
 		return false, "the scale factor must be between values 0 (exclusive) and 1"
 	}
@@ -117,6 +356,133 @@ func (options *SorterOptions) AreValid() (bool, string) {
 		return false, "the interval length random factor value must not be negative"
 	}
 
+	if options.IntervalLengthDeviation < 0.0 {
+		return false, "the interval length deviation value must not be negative"
+	}
+
+	if options.IntervalLengthDistribution == DistributionPowerLaw && options.IntervalLengthExponent <= 1.0 {
+		return false, "the interval length power-law exponent must be greater than 1"
+	}
+
+	if options.GradientStops != 0 && (options.GradientStops < 2 || options.GradientStops > 256) {
+		return false, "the gradient stops count must be zero or in range from 2 to 256"
+	}
+
+	if options.IntervalPainting == IntervalQuantize && (options.QuantizeLevels < 2 || options.QuantizeLevels > 256) {
+		return false, "the quantize levels count must be in range from 2 to 256"
+	}
+
+	if len(options.QuantizePalette) > 256 {
+		return false, "the quantize palette can not contain more than 256 colors"
+	}
+
+	if options.IntervalGapTolerance < 0 {
+		return false, "the interval gap tolerance value must not be negative"
+	}
+
+	if options.IntervalMinLength < 0 {
+		return false, "the interval min length value must not be negative"
+	}
+
+	if options.IntervalLength != 0 && options.IntervalLengthSource == IntervalLengthFixed && options.IntervalLengthDistribution == DistributionUniform && options.IntervalMinLength > options.IntervalLength+options.IntervalLengthRandomFactor {
+		return false, "the interval min length must not be greater than the interval max length"
+	}
+
+	if options.usesNoise() {
+		if options.NoiseScale <= 0.0 {
+			return false, "the noise scale must be greater than 0"
+		}
+
+		if options.NoiseOctaves < 1 || options.NoiseOctaves > 16 {
+			return false, "the noise octaves count must be between values 1 and 16"
+		}
+
+		if options.NoisePersistence <= 0.0 || options.NoisePersistence > 1.0 {
+			return false, "the noise persistence must be between values 0 (exclusive) and 1"
+		}
+	}
+
+	return true, ""
+}
+
+// Return a boolean value indicating if the interval hysteresis stay thresholds are valid and a string containing validation failure message
+func (options *SorterOptions) areStayThresholdsValid() (bool, string) {
+	switch options.IntervalDeterminant {
+	case SplitByMask, SplitByEdgeDetection, SplitByRgbChannels, SplitByLocalDifference, SplitByClusters:
+		return false, "the interval hysteresis is not supported by the mask, edge, rgb channels, local difference and clusters interval determinants"
+	case SplitByExpression:
+		return false, "the interval hysteresis is not supported by the expression interval determinant, the stay thresholds can not be applied to the expression clauses"
+	}
+
+	lower, upper := options.IntervalStayLowerThreshold, options.IntervalStayUpperThreshold
+	if lower < 0.0 || lower > 1.0 || upper < 0.0 || upper > 1.0 {
+		return false, "the interval stay thresholds must be between values 0 and 1"
+	}
+
+	if lower > upper && (!options.IntervalDeterminantWrapAround || options.IntervalDeterminant != SplitByHue) {
+		return false, "lower interval stay threshold must no be greater than the upper one unless the hue wrap-around is used"
+	}
+
+	// NOTE: The containment can only be verified for the manually selected interval determinant thresholds
+	if options.IntervalThresholdSelection == ThresholdManual {
+		if lower > options.IntervalDeterminantLowerThreshold || upper < options.IntervalDeterminantUpperThreshold {
+			return false, "the interval stay thresholds range must contain the interval determinant thresholds range"
+		}
+	}
+
+	return true, ""
+}
+
+// Return a boolean value indicating if the given interval determinant is used directly or by any clause of the interval expression
+func (options *SorterOptions) usesIntervalDeterminant(determinant IntervalDeterminant) bool {
+	if options.IntervalDeterminant == determinant {
+		return true
+	}
+
+	return options.IntervalDeterminant == SplitByExpression && options.IntervalExpression.usesDeterminant(determinant)
+}
+
+// Get the seed of the random decisions. A random seed is drawn on every call if the seed is not specified, so the random decisions are not
+// reproducible unless the seed is explicitly set
+func (options *SorterOptions) getSeed() int64 {
+	if options.Seed == nil {
+		return int64(utils.CIntn(math.MaxInt))
+	}
+
+	return *options.Seed
+}
+
+// Return a boolean value indicating if the noise field is used by the sort determinant, interval determinant, interval length source or
+// the direction scheme
+func (options *SorterOptions) usesNoise() bool {
+	return options.SortDeterminant == SortByNoise ||
+		options.usesIntervalDeterminant(SplitByNoise) ||
+		options.IntervalLengthSource == IntervalLengthNoise ||
+		options.DirectionScheme == DirectionByNoise
+}
+
+// Return a boolean value indicating if the given channel thresholds are valid and a string containing validation failure message
+func (thresholds *ChannelThresholds) areValid() (bool, string) {
+	channels := []struct {
+		name  string
+		lower float64
+		upper float64
+	}{
+		{"red", thresholds.RedLower, thresholds.RedUpper},
+		{"green", thresholds.GreenLower, thresholds.GreenUpper},
+		{"blue", thresholds.BlueLower, thresholds.BlueUpper},
+	}
+
+	for _, channel := range channels {
+		if channel.lower < 0.0 || channel.lower > 1.0 || channel.upper < 0.0 || channel.upper > 1.0 {
+			return false, fmt.Sprintf("the %s channel interval thresholds must be between values 0 and 1", channel.name)
+		}
+
+		if channel.lower > channel.upper {
+			return false, fmt.Sprintf("the %s channel lower interval threshold must no be greater than the upper one", channel.name)
+		}
+	}
+
 	return true, ""
 }
 
@@ -129,14 +495,70 @@ func GetDefaultSorterOptions() *SorterOptions {
 	options.SortOrder = SortHorizontalAndVertical
 	options.IntervalDeterminant = SplitByBrightness
 	options.IntervalPainting = IntervalFill
+	options.DirectionScheme = DirectionUniform
+	options.DirectionBrightnessThreshold = 0.5
+	options.SortStrength = 1.0
+	options.ChannelSplit = ChannelSplitNone
+	options.ChannelSplitDeterminant = ChannelSortByOwnValue
+	options.ChannelOptions = [4]ChannelOptions{
+		{LowerThreshold: 0.0, UpperThreshold: 1.0, AngleOffset: 0},
+		{LowerThreshold: 0.0, UpperThreshold: 1.0, AngleOffset: 0},
+		{LowerThreshold: 0.0, UpperThreshold: 1.0, AngleOffset: 0},
+		{LowerThreshold: 0.0, UpperThreshold: 1.0, AngleOffset: 0},
+	}
+	options.GradientStops = 0
+	options.GradientColorSpace = GradientSrgb
+	options.QuantizeLevels = 4
+	options.QuantizePalette = nil
+	options.QuantizeDithering = DitheringNone
 	options.IntervalDeterminantLowerThreshold = 0.0
 	options.IntervalDeterminantUpperThreshold = 1.0
+	options.IntervalDeterminantWrapAround = false
+	options.IntervalExpression = nil
+	options.ClusterCount = 8
+	options.ClusterSeed = 0
+	options.ClusterIndices = nil
+	options.IntervalHysteresis = false
+	options.IntervalStayLowerThreshold = 0.0
+	options.IntervalStayUpperThreshold = 1.0
+	options.IntervalGapTolerance = 0
+	options.IntervalMinLength = 0
+	options.IntervalChannelThresholds = ChannelThresholds{
+		RedLower:   0.0,
+		RedUpper:   1.0,
+		GreenLower: 0.0,
+		GreenUpper: 1.0,
+		BlueLower:  0.0,
+		BlueUpper:  1.0,
+	}
+	options.IntervalDifferenceMetric = DifferenceBrightness
+	options.IntervalDifferenceThreshold = 0.1
+	options.IntervalThresholdSelection = ThresholdManual
+	options.IntervalLowerPercentile = 0.0
+	options.IntervalUpperPercentile = 100.0
 	options.UseMask = false
+	options.MaskThreshold = 127
+	options.MaskFeatherRadius = 0.0
+	options.SoftMask = false
 	options.IntervalLength = 0
 	options.IntervalLengthRandomFactor = 0
+	options.IntervalLengthSource = IntervalLengthFixed
+	options.IntervalLengthDistribution = DistributionUniform
+	options.IntervalLengthDeviation = 0.5
+	options.IntervalLengthExponent = 2.0
+	options.Seed = nil
+	options.NoiseSeed = 0
+	options.NoiseScale = 100.0
+	options.NoiseOctaves = 4
+	options.NoisePersistence = 0.5
 	options.Cycles = 1
+	options.CycleSchedule = CycleSchedule{}
+	options.CompositeAngles = nil
+	options.AngleComposite = AngleCompositeLighten
+	options.CompositeMemoryBudget = 0
 	options.Scale = 1
 	options.Blending = BlendingNone
+	options.BlendingOpacity = 1.0
 
 	return options
 }
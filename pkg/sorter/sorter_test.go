@@ -1,6 +1,7 @@
 package sorter
 
 import (
+	"image/color"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -112,3 +113,454 @@ func TestSorterOptionsShouldNotValidateIntervalLengthRandomFactor(t *testing.T)
 	assert.False(t, valid)
 	assert.NotEmpty(t, msg)
 }
+
+func TestSorterOptionsShouldNotValidateInvalidNoiseParameters(t *testing.T) {
+	cases := []func(options *SorterOptions){
+		func(options *SorterOptions) { options.NoiseScale = 0 },
+		func(options *SorterOptions) { options.NoiseScale = -10 },
+		func(options *SorterOptions) { options.NoiseOctaves = 0 },
+		func(options *SorterOptions) { options.NoiseOctaves = 17 },
+		func(options *SorterOptions) { options.NoisePersistence = 0 },
+		func(options *SorterOptions) { options.NoisePersistence = 1.5 },
+	}
+
+	for _, modify := range cases {
+		options := GetDefaultSorterOptions()
+		options.SortDeterminant = SortByNoise
+		modify(options)
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldIgnoreNoiseParametersIfTheNoiseIsNotUsed(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.NoiseScale = 0
+	options.NoiseOctaves = 0
+	options.NoisePersistence = 0
+
+	valid, msg := options.AreValid()
+
+	assert.True(t, valid)
+	assert.Empty(t, msg)
+
+	options.IntervalDeterminant = SplitByNoise
+
+	valid, msg = options.AreValid()
+
+	assert.False(t, valid)
+	assert.NotEmpty(t, msg)
+}
+
+func TestSorterOptionsShouldNotValidateInvalidIntervalExpression(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByExpression
+
+	valid, msg := options.AreValid()
+
+	assert.False(t, valid)
+	assert.NotEmpty(t, msg)
+
+	options.IntervalExpression = CreateIntervalAndExpression(CreateIntervalClauseExpression(SplitByBrightness, 0.2, 0.8))
+
+	valid, msg = options.AreValid()
+
+	assert.False(t, valid)
+	assert.NotEmpty(t, msg)
+
+	options.IntervalExpression = CreateIntervalClauseExpression(SplitByBrightness, 0.2, 0.8)
+	options.IntervalThresholdSelection = ThresholdOtsu
+
+	valid, msg = options.AreValid()
+
+	assert.False(t, valid)
+	assert.NotEmpty(t, msg)
+}
+
+func TestSorterOptionsShouldNotValidateInvalidIntervalHysteresis(t *testing.T) {
+	cases := []func(options *SorterOptions){
+		func(options *SorterOptions) { options.IntervalStayLowerThreshold = -0.1 },
+		func(options *SorterOptions) { options.IntervalStayUpperThreshold = 1.1 },
+		func(options *SorterOptions) { options.IntervalStayLowerThreshold = 0.5 },
+		func(options *SorterOptions) { options.IntervalStayUpperThreshold = 0.5 },
+		func(options *SorterOptions) { options.IntervalDeterminant = SplitByEdgeDetection },
+		func(options *SorterOptions) {
+			options.IntervalDeterminant = SplitByExpression
+			options.IntervalExpression, _ = ParseIntervalExpression("brightness(0.3, 0.7)")
+		},
+	}
+
+	for _, modify := range cases {
+		options := GetDefaultSorterOptions()
+		options.IntervalDeterminantLowerThreshold = 0.3
+		options.IntervalDeterminantUpperThreshold = 0.7
+		options.IntervalHysteresis = true
+		modify(options)
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldValidateIntervalHysteresis(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminantLowerThreshold = 0.3
+	options.IntervalDeterminantUpperThreshold = 0.7
+	options.IntervalHysteresis = true
+	options.IntervalStayLowerThreshold = 0.2
+	options.IntervalStayUpperThreshold = 0.8
+
+	valid, msg := options.AreValid()
+
+	assert.True(t, valid)
+	assert.Empty(t, msg)
+}
+
+func TestSorterOptionsShouldNotValidateInvalidIntervalGapToleranceAndMinLength(t *testing.T) {
+	cases := []func(options *SorterOptions){
+		func(options *SorterOptions) { options.IntervalGapTolerance = -1 },
+		func(options *SorterOptions) { options.IntervalMinLength = -1 },
+		func(options *SorterOptions) {
+			options.IntervalLength = 5
+			options.IntervalMinLength = 6
+		},
+	}
+
+	for _, modify := range cases {
+		options := GetDefaultSorterOptions()
+		modify(options)
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldNotValidateInvalidIntervalLengthDistributionParameters(t *testing.T) {
+	cases := []func(options *SorterOptions){
+		func(options *SorterOptions) { options.IntervalLengthDeviation = -0.1 },
+		func(options *SorterOptions) { options.IntervalLengthExponent = 1.0 },
+		func(options *SorterOptions) { options.IntervalLengthExponent = 0.5 },
+	}
+
+	for _, modify := range cases {
+		options := GetDefaultSorterOptions()
+		options.IntervalLengthDistribution = DistributionPowerLaw
+		modify(options)
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldIgnoreIntervalLengthExponentForOtherDistributions(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalLengthDistribution = DistributionNormal
+	options.IntervalLengthExponent = 0
+
+	valid, msg := options.AreValid()
+
+	assert.True(t, valid)
+	assert.Empty(t, msg)
+}
+
+func TestSorterOptionsShouldNotValidateInvalidClusterParameters(t *testing.T) {
+	cases := []func(options *SorterOptions){
+		func(options *SorterOptions) { options.ClusterCount = 1 },
+		func(options *SorterOptions) { options.ClusterCount = 256 },
+		func(options *SorterOptions) { options.ClusterIndices = []int{-1} },
+		func(options *SorterOptions) { options.ClusterIndices = []int{0, 8} },
+		func(options *SorterOptions) { options.IntervalThresholdSelection = ThresholdOtsu },
+	}
+
+	for _, modify := range cases {
+		options := GetDefaultSorterOptions()
+		options.IntervalDeterminant = SplitByClusters
+		modify(options)
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldIgnoreClusterParametersForOtherIntervalDeterminants(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.ClusterCount = 0
+	options.ClusterIndices = []int{-1}
+
+	valid, msg := options.AreValid()
+
+	assert.True(t, valid)
+	assert.Empty(t, msg)
+}
+
+func TestSorterOptionsShouldNotValidateInvalidGradientStops(t *testing.T) {
+	cases := []int{-1, 1, 257}
+
+	for _, stops := range cases {
+		options := GetDefaultSorterOptions()
+		options.GradientStops = stops
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldNotValidateInvalidQuantizeParameters(t *testing.T) {
+	cases := []func(options *SorterOptions){
+		func(options *SorterOptions) { options.QuantizeLevels = 1 },
+		func(options *SorterOptions) { options.QuantizeLevels = 257 },
+		func(options *SorterOptions) { options.QuantizePalette = make(color.Palette, 257) },
+	}
+
+	for _, modify := range cases {
+		options := GetDefaultSorterOptions()
+		options.IntervalPainting = IntervalQuantize
+		modify(options)
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldIgnoreQuantizeLevelsForOtherIntervalPaintings(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.QuantizeLevels = 0
+
+	valid, msg := options.AreValid()
+
+	assert.True(t, valid)
+	assert.Empty(t, msg)
+}
+
+func TestSorterOptionsShouldNotValidateInvalidSortStrength(t *testing.T) {
+	cases := []float64{-0.1, 1.1}
+
+	for _, strength := range cases {
+		options := GetDefaultSorterOptions()
+		options.SortStrength = strength
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldNotValidateInvalidDirectionSchemeOptions(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.DirectionScheme = DirectionAlternateStrips
+	options.SortDirection = Shuffle
+
+	valid, msg := options.AreValid()
+	assert.False(t, valid)
+	assert.NotEmpty(t, msg)
+
+	options = GetDefaultSorterOptions()
+	options.DirectionScheme = DirectionByBrightness
+	options.DirectionBrightnessThreshold = 1.5
+
+	valid, msg = options.AreValid()
+	assert.False(t, valid)
+	assert.NotEmpty(t, msg)
+}
+
+func TestSorterOptionsShouldNotValidateInvalidChannelSplitOptions(t *testing.T) {
+	cases := []func(options *SorterOptions){
+		func(options *SorterOptions) { options.ChannelOptions[ChannelRed].LowerThreshold = -0.1 },
+		func(options *SorterOptions) { options.ChannelOptions[ChannelBlue].UpperThreshold = 1.1 },
+		func(options *SorterOptions) {
+			options.ChannelOptions[ChannelGreen].LowerThreshold = 0.8
+			options.ChannelOptions[ChannelGreen].UpperThreshold = 0.2
+		},
+	}
+
+	for _, modify := range cases {
+		options := GetDefaultSorterOptions()
+		options.ChannelSplit = ChannelSplitRgb
+		modify(options)
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldValidateAlphaChannelOptionsOnlyForRgbaChannelSplit(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.ChannelSplit = ChannelSplitRgb
+	options.ChannelOptions[ChannelAlpha].LowerThreshold = 2
+
+	valid, _ := options.AreValid()
+	assert.True(t, valid)
+
+	options.ChannelSplit = ChannelSplitRgba
+
+	valid, _ = options.AreValid()
+	assert.False(t, valid)
+}
+
+func TestSorterOptionsShouldNotValidateInvalidIntervalPercentiles(t *testing.T) {
+	cases := []struct {
+		lower float64
+		upper float64
+	}{
+		{-1.0, 50.0},
+		{50.0, 101.0},
+		{80.0, 20.0},
+	}
+
+	for _, c := range cases {
+		options := GetDefaultSorterOptions()
+		options.IntervalThresholdSelection = ThresholdPercentile
+		options.IntervalLowerPercentile = c.lower
+		options.IntervalUpperPercentile = c.upper
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldNotValidateAutomaticThresholdSelectionForMaskDeterminants(t *testing.T) {
+	cases := []IntervalDeterminant{
+		SplitByMask,
+		SplitByEdgeDetection,
+	}
+
+	for _, determinant := range cases {
+		options := GetDefaultSorterOptions()
+		options.IntervalDeterminant = determinant
+		options.IntervalThresholdSelection = ThresholdOtsu
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldValidateHueWrapAroundThresholds(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByHue
+	options.IntervalDeterminantWrapAround = true
+	options.IntervalDeterminantLowerThreshold = 0.9
+	options.IntervalDeterminantUpperThreshold = 0.1
+
+	valid, msg := options.AreValid()
+
+	assert.True(t, valid)
+	assert.Empty(t, msg)
+}
+
+func TestSorterOptionsShouldNotValidateWrapAroundThresholdsForNonHueDeterminant(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByBrightness
+	options.IntervalDeterminantWrapAround = true
+	options.IntervalDeterminantLowerThreshold = 0.9
+	options.IntervalDeterminantUpperThreshold = 0.1
+
+	valid, msg := options.AreValid()
+
+	assert.False(t, valid)
+	assert.NotEmpty(t, msg)
+}
+
+func TestSorterOptionsShouldValidateExpressionHueWrapAroundClauseOnlyWithHueWrapAround(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminant = SplitByExpression
+	options.IntervalExpression = CreateIntervalAndExpression(
+		CreateIntervalClauseExpression(SplitByBrightness, 0.2, 0.8),
+		CreateIntervalClauseExpression(SplitByHue, 0.9, 0.1))
+
+	valid, msg := options.AreValid()
+
+	assert.False(t, valid)
+	assert.NotEmpty(t, msg)
+
+	options.IntervalDeterminantWrapAround = true
+
+	valid, msg = options.AreValid()
+
+	assert.True(t, valid)
+	assert.Empty(t, msg)
+}
+
+func TestSorterOptionsShouldNotValidateInvalidChannelThresholds(t *testing.T) {
+	cases := []ChannelThresholds{
+		{-0.5, 1.0, 0.0, 1.0, 0.0, 1.0},
+		{0.0, 1.0, 0.0, 1.5, 0.0, 1.0},
+		{0.0, 1.0, 0.0, 1.0, 0.8, 0.2},
+	}
+
+	for _, thresholds := range cases {
+		options := GetDefaultSorterOptions()
+		options.IntervalChannelThresholds = thresholds
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldNotValidateInvalidAngleCompositeOptions(t *testing.T) {
+	cases := []func(options *SorterOptions){
+		func(options *SorterOptions) {
+			options.CompositeAngles = []int{0, 90}
+			options.ChannelSplit = ChannelSplitRgb
+		},
+		func(options *SorterOptions) {
+			options.CompositeAngles = []int{0, 90}
+			options.CompositeMemoryBudget = -1
+		},
+	}
+
+	for _, modify := range cases {
+		options := GetDefaultSorterOptions()
+		modify(options)
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldNotValidateInvalidBlendingOpacity(t *testing.T) {
+	for _, opacity := range []float64{-0.1, 1.1} {
+		options := GetDefaultSorterOptions()
+		options.BlendingOpacity = opacity
+
+		valid, msg := options.AreValid()
+
+		assert.False(t, valid)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+func TestSorterOptionsShouldNotValidateNegativeMaskFeatherRadius(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.MaskFeatherRadius = -1
+
+	valid, msg := options.AreValid()
+
+	assert.False(t, valid)
+	assert.NotEmpty(t, msg)
+}
@@ -28,11 +28,32 @@ type BufferedSorterState interface {
 	// Set the buffered rotated images associated to the incoming sorter options changes
 	SetRotatedImages(img, maskImage *image.NRGBA)
 
+	// Get the buffered scaled auxiliary images and a boolean value indicating if the values were buffered
+	GetScaledAuxiliaryImages() (*auxiliaryImagesNrgba, bool)
+
+	// Set the buffered scaled auxiliary images associated to the incoming sorter options changes
+	SetScaledAuxiliaryImages(auxiliary *auxiliaryImagesNrgba)
+
+	// Get the buffered rotated auxiliary images and a boolean value indicating if the values were buffered
+	GetRotatedAuxiliaryImages() (*auxiliaryImagesNrgba, bool)
+
+	// Set the buffered rotated auxiliary images associated to the incoming sorter options changes
+	SetRotatedAuxiliaryImages(auxiliary *auxiliaryImagesNrgba)
+
 	// Get the buffered edge detection image and a boolean value indicating if the value were buffered
 	GetEdgeDetectionImage() (*image.NRGBA, bool)
 
 	// Set the buffered edge detection image associated to the incoming sorter options changes
 	SetEdgeDetectionImage(img *image.NRGBA)
+
+	// Get the buffered color clusters and a boolean value indicating if the value were buffered
+	GetColorClusters() (*colorClusters, bool)
+
+	// Set the buffered color clusters associated to the incoming sorter options changes
+	SetColorClusters(clusters *colorClusters)
+
+	// Remove the buffered rotated images, rotated auxiliary images, edge detection image and color clusters, which are depending on the angle
+	ResetRotatedImages()
 }
 
 func CreateBufferedSorterState() BufferedSorterState {
@@ -42,6 +63,9 @@ func CreateBufferedSorterState() BufferedSorterState {
 		ImageScaled:        nil,
 		ImageRotated:       nil,
 		ImageEdgeDetection: nil,
+		AuxiliaryScaled:    nil,
+		AuxiliaryRotated:   nil,
+		ColorClusters:      nil,
 		Commited:           false,
 	}
 }
@@ -52,6 +76,9 @@ type bufferedSorterState struct {
 	ImageScaled        *BufferedPairEntry[*image.NRGBA]
 	ImageRotated       *BufferedPairEntry[*image.NRGBA]
 	ImageEdgeDetection *BufferedEntry[*image.NRGBA]
+	AuxiliaryScaled    *BufferedEntry[*auxiliaryImagesNrgba]
+	AuxiliaryRotated   *BufferedEntry[*auxiliaryImagesNrgba]
+	ColorClusters      *BufferedEntry[*colorClusters]
 	Commited           bool
 }
 
@@ -107,6 +134,10 @@ func (state *bufferedSorterState) GetRotatedImages() (*image.NRGBA, *image.NRGBA
 		return nil, nil, false
 	}
 
+	if state.CurrentOptions.MaskFeatherRadius != state.IncomingOptions.MaskFeatherRadius {
+		return nil, nil, false
+	}
+
 	return state.ImageRotated.First, state.ImageRotated.Second, true
 }
 
@@ -115,9 +146,61 @@ func (state *bufferedSorterState) GetScaledImages() (*image.NRGBA, *image.NRGBA,
 		return nil, nil, false
 	}
 
+	if state.CurrentOptions.MaskFeatherRadius != state.IncomingOptions.MaskFeatherRadius {
+		return nil, nil, false
+	}
+
 	return state.ImageScaled.First, state.ImageScaled.Second, true
 }
 
+func (state *bufferedSorterState) GetScaledAuxiliaryImages() (*auxiliaryImagesNrgba, bool) {
+	if state.AuxiliaryScaled == nil || state.CurrentOptions.Scale != state.IncomingOptions.Scale {
+		return nil, false
+	}
+
+	return state.AuxiliaryScaled.First, true
+}
+
+func (state *bufferedSorterState) GetRotatedAuxiliaryImages() (*auxiliaryImagesNrgba, bool) {
+	if state.AuxiliaryRotated == nil {
+		return nil, false
+	}
+
+	if state.CurrentOptions.Scale != state.IncomingOptions.Scale {
+		return nil, false
+	}
+
+	if state.CurrentOptions.Angle != state.IncomingOptions.Angle {
+		return nil, false
+	}
+
+	return state.AuxiliaryRotated.First, true
+}
+
+func (state *bufferedSorterState) GetColorClusters() (*colorClusters, bool) {
+	if state.ColorClusters == nil {
+		return nil, false
+	}
+
+	if state.CurrentOptions.Scale != state.IncomingOptions.Scale {
+		return nil, false
+	}
+
+	if state.CurrentOptions.Angle != state.IncomingOptions.Angle {
+		return nil, false
+	}
+
+	if state.CurrentOptions.ClusterCount != state.IncomingOptions.ClusterCount {
+		return nil, false
+	}
+
+	if state.CurrentOptions.ClusterSeed != state.IncomingOptions.ClusterSeed {
+		return nil, false
+	}
+
+	return state.ColorClusters.First, true
+}
+
 func (state *bufferedSorterState) Rollback() {
 	if state.Commited {
 		return
@@ -128,6 +211,9 @@ func (state *bufferedSorterState) Rollback() {
 	state.ImageScaled = nil
 	state.ImageRotated = nil
 	state.ImageEdgeDetection = nil
+	state.AuxiliaryScaled = nil
+	state.AuxiliaryRotated = nil
+	state.ColorClusters = nil
 	state.Commited = false
 }
 
@@ -151,6 +237,31 @@ func (state *bufferedSorterState) SetScaledImages(img *image.NRGBA, maskImage *i
 	}
 }
 
+func (state *bufferedSorterState) SetScaledAuxiliaryImages(auxiliary *auxiliaryImagesNrgba) {
+	state.AuxiliaryScaled = &BufferedEntry[*auxiliaryImagesNrgba]{
+		First: auxiliary,
+	}
+}
+
+func (state *bufferedSorterState) SetRotatedAuxiliaryImages(auxiliary *auxiliaryImagesNrgba) {
+	state.AuxiliaryRotated = &BufferedEntry[*auxiliaryImagesNrgba]{
+		First: auxiliary,
+	}
+}
+
+func (state *bufferedSorterState) SetColorClusters(clusters *colorClusters) {
+	state.ColorClusters = &BufferedEntry[*colorClusters]{
+		First: clusters,
+	}
+}
+
+func (state *bufferedSorterState) ResetRotatedImages() {
+	state.ImageRotated = nil
+	state.ImageEdgeDetection = nil
+	state.AuxiliaryRotated = nil
+	state.ColorClusters = nil
+}
+
 type BufferedEntry[TEntry any] struct {
 	First TEntry
 }
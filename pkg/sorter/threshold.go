@@ -0,0 +1,221 @@
+package sorter
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/Krzysztofz01/pixel-sorter/pkg/utils"
+)
+
+const (
+	thresholdHistogramBins = 1024
+)
+
+// Calculate the interval determinant thresholds for the given image according to the threshold selection method specified by the options.
+// The function can be used to preview the thresholds that will be selected by the sorter, so the image, mask and auxiliary images are scaled,
+// feathered and rotated according to the options in the same way as by the sorter. The thresholds of the scheduled cycles and the composite
+// angles are not taken under account. For the manual threshold selection, the thresholds specified by the options are returned. The auxiliary
+// images are only required by determinants which are using them.
+func CalculateIntervalDeterminantThresholds(img image.Image, mask image.Image, auxiliary *AuxiliaryImages, options *SorterOptions) (float64, float64, error) {
+	if img == nil {
+		return 0, 0, errors.New("sorter: can not calculate the thresholds for the provided nil image")
+	}
+
+	if options == nil {
+		options = GetDefaultSorterOptions()
+	}
+
+	if valid, msg := options.AreValid(); !valid {
+		return 0, 0, fmt.Errorf("sorter: %s", msg)
+	}
+
+	if mask != nil && img.Bounds() != mask.Bounds() {
+		return 0, 0, errors.New("sorter: can not calculate the thresholds for a image and mask with bounds that are not matching")
+	}
+
+	auxiliaryNrgba, err := createAuxiliaryImagesNrgba(auxiliary, img.Bounds())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sorter := &defaultSorter{
+		image:     utils.ImageToNrgbaImage(img),
+		auxiliary: auxiliaryNrgba,
+		logger:    getDiscardLogger(),
+	}
+
+	if mask != nil {
+		sorter.maskImage = utils.ImageToNrgbaImage(mask)
+	}
+
+	// NOTE: The thresholds are calculated on the image scaled and rotated in the same way as the image sorted by the sorter
+	srcImageNrgba, sources, _, err := sorter.prepareSortingImages(sorter.image, options)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return calculateIntervalDeterminantThresholds(utils.NrgbaToRgbaImage(srcImageNrgba), sources, options)
+}
+
+// Function used to calculate the interval determinant thresholds for the given image according to the threshold selection method. The
+// transparent pixels and the masked pixels (if the mask is used) are not taken under account.
+func calculateIntervalDeterminantThresholds(img *image.RGBA, sources *sortingSources, options *SorterOptions) (float64, float64, error) {
+	if options.IntervalThresholdSelection == ThresholdManual {
+		return options.IntervalDeterminantLowerThreshold, options.IntervalDeterminantUpperThreshold, nil
+	}
+
+	histogram, err := calculateIntervalDeterminantHistogram(img, sources, options)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	switch options.IntervalThresholdSelection {
+	case ThresholdOtsu:
+		{
+			return calculateOtsuThreshold(histogram), 1.0, nil
+		}
+	case ThresholdPercentile:
+		{
+			lower := calculatePercentileThreshold(histogram, options.IntervalLowerPercentile)
+			upper := calculatePercentileThreshold(histogram, options.IntervalUpperPercentile)
+
+			return lower, upper, nil
+		}
+	default:
+		panic("sorter: invalid threshold selection specified")
+	}
+}
+
+// Function used to calculate the histogram of the interval determinant values of the given image
+func calculateIntervalDeterminantHistogram(img *image.RGBA, sources *sortingSources, options *SorterOptions) ([]int, error) {
+	var (
+		histogram []int = make([]int, thresholdHistogramBins)
+		c         color.RGBA
+	)
+
+	for index := 0; index < len(img.Pix); index += 4 {
+		c.R, c.G, c.B, c.A = img.Pix[index+0], img.Pix[index+1], img.Pix[index+2], img.Pix[index+3]
+
+		if c.A < 255 {
+			continue
+		}
+
+		if options.UseMask {
+			isMasked, err := sources.mask.AtByIndexT(index/4, options.MaskThreshold)
+			if err != nil {
+				return nil, fmt.Errorf("sorter: failed to perform a lookup to the mask image: %w", err)
+			}
+
+			if isMasked {
+				continue
+			}
+		}
+
+		value, ok := calculateIntervalDeterminantValue(c, options.IntervalDeterminant, sources.sourceValueAt(options.IntervalDeterminant, index/4))
+		if !ok {
+			return nil, errors.New("sorter: the automatic threshold selection is not supported by the interval determinant")
+		}
+
+		bin := utils.ClampInt(0, int(value*float64(thresholdHistogramBins-1)), thresholdHistogramBins-1)
+		histogram[bin] += 1
+	}
+
+	return histogram, nil
+}
+
+// Function used to calculate the threshold splitting the given histogram into two classes using the Otsu's method. The threshold is
+// expressed as a normalized value (0.0 - 1.0) at which the class with the greater values starts.
+func calculateOtsuThreshold(histogram []int) float64 {
+	var (
+		total       int     = 0
+		sum         float64 = 0
+		sumBack     float64 = 0
+		weightBack  int     = 0
+		maxVariance float64 = math.Inf(-1)
+		threshold   int     = 0
+	)
+
+	for bin, count := range histogram {
+		total += count
+		sum += float64(bin * count)
+	}
+
+	if total == 0 {
+		return 0.0
+	}
+
+	for bin, count := range histogram {
+		weightBack += count
+		if weightBack == 0 {
+			continue
+		}
+
+		weightFore := total - weightBack
+		if weightFore == 0 {
+			break
+		}
+
+		sumBack += float64(bin * count)
+
+		meanBack := sumBack / float64(weightBack)
+		meanFore := (sum - sumBack) / float64(weightFore)
+
+		variance := float64(weightBack) * float64(weightFore) * (meanBack - meanFore) * (meanBack - meanFore)
+		if variance > maxVariance {
+			maxVariance = variance
+			threshold = bin + 1
+		}
+	}
+
+	return utils.ClampFloat64(0.0, float64(threshold)/float64(len(histogram)-1), 1.0)
+}
+
+// Function used to calculate the normalized value (0.0 - 1.0) below which the given percentage (0 - 100) of the histogram values is located
+func calculatePercentileThreshold(histogram []int, percentile float64) float64 {
+	total := 0
+	for _, count := range histogram {
+		total += count
+	}
+
+	if total == 0 {
+		return 0.0
+	}
+
+	target := percentile / 100.0 * float64(total)
+	cumulative := 0
+
+	for bin, count := range histogram {
+		cumulative += count
+
+		if float64(cumulative) >= target {
+			return float64(bin) / float64(len(histogram)-1)
+		}
+	}
+
+	return 1.0
+}
+
+// Function used to resolve the sorter options with the automatically selected interval determinant thresholds. The function returns a copy
+// of the options with the selected thresholds and the manual threshold selection or the original options if the selection is manual.
+func resolveIntervalDeterminantThresholds(img *image.RGBA, sources *sortingSources, options *SorterOptions, logger SorterLogger) (*SorterOptions, error) {
+	if options.IntervalThresholdSelection == ThresholdManual {
+		return options, nil
+	}
+
+	lower, upper, err := calculateIntervalDeterminantThresholds(img, sources, options)
+	if err != nil {
+		return nil, fmt.Errorf("sorter: failed to calculate the interval determinant thresholds: %w", err)
+	}
+
+	logger.Infof("Selected interval determinant thresholds. Lower: %.4f Upper: %.4f", lower, upper)
+
+	resolved := *options
+	resolved.IntervalDeterminantLowerThreshold = lower
+	resolved.IntervalDeterminantUpperThreshold = upper
+	resolved.IntervalThresholdSelection = ThresholdManual
+
+	return &resolved, nil
+}
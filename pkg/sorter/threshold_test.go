@@ -0,0 +1,102 @@
+package sorter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateOtsuThresholdShouldSplitBimodalHistogram(t *testing.T) {
+	histogram := make([]int, thresholdHistogramBins)
+	histogram[100] = 50
+	histogram[900] = 50
+
+	threshold := calculateOtsuThreshold(histogram)
+
+	assert.Greater(t, threshold, 100.0/float64(thresholdHistogramBins-1))
+	assert.LessOrEqual(t, threshold, 900.0/float64(thresholdHistogramBins-1))
+}
+
+func TestCalculateOtsuThresholdShouldReturnZeroForEmptyHistogram(t *testing.T) {
+	histogram := make([]int, thresholdHistogramBins)
+
+	assert.Equal(t, 0.0, calculateOtsuThreshold(histogram))
+}
+
+func TestCalculatePercentileThresholdShouldCalculateCorrectThreshold(t *testing.T) {
+	histogram := []int{10, 10, 10, 10, 10}
+
+	cases := []struct {
+		percentile float64
+		expected   float64
+	}{
+		{0.0, 0.0},
+		{20.0, 0.0},
+		{40.0, 0.25},
+		{50.0, 0.5},
+		{100.0, 1.0},
+	}
+
+	for _, c := range cases {
+		actual := calculatePercentileThreshold(histogram, c.percentile)
+
+		assert.Equal(t, c.expected, actual)
+	}
+}
+
+func TestCalculateIntervalDeterminantThresholdsShouldReturnManualThresholds(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalDeterminantLowerThreshold = 0.2
+	options.IntervalDeterminantUpperThreshold = 0.7
+
+	lower, upper, err := CalculateIntervalDeterminantThresholds(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0.2, lower)
+	assert.Equal(t, 0.7, upper)
+}
+
+func TestCalculateIntervalDeterminantThresholdsShouldSelectPercentileThresholds(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalThresholdSelection = ThresholdPercentile
+	options.IntervalLowerPercentile = 10
+	options.IntervalUpperPercentile = 90
+
+	lower, upper, err := CalculateIntervalDeterminantThresholds(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, lower)
+	assert.Equal(t, 1.0, upper)
+}
+
+func TestCalculateIntervalDeterminantThresholdsShouldSelectOtsuThresholds(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalThresholdSelection = ThresholdOtsu
+
+	lower, upper, err := CalculateIntervalDeterminantThresholds(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.Nil(t, err)
+	assert.Greater(t, lower, 0.0)
+	assert.Less(t, lower, 1.0)
+	assert.Equal(t, 1.0, upper)
+}
+
+func TestCalculateIntervalDeterminantThresholdsShouldSelectThresholdsOfTheScaledImage(t *testing.T) {
+	options := GetDefaultSorterOptions()
+	options.IntervalThresholdSelection = ThresholdPercentile
+	options.IntervalLowerPercentile = 10
+	options.IntervalUpperPercentile = 90
+	options.Scale = 0.5
+
+	lower, upper, err := CalculateIntervalDeterminantThresholds(mockTestBlackAndWhiteStripesImage(), nil, nil, options)
+
+	assert.Nil(t, err)
+	assert.Greater(t, lower, 0.0)
+	assert.Less(t, upper, 1.0)
+}
+
+func TestCalculateIntervalDeterminantThresholdsShouldNotCalculateForNilImage(t *testing.T) {
+	_, _, err := CalculateIntervalDeterminantThresholds(nil, nil, nil, nil)
+
+	assert.NotNil(t, err)
+}
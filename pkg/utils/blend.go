@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"image/color"
+	"math"
+)
+
+// Perform blending of two color.NRGBA colors according to a given blending mode, where the first color is the backdrop and the second color
+// is the blended source. The blended color is composited over the backdrop according to the alpha of both colors (source-over), so for the
+// opaque colors the result is the blended color. https://www.w3.org/TR/compositing-1/#blending
+func BlendNrgba(a, b color.NRGBA, mode BlendingMode) color.NRGBA {
+	var (
+		backdrop [3]float64 = [3]float64{float64(a.R) / 255.0, float64(a.G) / 255.0, float64(a.B) / 255.0}
+		source   [3]float64 = [3]float64{float64(b.R) / 255.0, float64(b.G) / 255.0, float64(b.B) / 255.0}
+		blended  [3]float64
+	)
+
+	switch mode {
+	case Hue:
+		blended = setLuminosity(setSaturation(source, saturation(backdrop)), luminosity(backdrop))
+	case Saturation:
+		blended = setLuminosity(setSaturation(backdrop, saturation(source)), luminosity(backdrop))
+	case Color:
+		blended = setLuminosity(source, luminosity(backdrop))
+	case Luminosity:
+		blended = setLuminosity(backdrop, luminosity(source))
+	default:
+		for channel := 0; channel < 3; channel += 1 {
+			blended[channel] = blendChannel(backdrop[channel], source[channel], mode)
+		}
+	}
+
+	backdropAlpha := float64(a.A) / 255.0
+	sourceAlpha := float64(b.A) / 255.0
+
+	alpha := sourceAlpha + backdropAlpha*(1.0-sourceAlpha)
+	if alpha == 0.0 {
+		return color.NRGBA{0, 0, 0, 0}
+	}
+
+	var composited [3]uint8
+	for channel := 0; channel < 3; channel += 1 {
+		value := sourceAlpha*(1.0-backdropAlpha)*source[channel] +
+			sourceAlpha*backdropAlpha*blended[channel] +
+			(1.0-sourceAlpha)*backdropAlpha*backdrop[channel]
+
+		composited[channel] = uint8(math.Round(ClampFloat64(0.0, value/alpha, 1.0) * 255.0))
+	}
+
+	return color.NRGBA{composited[0], composited[1], composited[2], uint8(math.Round(alpha * 255.0))}
+}
+
+// Blend the normalized backdrop and source channel values according to the given separable blending mode
+func blendChannel(backdrop, source float64, mode BlendingMode) float64 {
+	switch mode {
+	case LightenOnly:
+		return math.Max(backdrop, source)
+	case DarkenOnly:
+		return math.Min(backdrop, source)
+	case Multiply:
+		return backdrop * source
+	case Screen:
+		return backdrop + source - backdrop*source
+	case Overlay:
+		return blendChannel(source, backdrop, HardLight)
+	case HardLight:
+		if source <= 0.5 {
+			return blendChannel(backdrop, 2.0*source, Multiply)
+		}
+
+		return blendChannel(backdrop, 2.0*source-1.0, Screen)
+	case SoftLight:
+		if source <= 0.5 {
+			return backdrop - (1.0-2.0*source)*backdrop*(1.0-backdrop)
+		}
+
+		d := math.Sqrt(backdrop)
+		if backdrop <= 0.25 {
+			d = ((16.0*backdrop-12.0)*backdrop + 4.0) * backdrop
+		}
+
+		return backdrop + (2.0*source-1.0)*(d-backdrop)
+	case Difference:
+		return math.Abs(backdrop - source)
+	case Exclusion:
+		return backdrop + source - 2.0*backdrop*source
+	case ColorDodge:
+		if backdrop == 0.0 {
+			return 0.0
+		}
+
+		if source == 1.0 {
+			return 1.0
+		}
+
+		return math.Min(1.0, backdrop/(1.0-source))
+	case ColorBurn:
+		if backdrop == 1.0 {
+			return 1.0
+		}
+
+		if source == 0.0 {
+			return 0.0
+		}
+
+		return 1.0 - math.Min(1.0, (1.0-backdrop)/source)
+	default:
+		panic("color-utils: undefined blending mode provided")
+	}
+}
+
+// Calculate the luminosity of the normalized RGB components used by the non-separable blending modes
+func luminosity(c [3]float64) float64 {
+	return 0.3*c[0] + 0.59*c[1] + 0.11*c[2]
+}
+
+// Calculate the saturation of the normalized RGB components used by the non-separable blending modes
+func saturation(c [3]float64) float64 {
+	return Max3Float64(c[0], c[1], c[2]) - Min3Float64(c[0], c[1], c[2])
+}
+
+// Shift the normalized RGB components to the given luminosity and clip the components into the range from 0.0 to 1.0 preserving the luminosity
+func setLuminosity(c [3]float64, l float64) [3]float64 {
+	d := l - luminosity(c)
+	c = [3]float64{c[0] + d, c[1] + d, c[2] + d}
+
+	l = luminosity(c)
+	min := Min3Float64(c[0], c[1], c[2])
+	max := Max3Float64(c[0], c[1], c[2])
+
+	for channel := 0; channel < 3; channel += 1 {
+		if min < 0.0 {
+			c[channel] = l + (c[channel]-l)*l/(l-min)
+		}
+
+		if max > 1.0 {
+			c[channel] = l + (c[channel]-l)*(1.0-l)/(max-l)
+		}
+	}
+
+	return c
+}
+
+// Scale the normalized RGB components to the given saturation preserving the order of the components
+func setSaturation(c [3]float64, s float64) [3]float64 {
+	var (
+		minIndex int = 0
+		maxIndex int = 0
+		result   [3]float64
+	)
+
+	for channel := 1; channel < 3; channel += 1 {
+		if c[channel] < c[minIndex] {
+			minIndex = channel
+		}
+
+		if c[channel] >= c[maxIndex] {
+			maxIndex = channel
+		}
+	}
+
+	if c[maxIndex] == c[minIndex] {
+		return result
+	}
+
+	midIndex := 3 - minIndex - maxIndex
+	result[midIndex] = (c[midIndex] - c[minIndex]) * s / (c[maxIndex] - c[minIndex])
+	result[maxIndex] = s
+
+	return result
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlendNrgbaShouldBlendOpaqueColorsUsingSeparableModes(t *testing.T) {
+	a := color.NRGBA{51, 102, 204, 0xff}
+	b := color.NRGBA{153, 102, 51, 0xff}
+
+	cases := map[BlendingMode]color.NRGBA{
+		Multiply:   {31, 41, 41, 0xff},
+		Screen:     {173, 163, 214, 0xff},
+		Overlay:    {61, 82, 173, 0xff},
+		HardLight:  {92, 82, 82, 0xff},
+		SoftLight:  {64, 90, 180, 0xff},
+		Difference: {102, 0, 153, 0xff},
+		Exclusion:  {143, 122, 173, 0xff},
+		ColorDodge: {128, 170, 255, 0xff},
+		ColorBurn:  {0, 0, 0, 0xff},
+	}
+
+	for mode, expected := range cases {
+		actual := BlendNrgba(a, b, mode)
+
+		assert.InDelta(t, expected.R, actual.R, 1, "mode: %d", mode)
+		assert.InDelta(t, expected.G, actual.G, 1, "mode: %d", mode)
+		assert.InDelta(t, expected.B, actual.B, 1, "mode: %d", mode)
+		assert.Equal(t, expected.A, actual.A, "mode: %d", mode)
+	}
+}
+
+func TestBlendNrgbaShouldBlendOpaqueColorsUsingNonSeparableModes(t *testing.T) {
+	gray := color.NRGBA{128, 128, 128, 0xff}
+	red := color.NRGBA{255, 0, 0, 0xff}
+
+	assert.Equal(t, gray, BlendNrgba(gray, red, Hue))
+	assert.Equal(t, gray, BlendNrgba(gray, red, Saturation))
+	assert.Equal(t, color.NRGBA{77, 77, 77, 0xff}, BlendNrgba(red, gray, Saturation))
+	assert.Equal(t, red, BlendNrgba(red, red, Hue))
+
+	actual := BlendNrgba(gray, red, Color)
+	assert.Greater(t, actual.R, actual.G)
+	assert.Equal(t, actual.G, actual.B)
+	assert.InDelta(t, 128, 0.3*float64(actual.R)+0.59*float64(actual.G)+0.11*float64(actual.B), 1)
+
+	actual = BlendNrgba(red, gray, Luminosity)
+	assert.Greater(t, actual.R, actual.G)
+	assert.InDelta(t, 128, 0.3*float64(actual.R)+0.59*float64(actual.G)+0.11*float64(actual.B), 1)
+}
+
+func TestBlendNrgbaShouldCompositeTheAlphaChannel(t *testing.T) {
+	opaque := color.NRGBA{200, 100, 50, 0xff}
+	transparent := color.NRGBA{10, 20, 30, 0x00}
+
+	assert.Equal(t, opaque, BlendNrgba(transparent, opaque, Multiply))
+	assert.Equal(t, opaque, BlendNrgba(opaque, transparent, Multiply))
+	assert.Equal(t, color.NRGBA{0, 0, 0, 0}, BlendNrgba(transparent, transparent, LightenOnly))
+
+	actual := BlendNrgba(color.NRGBA{0, 0, 0, 0x80}, color.NRGBA{255, 255, 255, 0x80}, LightenOnly)
+	assert.InDelta(t, 0xc0, actual.A, 1)
+}
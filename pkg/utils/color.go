@@ -1,16 +1,33 @@
 package utils
 
 import (
+	"fmt"
 	"image/color"
 	"math"
+	"strconv"
+	"strings"
 )
 
-// Representation of a blending mode algorithm
+// Representation of a blending mode algorithm. The separable modes are blending each color channel independently, while the
+// non-separable modes (hue, saturation, color and luminosity) are blending the colors as a whole.
 type BlendingMode int
 
 const (
 	LightenOnly BlendingMode = iota
 	DarkenOnly
+	Multiply
+	Screen
+	Overlay
+	SoftLight
+	HardLight
+	Difference
+	Exclusion
+	ColorDodge
+	ColorBurn
+	Hue
+	Saturation
+	Color
+	Luminosity
 )
 
 // Convert the color.NRGBA color to the Y grayscale component represented as a integer in range from 0 to 255.
@@ -30,6 +47,35 @@ func RgbaToNormalizedComponents(c color.RGBA) (float64, float64, float64) {
 	return rNorm, gNorm, bNorm
 }
 
+// Convert the color.RGBA struct to individual linear RGB components represented as floating point numbers in range from 0.0 to 1.0
+func RgbaToLinearComponents(c color.RGBA) (float64, float64, float64) {
+	return linearComponentLuminanceLookup[c.R], linearComponentLuminanceLookup[c.G], linearComponentLuminanceLookup[c.B]
+}
+
+// Convert a color.RGBA color to the Oklab color space components (L, a, b), where the lightness is expressed in range from 0.0 to 1.0.
+// Note that the alpha channel is not taken under account. https://bottosson.github.io/posts/oklab/
+func RgbaToOklab(c color.RGBA) (float64, float64, float64) {
+	rLinear, gLinear, bLinear := RgbaToLinearComponents(c)
+
+	l := math.Cbrt(0.4122214708*rLinear + 0.5363325363*gLinear + 0.0514459929*bLinear)
+	m := math.Cbrt(0.2119034982*rLinear + 0.6806995451*gLinear + 0.1073969566*bLinear)
+	s := math.Cbrt(0.0883024619*rLinear + 0.2817188376*gLinear + 0.6299787005*bLinear)
+
+	okL := 0.2104542553*l + 0.7936177850*m - 0.0040720468*s
+	okA := 1.9779984951*l - 2.4285922050*m + 0.4505937099*s
+	okB := 0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+
+	return okL, okA, okB
+}
+
+// Calculate the euclidean distance between two color.RGBA colors in the Oklab color space
+func OklabDistance(a, b color.RGBA) float64 {
+	aL, aA, aB := RgbaToOklab(a)
+	bL, bA, bB := RgbaToOklab(b)
+
+	return math.Sqrt((aL-bL)*(aL-bL) + (aA-bA)*(aA-bA) + (aB-bB)*(aB-bB))
+}
+
 // Convert a color.RGBA color to HSL+Alpha components where Hue is expressed in degress (0-360) and the saturation, lightnes and alpha\
 // in percentage (0.0-1.0)
 func RgbaToHsla(c color.RGBA) (int, float64, float64, float64) {
@@ -75,31 +121,6 @@ func RgbaToHsla(c color.RGBA) (int, float64, float64, float64) {
 	return hue, saturation, lightness, alpha
 }
 
-// Perform blending of two color.NRGBA colors according to a given blending mode
-// TODO: Currently the alpha channel of the output color has a fixed 0xff value
-func BlendNrgba(a, b color.NRGBA, mode BlendingMode) color.NRGBA {
-	switch mode {
-	case LightenOnly:
-		{
-			r := Max2Uint8(a.R, b.R)
-			g := Max2Uint8(a.G, b.G)
-			b := Max2Uint8(a.B, b.B)
-
-			return color.NRGBA{r, g, b, 0xff}
-		}
-	case DarkenOnly:
-		{
-			r := Min2Uint8(a.R, b.R)
-			g := Min2Uint8(a.G, b.G)
-			b := Min2Uint8(a.B, b.B)
-
-			return color.NRGBA{r, g, b, 0xff}
-		}
-	default:
-		panic("color-utils: undefined blending mode provided")
-	}
-}
-
 // Performa a linear interpolation between two color.RGBA colors and return the interpolated color for the given t point
 func InterpolateRgba(a, b color.RGBA, t float64) color.RGBA {
 	rLerp := Lerp(float64(a.R), float64(b.R), t)
@@ -114,3 +135,126 @@ func InterpolateRgba(a, b color.RGBA, t float64) color.RGBA {
 		A: uint8(aLerp),
 	}
 }
+
+// Convert the linear RGB components represented as floating point numbers in range from 0.0 to 1.0 and the alpha component to a
+// color.RGBA color. The components out of range are clamped.
+func LinearComponentsToRgba(rLinear, gLinear, bLinear float64, alpha uint8) color.RGBA {
+	return color.RGBA{
+		R: linearComponentToSrgbComponent(rLinear),
+		G: linearComponentToSrgbComponent(gLinear),
+		B: linearComponentToSrgbComponent(bLinear),
+		A: alpha,
+	}
+}
+
+// Convert the linear RGB component to the gamma-encoded sRGB component represented as a integer in range from 0 to 255
+func linearComponentToSrgbComponent(component float64) uint8 {
+	component = ClampFloat64(0.0, component, 1.0)
+
+	if component <= 0.0031308 {
+		component = component * 12.92
+	} else {
+		component = 1.055*math.Pow(component, 1.0/2.4) - 0.055
+	}
+
+	return uint8(ClampInt(0, int(math.Round(component*255.0)), 255))
+}
+
+// Convert the Oklab color space components (L, a, b) and the alpha component to a color.RGBA color. The colors out of the sRGB
+// gamut are clamped. https://bottosson.github.io/posts/oklab/
+func OklabToRgba(okL, okA, okB float64, alpha uint8) color.RGBA {
+	l := okL + 0.3963377774*okA + 0.2158037573*okB
+	m := okL - 0.1055613458*okA - 0.0638541728*okB
+	s := okL - 0.0894841775*okA - 1.2914855480*okB
+
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	rLinear := +4.0767416621*l - 3.3077115913*m + 0.2309699292*s
+	gLinear := -1.2684380046*l + 2.6097574011*m - 0.3413193965*s
+	bLinear := -0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+
+	return LinearComponentsToRgba(rLinear, gLinear, bLinear, alpha)
+}
+
+// Convert the HSL+Alpha components where Hue is expressed in degrees (0-360) and the saturation, lightness and alpha in percentage
+// (0.0-1.0) to a color.RGBA color
+func HslaToRgba(h, s, l, a float64) color.RGBA {
+	h = math.Mod(h, 360.0)
+	if h < 0.0 {
+		h += 360.0
+	}
+
+	chroma := (1.0 - math.Abs(2.0*l-1.0)) * s
+	x := chroma * (1.0 - math.Abs(math.Mod(h/60.0, 2.0)-1.0))
+	m := l - chroma/2.0
+
+	var r, g, b float64
+	switch {
+	case h < 60.0:
+		r, g, b = chroma, x, 0
+	case h < 120.0:
+		r, g, b = x, chroma, 0
+	case h < 180.0:
+		r, g, b = 0, chroma, x
+	case h < 240.0:
+		r, g, b = 0, x, chroma
+	case h < 300.0:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	return color.RGBA{
+		R: uint8(ClampInt(0, int(math.Round((r+m)*255.0)), 255)),
+		G: uint8(ClampInt(0, int(math.Round((g+m)*255.0)), 255)),
+		B: uint8(ClampInt(0, int(math.Round((b+m)*255.0)), 255)),
+		A: uint8(ClampInt(0, int(math.Round(a*255.0)), 255)),
+	}
+}
+
+// Perform a linear interpolation between two color.RGBA colors in the linear RGB color space and return the interpolated color for the given t point
+func InterpolateRgbaLinear(a, b color.RGBA, t float64) color.RGBA {
+	aR, aG, aB := RgbaToLinearComponents(a)
+	bR, bG, bB := RgbaToLinearComponents(b)
+
+	return LinearComponentsToRgba(Lerp(aR, bR, t), Lerp(aG, bG, t), Lerp(aB, bB, t), uint8(Lerp(float64(a.A), float64(b.A), t)))
+}
+
+// Perform a linear interpolation between two color.RGBA colors in the Oklab color space and return the interpolated color for the given t point
+func InterpolateRgbaOklab(a, b color.RGBA, t float64) color.RGBA {
+	aL, aA, aB := RgbaToOklab(a)
+	bL, bA, bB := RgbaToOklab(b)
+
+	return OklabToRgba(Lerp(aL, bL, t), Lerp(aA, bA, t), Lerp(aB, bB, t), uint8(Lerp(float64(a.A), float64(b.A), t)))
+}
+
+// Perform a linear interpolation between two color.RGBA colors in the HSL color space and return the interpolated color for the given t point.
+// The hue is interpolated along the shortest path around the hue circle.
+func InterpolateRgbaHsl(a, b color.RGBA, t float64) color.RGBA {
+	aH, aS, aL, aA := RgbaToHsla(a)
+	bH, bS, bL, bA := RgbaToHsla(b)
+
+	hueDelta := float64(bH - aH)
+	if hueDelta > 180.0 {
+		hueDelta -= 360.0
+	} else if hueDelta < -180.0 {
+		hueDelta += 360.0
+	}
+
+	return HslaToRgba(float64(aH)+hueDelta*t, Lerp(aS, bS, t), Lerp(aL, bL, t), Lerp(aA, bA, t))
+}
+
+// Parse the color in the hexadecimal RRGGBB format. The color can be prefixed with a hash.
+func ParseHexColor(text string) (color.RGBA, error) {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "#")
+	if len(text) != 6 {
+		return color.RGBA{}, fmt.Errorf("utils: invalid hex color specified (%s)", text)
+	}
+
+	value, err := strconv.ParseUint(text, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("utils: invalid hex color specified (%s): %w", text, err)
+	}
+
+	return color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff}, nil
+}
@@ -146,3 +146,134 @@ func TestInterpolateRgbaShouldCorrectlyInterpolateColors(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	}
 }
+
+func TestRgbaToOklabShouldConvert(t *testing.T) {
+	cases := map[color.RGBA]struct{ l, a, b float64 }{
+		{0, 0, 0, 255}:       {0.0, 0.0, 0.0},
+		{255, 255, 255, 255}: {1.0, 0.0, 0.0},
+		{255, 0, 0, 255}:     {0.627955, 0.224863, 0.125846},
+		{0, 0, 255, 255}:     {0.452014, -0.032457, -0.311528},
+	}
+
+	const delta float64 = 1e-3
+
+	for rgba, expected := range cases {
+		lActual, aActual, bActual := RgbaToOklab(rgba)
+
+		assert.InDelta(t, expected.l, lActual, delta)
+		assert.InDelta(t, expected.a, aActual, delta)
+		assert.InDelta(t, expected.b, bActual, delta)
+	}
+}
+
+func TestOklabDistanceShouldCalculateDistance(t *testing.T) {
+	black := color.RGBA{0, 0, 0, 255}
+	white := color.RGBA{255, 255, 255, 255}
+
+	assert.InDelta(t, 0.0, OklabDistance(black, black), 1e-7)
+	assert.InDelta(t, 1.0, OklabDistance(black, white), 1e-3)
+	assert.InDelta(t, OklabDistance(black, white), OklabDistance(white, black), 1e-7)
+}
+
+func TestOklabToRgbaShouldConvertBackAndForth(t *testing.T) {
+	cases := []color.RGBA{
+		{0, 0, 0, 255},
+		{255, 255, 255, 255},
+		{255, 0, 0, 255},
+		{0, 0, 255, 255},
+		{12, 200, 99, 128},
+	}
+
+	for _, expected := range cases {
+		l, a, b := RgbaToOklab(expected)
+		actual := OklabToRgba(l, a, b, expected.A)
+
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestLinearComponentsToRgbaShouldConvertBackAndForth(t *testing.T) {
+	for component := 0; component <= 255; component += 1 {
+		expected := color.RGBA{uint8(component), uint8(255 - component), uint8(component / 2), 255}
+
+		r, g, b := RgbaToLinearComponents(expected)
+		actual := LinearComponentsToRgba(r, g, b, expected.A)
+
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestHslaToRgbaShouldConvertBackAndForth(t *testing.T) {
+	cases := []color.RGBA{
+		{0, 0, 0, 255},
+		{255, 255, 255, 255},
+		{255, 0, 0, 255},
+		{0, 255, 0, 255},
+		{0, 0, 255, 255},
+		{255, 0, 255, 255},
+	}
+
+	for _, expected := range cases {
+		h, s, l, a := RgbaToHsla(expected)
+		actual := HslaToRgba(float64(h), s, l, a)
+
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestInterpolateRgbaInColorSpacesShouldPreserveEndpoints(t *testing.T) {
+	a := color.RGBA{255, 0, 0, 255}
+	b := color.RGBA{0, 0, 255, 255}
+
+	interpolations := []func(a, b color.RGBA, t float64) color.RGBA{
+		InterpolateRgbaLinear,
+		InterpolateRgbaOklab,
+		InterpolateRgbaHsl,
+	}
+
+	for _, interpolate := range interpolations {
+		assert.Equal(t, a, interpolate(a, b, 0.0))
+		assert.Equal(t, b, interpolate(a, b, 1.0))
+	}
+}
+
+func TestInterpolateRgbaLinearShouldInterpolateInLinearSpace(t *testing.T) {
+	actual := InterpolateRgbaLinear(color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}, 0.5)
+
+	assert.Equal(t, color.RGBA{188, 188, 188, 255}, actual)
+}
+
+func TestInterpolateRgbaHslShouldUseTheShortestHuePath(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	magenta := color.RGBA{255, 0, 255, 255}
+	yellow := color.RGBA{255, 255, 0, 255}
+
+	assert.Equal(t, color.RGBA{255, 0, 128, 255}, InterpolateRgbaHsl(red, magenta, 0.5))
+	assert.Equal(t, color.RGBA{255, 0, 128, 255}, InterpolateRgbaHsl(magenta, red, 0.5))
+	assert.Equal(t, color.RGBA{255, 128, 0, 255}, InterpolateRgbaHsl(red, yellow, 0.5))
+}
+
+func TestParseHexColorShouldParseValidColors(t *testing.T) {
+	cases := map[string]color.RGBA{
+		"#00ff00":  {0, 255, 0, 255},
+		"ff8040":   {255, 128, 64, 255},
+		" #0A0b0C": {10, 11, 12, 255},
+	}
+
+	for text, expected := range cases {
+		actual, err := ParseHexColor(text)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestParseHexColorShouldReturnErrorForInvalidColors(t *testing.T) {
+	cases := []string{"", "#fff", "#gg0000", "#00ff00ff"}
+
+	for _, text := range cases {
+		_, err := ParseHexColor(text)
+
+		assert.NotNil(t, err)
+	}
+}
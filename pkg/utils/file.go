@@ -36,6 +36,32 @@ func GetImageFromFile(filePath string) (image.Image, error) {
 	return img, nil
 }
 
+// Get the image config (color model and dimensions) from a file specified by the given path without decoding the whole image
+func GetImageConfigFromFile(filePath string) (image.Config, error) {
+	filePath, err := EscapePathQuotes(filePath)
+	if err != nil {
+		return image.Config{}, fmt.Errorf("utils: failed to escape the specified image path: %w", err)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return image.Config{}, fmt.Errorf("utils: can not open the specified file: %w", err)
+	}
+
+	defer func() {
+		if err := file.Close(); err != nil {
+			panic(err)
+		}
+	}()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return image.Config{}, fmt.Errorf("utils: failed to decode the specified image config: %w", err)
+	}
+
+	return config, nil
+}
+
 // Remove the quotes surrounding the path. The operation will fail for more than 10 iterations.
 func EscapePathQuotes(path string) (string, error) {
 	const maxIterations int = 10
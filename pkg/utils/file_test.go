@@ -113,6 +113,23 @@ func TestImageShouldBeRetrievied(t *testing.T) {
 	clearEnvironmentFromTestFiles()
 }
 
+func TestImageConfigShouldBeRetrievied(t *testing.T) {
+	clearEnvironmentFromTestFiles()
+
+	expectedImage := mockTestBlackImage()
+
+	err := StoreImageToFile(test_file_name_png, "png", expectedImage)
+	assert.Nil(t, err)
+
+	actualConfig, err := GetImageConfigFromFile(test_file_name_png)
+	assert.Nil(t, err)
+
+	assert.Equal(t, expectedImage.Bounds().Dx(), actualConfig.Width)
+	assert.Equal(t, expectedImage.Bounds().Dy(), actualConfig.Height)
+
+	clearEnvironmentFromTestFiles()
+}
+
 func TestEscapedPathQuotesShouldCorrectlyRemoveSurroundingQuotes(t *testing.T) {
 	cases := map[string]struct {
 		path string
@@ -206,6 +206,71 @@ func BlendImagesNrgba(a, b *image.NRGBA, mode BlendingMode) (*image.NRGBA, error
 	return img, nil
 }
 
+// Mix two NRGBA images into a new image by linearly interpolating the components of the first image toward the components of the
+// second image according to the given opacity of the second image in range from 0.0 to 1.0.
+func MixImagesNrgba(a, b *image.NRGBA, opacity float64) (*image.NRGBA, error) {
+	if a == nil || b == nil {
+		panic("image-utils: can not perform mixing if one of the images is nil")
+	}
+
+	if opacity < 0.0 || opacity > 1.0 {
+		return nil, errors.New("image-utils: the provided opacity is out of range")
+	}
+
+	if a.Bounds().Dx() != b.Bounds().Dx() || a.Bounds().Dy() != b.Bounds().Dy() {
+		return nil, errors.New("image-utils: the provided images have different size")
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, a.Bounds().Dx(), a.Bounds().Dy()))
+	pimit.ParallelNrgbaReadWrite(img, func(x, y int, _, _, _, _ uint8) (uint8, uint8, uint8, uint8) {
+		aColor := a.NRGBAAt(a.Bounds().Min.X+x, a.Bounds().Min.Y+y)
+		bColor := b.NRGBAAt(b.Bounds().Min.X+x, b.Bounds().Min.Y+y)
+
+		return uint8(math.Round(Lerp(float64(aColor.R), float64(bColor.R), opacity))),
+			uint8(math.Round(Lerp(float64(aColor.G), float64(bColor.G), opacity))),
+			uint8(math.Round(Lerp(float64(aColor.B), float64(bColor.B), opacity))),
+			uint8(math.Round(Lerp(float64(aColor.A), float64(bColor.A), opacity)))
+	})
+
+	return img, nil
+}
+
+// Mix two NRGBA images into a new image by linearly interpolating the components of the first image toward the components of the
+// second image according to the given opacity multiplied by the per-pixel opacity represented by the grayscale value of the opacity map.
+func MixImagesNrgbaByMap(a, b, opacityMap *image.NRGBA, opacity float64) (*image.NRGBA, error) {
+	if a == nil || b == nil || opacityMap == nil {
+		panic("image-utils: can not perform mixing if one of the images is nil")
+	}
+
+	if opacity < 0.0 || opacity > 1.0 {
+		return nil, errors.New("image-utils: the provided opacity is out of range")
+	}
+
+	if a.Bounds().Dx() != b.Bounds().Dx() || a.Bounds().Dy() != b.Bounds().Dy() {
+		return nil, errors.New("image-utils: the provided images have different size")
+	}
+
+	if a.Bounds().Dx() != opacityMap.Bounds().Dx() || a.Bounds().Dy() != opacityMap.Bounds().Dy() {
+		return nil, errors.New("image-utils: the provided opacity map has a different size")
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, a.Bounds().Dx(), a.Bounds().Dy()))
+	pimit.ParallelNrgbaReadWrite(img, func(x, y int, _, _, _, _ uint8) (uint8, uint8, uint8, uint8) {
+		aColor := a.NRGBAAt(a.Bounds().Min.X+x, a.Bounds().Min.Y+y)
+		bColor := b.NRGBAAt(b.Bounds().Min.X+x, b.Bounds().Min.Y+y)
+		mapColor := opacityMap.NRGBAAt(opacityMap.Bounds().Min.X+x, opacityMap.Bounds().Min.Y+y)
+
+		t := opacity * float64(NrgbaToGrayscaleComponent(mapColor)) / 255.0
+
+		return uint8(math.Round(Lerp(float64(aColor.R), float64(bColor.R), t))),
+			uint8(math.Round(Lerp(float64(aColor.G), float64(bColor.G), t))),
+			uint8(math.Round(Lerp(float64(aColor.B), float64(bColor.B), t))),
+			uint8(math.Round(Lerp(float64(aColor.A), float64(bColor.A), t)))
+	})
+
+	return img, nil
+}
+
 // Create a RGBA color-space copy of an image represented in the NRGBA color-space.
 func NrgbaToRgbaImage(i *image.NRGBA) *image.RGBA {
 	width := i.Bounds().Dx()
@@ -516,3 +516,97 @@ func mockTestGradientImageNrgba() *image.NRGBA {
 
 	return image
 }
+
+func TestMixImagesNrgbaShouldMixImagesAccordingToOpacity(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	rect := image.Rect(0, 0, 2, 1)
+
+	a := image.NewNRGBA(rect)
+	a.SetNRGBA(0, 0, color.NRGBA{0, 100, 200, 255})
+	a.SetNRGBA(1, 0, color.NRGBA{10, 20, 30, 255})
+
+	b := image.NewNRGBA(rect)
+	b.SetNRGBA(0, 0, color.NRGBA{200, 100, 0, 255})
+	b.SetNRGBA(1, 0, color.NRGBA{30, 20, 10, 255})
+
+	cases := map[float64][2]color.NRGBA{
+		0.0:  {{0, 100, 200, 255}, {10, 20, 30, 255}},
+		0.25: {{50, 100, 150, 255}, {15, 20, 25, 255}},
+		1.0:  {{200, 100, 0, 255}, {30, 20, 10, 255}},
+	}
+
+	for opacity, expected := range cases {
+		actual, err := MixImagesNrgba(a, b, opacity)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected[0], actual.NRGBAAt(0, 0))
+		assert.Equal(t, expected[1], actual.NRGBAAt(1, 0))
+	}
+}
+
+func TestMixImagesNrgbaShouldNotMixInvalidParameters(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	a := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	b := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+
+	actual, err := MixImagesNrgba(a, b, 0.5)
+	assert.Nil(t, actual)
+	assert.NotNil(t, err)
+
+	actual, err = MixImagesNrgba(a, a, 1.5)
+	assert.Nil(t, actual)
+	assert.NotNil(t, err)
+
+	assert.Panics(t, func() {
+		MixImagesNrgba(nil, a, 0.5)
+	})
+}
+
+func TestMixImagesNrgbaByMapShouldMixImagesAccordingToOpacityMap(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	rect := image.Rect(0, 0, 2, 1)
+
+	a := image.NewNRGBA(rect)
+	a.SetNRGBA(0, 0, color.NRGBA{0, 100, 200, 255})
+	a.SetNRGBA(1, 0, color.NRGBA{10, 20, 30, 255})
+
+	b := image.NewNRGBA(rect)
+	b.SetNRGBA(0, 0, color.NRGBA{200, 100, 0, 255})
+	b.SetNRGBA(1, 0, color.NRGBA{30, 20, 10, 255})
+
+	opacityMap := image.NewNRGBA(rect)
+	opacityMap.SetNRGBA(0, 0, color.NRGBA{255, 255, 255, 255})
+	opacityMap.SetNRGBA(1, 0, color.NRGBA{0, 0, 0, 255})
+
+	actual, err := MixImagesNrgbaByMap(a, b, opacityMap, 0.5)
+
+	assert.Nil(t, err)
+	assert.Equal(t, color.NRGBA{100, 100, 100, 255}, actual.NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{10, 20, 30, 255}, actual.NRGBAAt(1, 0))
+}
+
+func TestMixImagesNrgbaByMapShouldNotMixInvalidParameters(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	a := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	b := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+
+	actual, err := MixImagesNrgbaByMap(a, a, b, 0.5)
+	assert.Nil(t, actual)
+	assert.NotNil(t, err)
+
+	actual, err = MixImagesNrgbaByMap(a, b, a, 0.5)
+	assert.Nil(t, actual)
+	assert.NotNil(t, err)
+
+	actual, err = MixImagesNrgbaByMap(a, a, a, -0.5)
+	assert.Nil(t, actual)
+	assert.NotNil(t, err)
+
+	assert.Panics(t, func() {
+		MixImagesNrgbaByMap(a, a, nil, 0.5)
+	})
+}
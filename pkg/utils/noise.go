@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Seeded two-dimensional gradient noise generator based on the improved Perlin noise algorithm.
+// https://mrl.cs.nyu.edu/~perlin/noise/
+type PerlinNoise struct {
+	permutation [512]int
+}
+
+// Create a new Perlin noise generator instance. Generators created with the same seed are producing the same noise.
+func CreatePerlinNoise(seed int64) *PerlinNoise {
+	noise := new(PerlinNoise)
+
+	random := rand.New(rand.NewSource(seed))
+	for index, value := range random.Perm(256) {
+		noise.permutation[index] = value
+		noise.permutation[index+256] = value
+	}
+
+	return noise
+}
+
+// Calculate the noise value at the given point. The value is in range from -1.0 to 1.0.
+func (noise *PerlinNoise) Noise(x, y float64) float64 {
+	xFloor := math.Floor(x)
+	yFloor := math.Floor(y)
+
+	xi := int(xFloor) & 255
+	yi := int(yFloor) & 255
+
+	xf := x - xFloor
+	yf := y - yFloor
+
+	u := perlinFade(xf)
+	v := perlinFade(yf)
+
+	p := &noise.permutation
+	aa := p[p[xi]+yi]
+	ab := p[p[xi]+yi+1]
+	ba := p[p[xi+1]+yi]
+	bb := p[p[xi+1]+yi+1]
+
+	x1 := perlinLerp(perlinGradient(aa, xf, yf), perlinGradient(ba, xf-1, yf), u)
+	x2 := perlinLerp(perlinGradient(ab, xf, yf-1), perlinGradient(bb, xf-1, yf-1), u)
+
+	return ClampFloat64(-1.0, perlinLerp(x1, x2, v), 1.0)
+}
+
+// Calculate the fractal noise value at the given point by summing the given number of noise octaves. Each octave has a doubled frequency
+// and the amplitude multiplied by the persistence. The value is normalized to the range from 0.0 to 1.0.
+func (noise *PerlinNoise) FractalNoise(x, y float64, octaves int, persistence float64) float64 {
+	var (
+		sum       float64 = 0.0
+		amplitude float64 = 1.0
+		total     float64 = 0.0
+		frequency float64 = 1.0
+	)
+
+	for octave := 0; octave < octaves; octave += 1 {
+		sum += noise.Noise(x*frequency, y*frequency) * amplitude
+		total += amplitude
+
+		amplitude *= persistence
+		frequency *= 2.0
+	}
+
+	if total == 0.0 {
+		return 0.5
+	}
+
+	return ClampFloat64(0.0, (sum/total+1.0)/2.0, 1.0)
+}
+
+func perlinFade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func perlinLerp(a, b, t float64) float64 {
+	return a + t*(b-a)
+}
+
+func perlinGradient(hash int, x, y float64) float64 {
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPerlinNoiseShouldBeDeterministicForTheSameSeed(t *testing.T) {
+	a := CreatePerlinNoise(42)
+	b := CreatePerlinNoise(42)
+
+	for x := 0.0; x < 10.0; x += 0.37 {
+		for y := 0.0; y < 10.0; y += 0.53 {
+			assert.Equal(t, a.Noise(x, y), b.Noise(x, y))
+			assert.Equal(t, a.FractalNoise(x, y, 4, 0.5), b.FractalNoise(x, y, 4, 0.5))
+		}
+	}
+}
+
+func TestPerlinNoiseShouldDifferForDifferentSeeds(t *testing.T) {
+	a := CreatePerlinNoise(1)
+	b := CreatePerlinNoise(2)
+
+	different := false
+	for x := 0.0; x < 10.0; x += 0.37 {
+		if a.Noise(x, 0.5) != b.Noise(x, 0.5) {
+			different = true
+		}
+	}
+
+	assert.True(t, different)
+}
+
+func TestPerlinNoiseShouldReturnValuesInCorrectRange(t *testing.T) {
+	noise := CreatePerlinNoise(7)
+
+	for x := -20.0; x < 20.0; x += 0.29 {
+		for y := -20.0; y < 20.0; y += 0.31 {
+			value := noise.Noise(x, y)
+			assert.GreaterOrEqual(t, value, -1.0)
+			assert.LessOrEqual(t, value, 1.0)
+
+			fractal := noise.FractalNoise(x, y, 5, 0.6)
+			assert.GreaterOrEqual(t, fractal, 0.0)
+			assert.LessOrEqual(t, fractal, 1.0)
+		}
+	}
+}
+
+func TestPerlinNoiseShouldBeZeroAtLatticePoints(t *testing.T) {
+	noise := CreatePerlinNoise(3)
+
+	for x := 0; x < 10; x += 1 {
+		for y := 0; y < 10; y += 1 {
+			assert.InDelta(t, 0.0, noise.Noise(float64(x), float64(y)), 1e-9)
+		}
+	}
+}
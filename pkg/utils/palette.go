@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	paletteMaxSize = 256
+)
+
+// Get the color palette from a file specified by the given path. The supported formats are the GIMP palette (.gpl) and the
+// list of hexadecimal colors (.hex) with a single color per line.
+func GetPaletteFromFile(filePath string) (color.Palette, error) {
+	filePath, err := EscapePathQuotes(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("utils: failed to escape the specified palette path: %w", err)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("utils: can not open the specified file: %w", err)
+	}
+
+	defer func() {
+		if err := file.Close(); err != nil {
+			panic(err)
+		}
+	}()
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".gpl":
+		return ParseGplPalette(file)
+	case ".hex":
+		return ParseHexPalette(file)
+	default:
+		return nil, fmt.Errorf("utils: unsupported palette file format specified (%s)", filePath)
+	}
+}
+
+// Parse the color palette in the GIMP palette (.gpl) format. The header, the name and columns properties and the comments are skipped.
+func ParseGplPalette(reader io.Reader) (color.Palette, error) {
+	var (
+		scanner *bufio.Scanner = bufio.NewScanner(reader)
+		palette color.Palette  = make(color.Palette, 0)
+		line    int            = 0
+	)
+
+	for scanner.Scan() {
+		line += 1
+		text := strings.TrimSpace(scanner.Text())
+
+		if line == 1 {
+			if text != "GIMP Palette" {
+				return nil, errors.New("utils: the gpl palette is missing the GIMP Palette header")
+			}
+
+			continue
+		}
+
+		if len(text) == 0 || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "Name:") || strings.HasPrefix(text, "Columns:") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("utils: invalid gpl palette color entry at line %d", line)
+		}
+
+		var components [3]uint8
+		for index := range components {
+			value, err := strconv.ParseUint(fields[index], 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("utils: invalid gpl palette color component at line %d: %w", line, err)
+			}
+
+			components[index] = uint8(value)
+		}
+
+		palette = append(palette, color.RGBA{components[0], components[1], components[2], 0xff})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("utils: failed to read the gpl palette: %w", err)
+	}
+
+	return validatePalette(palette)
+}
+
+// Parse the color palette in the hexadecimal (.hex) format with a single RRGGBB color per line. The colors can be prefixed with a hash.
+func ParseHexPalette(reader io.Reader) (color.Palette, error) {
+	var (
+		scanner *bufio.Scanner = bufio.NewScanner(reader)
+		palette color.Palette  = make(color.Palette, 0)
+		line    int            = 0
+	)
+
+	for scanner.Scan() {
+		line += 1
+		text := strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "#")
+
+		if len(text) == 0 {
+			continue
+		}
+
+		if len(text) != 6 {
+			return nil, fmt.Errorf("utils: invalid hex palette color entry at line %d", line)
+		}
+
+		value, err := strconv.ParseUint(text, 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("utils: invalid hex palette color entry at line %d: %w", line, err)
+		}
+
+		palette = append(palette, color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("utils: failed to read the hex palette: %w", err)
+	}
+
+	return validatePalette(palette)
+}
+
+// Create the color palette of the given size representing the colors of the given image using the median cut algorithm. The
+// transparent pixels are not taken under account. The palette can contain fewer colors if the image has fewer distinct colors.
+func CreateMedianCutPalette(img image.Image, size int) (color.Palette, error) {
+	if img == nil {
+		return nil, errors.New("utils: can not create the median cut palette for the provided nil image")
+	}
+
+	if size < 1 || size > paletteMaxSize {
+		return nil, fmt.Errorf("utils: the median cut palette size must be in range from 1 to %d", paletteMaxSize)
+	}
+
+	nrgba := ImageToNrgbaImage(img)
+	pixels := make([][3]uint8, 0, nrgba.Bounds().Dx()*nrgba.Bounds().Dy())
+
+	for index := 0; index < len(nrgba.Pix); index += 4 {
+		if nrgba.Pix[index+3] == 0 {
+			continue
+		}
+
+		pixels = append(pixels, [3]uint8{nrgba.Pix[index+0], nrgba.Pix[index+1], nrgba.Pix[index+2]})
+	}
+
+	if len(pixels) == 0 {
+		return nil, errors.New("utils: can not create the median cut palette for a fully transparent image")
+	}
+
+	boxes := [][][3]uint8{pixels}
+	for len(boxes) < size {
+		// NOTE: The box with the widest channel range is split at the median of the channel
+		selected, selectedChannel, selectedRange := -1, 0, 0
+		for index, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+
+			channel, channelRange := calculateWidestChannel(box)
+			if channelRange > selectedRange {
+				selected, selectedChannel, selectedRange = index, channel, channelRange
+			}
+		}
+
+		if selected == -1 {
+			break
+		}
+
+		box := boxes[selected]
+		sort.Slice(box, func(i, j int) bool {
+			return box[i][selectedChannel] < box[j][selectedChannel]
+		})
+
+		median := len(box) / 2
+		boxes[selected] = box[:median]
+		boxes = append(boxes, box[median:])
+	}
+
+	palette := make(color.Palette, 0, len(boxes))
+	for _, box := range boxes {
+		sumR, sumG, sumB := 0, 0, 0
+		for _, pixel := range box {
+			sumR += int(pixel[0])
+			sumG += int(pixel[1])
+			sumB += int(pixel[2])
+		}
+
+		palette = append(palette, color.RGBA{
+			R: uint8(sumR / len(box)),
+			G: uint8(sumG / len(box)),
+			B: uint8(sumB / len(box)),
+			A: 0xff,
+		})
+	}
+
+	return palette, nil
+}
+
+// Function used to find the RGB channel with the widest range of values in the given pixels and the range of the channel
+func calculateWidestChannel(pixels [][3]uint8) (int, int) {
+	min, max := pixels[0], pixels[0]
+	for _, pixel := range pixels {
+		for channel := range pixel {
+			min[channel] = Min2Uint8(min[channel], pixel[channel])
+			max[channel] = Max2Uint8(max[channel], pixel[channel])
+		}
+	}
+
+	widest := 0
+	for channel := range min {
+		if max[channel]-min[channel] > max[widest]-min[widest] {
+			widest = channel
+		}
+	}
+
+	return widest, int(max[widest] - min[widest])
+}
+
+// Function used to check if the parsed palette size is in the supported range
+func validatePalette(palette color.Palette) (color.Palette, error) {
+	if len(palette) == 0 {
+		return nil, errors.New("utils: the palette does not contain any colors")
+	}
+
+	if len(palette) > paletteMaxSize {
+		return nil, fmt.Errorf("utils: the palette can not contain more than %d colors", paletteMaxSize)
+	}
+
+	return palette, nil
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	test_file_name_gpl string = "test-file-utility-palette.gpl"
+)
+
+func TestParseGplPaletteShouldParsePalette(t *testing.T) {
+	text := "GIMP Palette\nName: Test\nColumns: 2\n# Comment\n  0   0   0\tBlack\n255 128 64 Orange\n\n"
+
+	palette, err := ParseGplPalette(strings.NewReader(text))
+
+	assert.Nil(t, err)
+	assert.Equal(t, color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 128, 64, 255}}, palette)
+}
+
+func TestParseGplPaletteShouldNotParseInvalidPalette(t *testing.T) {
+	cases := []string{
+		"",
+		"0 0 0\n",
+		"GIMP Palette\n",
+		"GIMP Palette\n0 0\n",
+		"GIMP Palette\n0 0 256\n",
+		"GIMP Palette\n0 x 0\n",
+	}
+
+	for _, text := range cases {
+		palette, err := ParseGplPalette(strings.NewReader(text))
+
+		assert.Nil(t, palette)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestParseHexPaletteShouldParsePalette(t *testing.T) {
+	text := "#000000\nff8040\n\n  #FFFFFF  \n"
+
+	palette, err := ParseHexPalette(strings.NewReader(text))
+
+	assert.Nil(t, err)
+	assert.Equal(t, color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 128, 64, 255}, color.RGBA{255, 255, 255, 255}}, palette)
+}
+
+func TestParseHexPaletteShouldNotParseInvalidPalette(t *testing.T) {
+	cases := []string{
+		"",
+		"#fff\n",
+		"#gggggg\n",
+		"#0000000\n",
+	}
+
+	for _, text := range cases {
+		palette, err := ParseHexPalette(strings.NewReader(text))
+
+		assert.Nil(t, palette)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestGetPaletteFromFileShouldReadPalette(t *testing.T) {
+	defer func() {
+		_ = os.Remove(test_file_name_gpl)
+	}()
+
+	err := os.WriteFile(test_file_name_gpl, []byte("GIMP Palette\n10 20 30 Color\n"), 0644)
+	assert.Nil(t, err)
+
+	palette, err := GetPaletteFromFile(test_file_name_gpl)
+
+	assert.Nil(t, err)
+	assert.Equal(t, color.Palette{color.RGBA{10, 20, 30, 255}}, palette)
+}
+
+func TestGetPaletteFromFileShouldNotReadUnsupportedFormat(t *testing.T) {
+	palette, err := GetPaletteFromFile("palette.txt")
+
+	assert.Nil(t, palette)
+	assert.NotNil(t, err)
+}
+
+func TestCreateMedianCutPaletteShouldCreatePalette(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{0, 0, 0, 255})
+	img.SetNRGBA(1, 0, color.NRGBA{10, 10, 10, 255})
+	img.SetNRGBA(2, 0, color.NRGBA{240, 240, 240, 255})
+	img.SetNRGBA(3, 0, color.NRGBA{250, 250, 250, 255})
+
+	palette, err := CreateMedianCutPalette(img, 2)
+
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, color.Palette{color.RGBA{5, 5, 5, 255}, color.RGBA{245, 245, 245, 255}}, palette)
+}
+
+func TestCreateMedianCutPaletteShouldNotExceedDistinctColors(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	for index := range img.Pix {
+		img.Pix[index] = 255
+	}
+
+	palette, err := CreateMedianCutPalette(img, 16)
+
+	assert.Nil(t, err)
+	assert.Equal(t, color.Palette{color.RGBA{255, 255, 255, 255}}, palette)
+}
+
+func TestCreateMedianCutPaletteShouldNotCreateForInvalidParameters(t *testing.T) {
+	palette, err := CreateMedianCutPalette(nil, 2)
+	assert.Nil(t, palette)
+	assert.NotNil(t, err)
+
+	palette, err = CreateMedianCutPalette(image.NewNRGBA(image.Rect(0, 0, 1, 1)), 0)
+	assert.Nil(t, palette)
+	assert.NotNil(t, err)
+
+	palette, err = CreateMedianCutPalette(image.NewNRGBA(image.Rect(0, 0, 1, 1)), 2)
+	assert.Nil(t, palette)
+	assert.NotNil(t, err)
+}